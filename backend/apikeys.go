@@ -0,0 +1,228 @@
+package main
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Prefixo que identifica uma chave de API no header Authorization
+const apiKeyPrefix = "rk_"
+
+// Escopos que podem ser concedidos a uma chave de API
+var validScopes = map[string]bool{
+	"jobs:read":          true,
+	"jobs:write":         true,
+	"applications:read":  true,
+	"applications:write": true,
+	"profile:read":       true,
+	"profile:write":      true,
+}
+
+// generateAPIKey gera uma nova chave no formato rk_<prefixo>_<segredo>.
+// O prefixo fica visível para o usuário identificar a chave; apenas o hash
+// da chave completa é armazenado.
+func generateAPIKey() (key, prefix string, err error) {
+	id, err := randomToken(4)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := randomToken(24)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix = apiKeyPrefix + id
+	key = prefix + "_" + secret
+	return key, prefix, nil
+}
+
+// authenticateAPIKey valida a chave recebida e retorna o dono e os escopos.
+func authenticateAPIKey(key string) (userID int, email string, scopes []string, ok bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 {
+		return 0, "", nil, false
+	}
+	prefix := parts[0] + "_" + parts[1]
+
+	var keyID int
+	var keyHash, scopeList string
+	var expiresAt sql.NullTime
+	err := db.QueryRow(`
+		SELECT k.id, k.user_id, k.key_hash, k.scopes, k.expires_at, u.email
+		FROM api_keys k
+		JOIN users u ON k.user_id = u.id
+		WHERE k.prefix = ?`, prefix).Scan(&keyID, &userID, &keyHash, &scopeList, &expiresAt, &email)
+	if err != nil {
+		return 0, "", nil, false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(hashToken(key))) != 1 {
+		return 0, "", nil, false
+	}
+
+	if expiresAt.Valid && time.Now().After(expiresAt.Time) {
+		return 0, "", nil, false
+	}
+
+	db.Exec("UPDATE api_keys SET last_used_at = ? WHERE id = ?", time.Now(), keyID)
+
+	return userID, email, strings.Split(scopeList, ","), true
+}
+
+// requireScope restringe a rota às chaves de API que possuem o escopo informado.
+// Requisições autenticadas com JWT não são afetadas.
+func requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != "api_key" {
+			c.Next()
+			return
+		}
+
+		for _, s := range c.GetStringSlice("scopes") {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Chave de API sem o escopo " + scope})
+		c.Abort()
+	}
+}
+
+// requireSession bloqueia o acesso com chave de API, exigindo login com JWT
+func requireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") == "api_key" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Esta operação exige login com usuário e senha"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func getAPIKeysHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	rows, err := db.Query(`
+		SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+		FROM api_keys
+		WHERE user_id = ?
+		ORDER BY created_at DESC`, userID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar chaves de API"})
+		return
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var key APIKey
+		var scopeList string
+		var expiresAt, lastUsedAt sql.NullTime
+		err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &scopeList,
+			&expiresAt, &lastUsedAt, &key.CreatedAt)
+
+		if err != nil {
+			continue
+		}
+
+		key.Scopes = strings.Split(scopeList, ",")
+		if expiresAt.Valid {
+			key.ExpiresAt = &expiresAt.Time
+		}
+		if lastUsedAt.Valid {
+			key.LastUsedAt = &lastUsedAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+func createAPIKeyHandler(c *gin.Context) {
+	var req APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !validScopes[scope] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Escopo inválido: " + scope})
+			return
+		}
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A data de expiração deve estar no futuro"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar chave de API"})
+		return
+	}
+
+	now := time.Now()
+	result, err := db.Exec(`
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID, req.Name, prefix, hashToken(key), strings.Join(req.Scopes, ","), req.ExpiresAt, now)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar chave de API"})
+		return
+	}
+
+	keyID, _ := result.LastInsertId()
+
+	// A chave completa só é exibida neste momento
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Chave de API criada com sucesso",
+		"key":     key,
+		"api_key": APIKey{
+			ID:        int(keyID),
+			UserID:    userID,
+			Name:      req.Name,
+			Prefix:    prefix,
+			Scopes:    req.Scopes,
+			ExpiresAt: req.ExpiresAt,
+			CreatedAt: now,
+		},
+	})
+}
+
+func deleteAPIKeyHandler(c *gin.Context) {
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec("DELETE FROM api_keys WHERE id = ? AND user_id = ?", keyID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao revogar chave de API"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chave de API não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Chave de API revogada com sucesso"})
+}
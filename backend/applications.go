@@ -1,18 +1,34 @@
 package main
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Prazo em que uma candidatura retirada pode ser reaberta com uma nova candidatura
+var reapplyWindow = time.Duration(getEnvInt("WITHDRAWAL_REAPPLY_DAYS", 30)) * 24 * time.Hour
+
+var (
+	errAlreadyApplied = errors.New("Você já se candidatou para esta vaga")
+	errReapplyExpired = errors.New("O prazo para reabrir esta candidatura expirou")
+	// A candidatura mudou de status entre a leitura e a gravação
+	errApplicationChanged = errors.New("A candidatura foi alterada por outra requisição, tente novamente")
+)
+
 func getApplicationsHandler(c *gin.Context) {
 	userID := c.GetInt("user_id")
 
 	rows, err := db.Query(`
-		SELECT a.id, a.job_id, a.user_id, a.status, a.created_at, a.updated_at,
+		SELECT a.id, a.job_id, a.user_id, a.status, a.withdrawn_at, a.withdrawal_reason, a.job_closed_at, a.created_at, a.updated_at,
 		       j.title as job_title, j.company as job_company, j.location as job_location,
 		       u.name as user_name
 		FROM applications a
@@ -31,25 +47,36 @@ func getApplicationsHandler(c *gin.Context) {
 	for rows.Next() {
 		var app Application
 		var jobTitle, jobCompany, jobLocation, userName string
+		var withdrawnAt, jobClosedAt sql.NullTime
 		err := rows.Scan(
-			&app.ID, &app.JobID, &app.UserID, &app.Status, &app.CreatedAt, &app.UpdatedAt,
+			&app.ID, &app.JobID, &app.UserID, &app.Status, &withdrawnAt, &app.WithdrawalReason, &jobClosedAt, &app.CreatedAt, &app.UpdatedAt,
 			&jobTitle, &jobCompany, &jobLocation, &userName)
 		
 		if err != nil {
 			continue
 		}
 
+		if withdrawnAt.Valid {
+			app.WithdrawnAt = &withdrawnAt.Time
+		}
+		if jobClosedAt.Valid {
+			app.JobClosedAt = &jobClosedAt.Time
+		}
+
 		applications = append(applications, gin.H{
-			"id":           app.ID,
-			"job_id":       app.JobID,
-			"user_id":      app.UserID,
-			"status":       app.Status,
-			"created_at":   app.CreatedAt,
-			"updated_at":   app.UpdatedAt,
-			"job_title":    jobTitle,
-			"job_company":  jobCompany,
-			"job_location": jobLocation,
-			"user_name":    userName,
+			"id":                app.ID,
+			"job_id":            app.JobID,
+			"user_id":           app.UserID,
+			"status":            app.Status,
+			"withdrawn_at":      app.WithdrawnAt,
+			"withdrawal_reason": app.WithdrawalReason,
+			"job_closed_at":     app.JobClosedAt,
+			"created_at":        app.CreatedAt,
+			"updated_at":        app.UpdatedAt,
+			"job_title":         jobTitle,
+			"job_company":       jobCompany,
+			"job_location":      jobLocation,
+			"user_name":         userName,
 		})
 	}
 
@@ -67,17 +94,15 @@ func createApplicationHandler(c *gin.Context) {
 
 	// Verificar se a vaga existe
 	var job Job
-	err := db.QueryRow("SELECT id FROM jobs WHERE id = ?", req.JobID).Scan(&job.ID)
+	var closesAt sql.NullTime
+	err := db.QueryRow("SELECT id, user_id, closes_at FROM jobs WHERE id = ? AND deleted_at IS NULL", req.JobID).Scan(&job.ID, &job.UserID, &closesAt)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
 		return
 	}
 
-	// Verificar se já existe uma candidatura
-	var existingApp Application
-	err = db.QueryRow("SELECT id FROM applications WHERE job_id = ? AND user_id = ?", req.JobID, userID).Scan(&existingApp.ID)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Você já se candidatou para esta vaga"})
+	if closesAt.Valid && !closesAt.Time.After(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "As inscrições para esta vaga foram encerradas"})
 		return
 	}
 
@@ -87,22 +112,156 @@ func createApplicationHandler(c *gin.Context) {
 		return
 	}
 
+	if isHiringSide(req.JobID, userID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Membros da equipe de contratação não podem se candidatar para esta vaga"})
+		return
+	}
+
+	// Validar as respostas das perguntas de triagem
+	questions, err := listScreeningQuestions(req.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar perguntas da vaga"})
+		return
+	}
+
+	answers, err := evaluateAnswers(questions, req.Answers)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respostas eliminatórias rejeitam a candidatura automaticamente
+	status := "pending"
+	for _, answer := range answers {
+		if answer.Knockout {
+			status = "rejected"
+			break
+		}
+	}
+
+	// Copiar o currículo escolhido para a candidatura
+	var snapshot *ApplicationResume
+	if req.ResumeID != nil {
+		snapshot, err = snapshotResume(*req.ResumeID, userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Currículo não encontrado"})
+			return
+		}
+	}
+
+	// A verificação de candidatura existente e todas as gravações acontecem na
+	// mesma transação, para que requisições simultâneas não criem duplicatas
 	now := time.Now()
-	result, err := db.Exec(`
-		INSERT INTO applications (job_id, user_id, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)`,
-		req.JobID, userID, "pending", now, now)
-	
+	var appID int64
+	var oldResumeKey string
+	err = withTx(func(tx *sql.Tx) error {
+		// Candidaturas retiradas podem ser reabertas dentro do prazo configurado
+		var existingApp Application
+		var withdrawnAt sql.NullTime
+		err := tx.QueryRow("SELECT id, status, withdrawn_at FROM applications WHERE job_id = ? AND user_id = ?", req.JobID, userID).Scan(
+			&existingApp.ID, &existingApp.Status, &withdrawnAt)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		fromStatus := ""
+		if err == nil {
+			if existingApp.Status != "withdrawn" {
+				return errAlreadyApplied
+			}
+			if !withdrawnAt.Valid || time.Since(withdrawnAt.Time) > reapplyWindow {
+				return errReapplyExpired
+			}
+
+			appID = int64(existingApp.ID)
+			fromStatus = "withdrawn"
+			oldResumeKey, err = reopenApplication(tx, existingApp.ID, status, req.CoverLetter, now)
+			if err != nil {
+				return err
+			}
+		} else {
+			result, err := tx.Exec(`
+				INSERT INTO applications (job_id, user_id, status, cover_letter, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				req.JobID, userID, status, req.CoverLetter, now, now)
+			if err != nil {
+				if isUniqueViolation(err) {
+					return errAlreadyApplied
+				}
+				return err
+			}
+			appID, _ = result.LastInsertId()
+		}
+
+		reason := ""
+		if status == "rejected" {
+			// Reprovação automática pelas perguntas eliminatórias
+			var reasonID int
+			if err := tx.QueryRow("SELECT id, label FROM rejection_reasons WHERE code = ?", screeningKnockoutReason).Scan(&reasonID, &reason); err != nil {
+				return err
+			}
+			_, err := tx.Exec("UPDATE applications SET rejection_reason_id = ?, rejected_at = ? WHERE id = ?", reasonID, now, appID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := recordStatusChange(tx, int(appID), fromStatus, status, reason, userID); err != nil {
+			return err
+		}
+
+		var candidateName, jobTitle string
+		tx.QueryRow("SELECT name FROM users WHERE id = ?", userID).Scan(&candidateName)
+		tx.QueryRow("SELECT title FROM jobs WHERE id = ?", req.JobID).Scan(&jobTitle)
+		message := fmt.Sprintf("%s se candidatou para a vaga %s", candidateName, jobTitle)
+		if err := notifyHiringTeam(tx, req.JobID, "application_received", message, int(appID)); err != nil {
+			return err
+		}
+
+		if snapshot != nil {
+			_, err = tx.Exec(`
+				INSERT INTO application_resumes (application_id, resume_id, filename, content_type, size, storage_key, checksum, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				appID, snapshot.ResumeID, snapshot.Filename, snapshot.ContentType, snapshot.Size, snapshot.StorageKey, snapshot.Checksum, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, answer := range answers {
+			value, _ := json.Marshal(answer.Value)
+			_, err = tx.Exec(`
+				INSERT INTO application_answers (application_id, question_id, question, value, knockout)
+				VALUES (?, ?, ?, ?, ?)`,
+				appID, answer.QuestionID, answer.Question, string(value), answer.Knockout)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
 	if err != nil {
+		if snapshot != nil {
+			fileStorage.Delete(snapshot.StorageKey)
+		}
+		if errors.Is(err, errAlreadyApplied) || errors.Is(err, errReapplyExpired) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar candidatura"})
 		return
 	}
 
-	appID, _ := result.LastInsertId()
+	// O currículo da candidatura anterior só é apagado depois da confirmação
+	if oldResumeKey != "" {
+		fileStorage.Delete(oldResumeKey)
+	}
 	
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Candidatura realizada com sucesso",
+		"message":        "Candidatura realizada com sucesso",
 		"application_id": appID,
+		"status":         status,
 	})
 }
 
@@ -115,80 +274,324 @@ func getApplicationHandler(c *gin.Context) {
 
 	userID := c.GetInt("user_id")
 
+	// O candidato e o dono da vaga podem ver a candidatura
 	var app Application
 	var jobTitle, jobCompany, jobLocation, userName string
+	var withdrawnAt, jobClosedAt sql.NullTime
 	err = db.QueryRow(`
-		SELECT a.id, a.job_id, a.user_id, a.status, a.created_at, a.updated_at,
+		SELECT a.id, a.job_id, a.user_id, a.status, a.cover_letter, a.withdrawn_at, a.withdrawal_reason, a.job_closed_at, a.created_at, a.updated_at,
 		       j.title as job_title, j.company as job_company, j.location as job_location,
 		       u.name as user_name
 		FROM applications a
 		JOIN jobs j ON a.job_id = j.id
 		JOIN users u ON a.user_id = u.id
-		WHERE a.id = ? AND a.user_id = ?`, appID, userID).Scan(
-		&app.ID, &app.JobID, &app.UserID, &app.Status, &app.CreatedAt, &app.UpdatedAt,
-		&jobTitle, &jobCompany, &jobLocation, &userName)
+		WHERE a.id = ? AND (a.user_id = ? OR `+hiringSideSQL+`)`, appID, userID, userID, userID).Scan(
+		&app.ID, &app.JobID, &app.UserID, &app.Status, &app.CoverLetter, &withdrawnAt, &app.WithdrawalReason,
+		&jobClosedAt, &app.CreatedAt, &app.UpdatedAt, &jobTitle, &jobCompany, &jobLocation, &userName)
 	
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
 		return
 	}
 
+	if withdrawnAt.Valid {
+		app.WithdrawnAt = &withdrawnAt.Time
+	}
+	if jobClosedAt.Valid {
+		app.JobClosedAt = &jobClosedAt.Time
+	}
+
+	answers, err := listApplicationAnswers(app.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar respostas"})
+		return
+	}
+
+	// Resumo das avaliações, apenas para a equipe de contratação que já pode vê-las
+	scorecardSummary := visibleScorecardSummary(app.ID, userID)
+	rejection := visibleRejection(app.ID, userID)
+	tags := visibleTags(app.ID, userID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"application": gin.H{
-			"id":           app.ID,
-			"job_id":       app.JobID,
-			"user_id":      app.UserID,
-			"status":       app.Status,
-			"created_at":   app.CreatedAt,
-			"updated_at":   app.UpdatedAt,
-			"job_title":    jobTitle,
-			"job_company":  jobCompany,
-			"job_location": jobLocation,
-			"user_name":    userName,
+			"id":                app.ID,
+			"job_id":            app.JobID,
+			"user_id":           app.UserID,
+			"status":            app.Status,
+			"cover_letter":      app.CoverLetter,
+			"answers":           candidateScreeningAnswers(answers, app.JobID, userID),
+			"scorecard_summary": scorecardSummary,
+			"rejection":         rejection,
+			"tags":              tags,
+			"withdrawn_at":      app.WithdrawnAt,
+			"withdrawal_reason": app.WithdrawalReason,
+			"job_closed_at":     app.JobClosedAt,
+			"created_at":        app.CreatedAt,
+			"updated_at":        app.UpdatedAt,
+			"job_title":         jobTitle,
+			"job_company":       jobCompany,
+			"job_location":      jobLocation,
+			"user_name":         userName,
 		},
 	})
 }
 
-func updateApplicationHandler(c *gin.Context) {
+// deleteApplicationHandler mantém a rota antiga de cancelamento, que agora
+// retira a candidatura em vez de excluí-la
+func deleteApplicationHandler(c *gin.Context) {
+	withdrawApplicationHandler(c)
+}
+
+// withdrawApplicationHandler marca a candidatura como retirada, preservando o
+// registro e o histórico para o recrutador
+func withdrawApplicationHandler(c *gin.Context) {
 	appID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
 		return
 	}
 
-	var req struct {
-		Status string `json:"status" binding:"required,oneof=pending accepted rejected"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	// O motivo é opcional, então o corpo pode vir vazio
+	var req WithdrawRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
 
 	userID := c.GetInt("user_id")
 
 	// Verificar se a candidatura existe e pertence ao usuário
 	var existingApp Application
-	err = db.QueryRow("SELECT user_id FROM applications WHERE id = ?", appID).Scan(&existingApp.UserID)
+	err = db.QueryRow("SELECT user_id, status FROM applications WHERE id = ?", appID).Scan(&existingApp.UserID, &existingApp.Status)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
 		return
 	}
 
 	if existingApp.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para editar esta candidatura"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para retirar esta candidatura"})
+		return
+	}
+
+	if existingApp.Status == "withdrawn" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta candidatura já foi retirada"})
+		return
+	}
+
+	// Retirar e candidatar-se de novo desfaria a reprovação sem passar pela
+	// equipe de contratação
+	if existingApp.Status == "rejected" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Candidaturas reprovadas não podem ser retiradas"})
 		return
 	}
 
-	_, err = db.Exec("UPDATE applications SET status = ?, updated_at = ? WHERE id = ?", req.Status, time.Now(), appID)
+	now := time.Now()
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE applications SET status = 'withdrawn', withdrawn_at = ?, withdrawal_reason = ?, updated_at = ?
+			WHERE id = ? AND status = ?`, now, req.Reason, now, appID, existingApp.Status)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errApplicationChanged
+		}
+		return recordStatusChange(tx, appID, existingApp.Status, "withdrawn", req.Reason, userID)
+	})
+	if errors.Is(err, errApplicationChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar candidatura"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao retirar candidatura"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Candidatura atualizada com sucesso"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Candidatura retirada com sucesso",
+		"reapply_until": now.Add(reapplyWindow),
+	})
 }
 
-func deleteApplicationHandler(c *gin.Context) {
+// getJobApplicationsHandler lista as candidaturas recebidas por uma vaga do usuário
+func getJobApplicationsHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if !requireHiringTeam(c, jobID) {
+		return
+	}
+
+	// Filtro opcional por tag
+	tag := strings.ToLower(strings.TrimSpace(c.Query("tag")))
+
+	tags, err := listJobApplicationTags(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar tags"})
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT a.id, a.job_id, a.user_id, a.status, a.cover_letter, a.withdrawn_at, a.withdrawal_reason, a.job_closed_at, a.created_at, a.updated_at,
+		       u.name as user_name, u.email as user_email
+		FROM applications a
+		JOIN users u ON a.user_id = u.id
+		WHERE a.job_id = ?
+		  AND (? = '' OR a.id IN (SELECT application_id FROM application_tags WHERE tag = ?))
+		ORDER BY a.created_at DESC`, jobID, tag, tag)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar candidaturas"})
+		return
+	}
+	defer rows.Close()
+
+	applications := []gin.H{}
+	for rows.Next() {
+		var app Application
+		var userName, userEmail string
+		var withdrawnAt, jobClosedAt sql.NullTime
+		err := rows.Scan(
+			&app.ID, &app.JobID, &app.UserID, &app.Status, &app.CoverLetter, &withdrawnAt, &app.WithdrawalReason,
+			&jobClosedAt, &app.CreatedAt, &app.UpdatedAt, &userName, &userEmail)
+
+		if err != nil {
+			continue
+		}
+
+		if withdrawnAt.Valid {
+			app.WithdrawnAt = &withdrawnAt.Time
+		}
+		if jobClosedAt.Valid {
+			app.JobClosedAt = &jobClosedAt.Time
+		}
+		if tags[app.ID] == nil {
+			tags[app.ID] = []string{}
+		}
+
+		applications = append(applications, gin.H{
+			"id":                app.ID,
+			"job_id":            app.JobID,
+			"user_id":           app.UserID,
+			"status":            app.Status,
+			"cover_letter":      app.CoverLetter,
+			"withdrawn_at":      app.WithdrawnAt,
+			"withdrawal_reason": app.WithdrawalReason,
+			"job_closed_at":     app.JobClosedAt,
+			"created_at":        app.CreatedAt,
+			"updated_at":        app.UpdatedAt,
+			"user_name":         userName,
+			"user_email":        userEmail,
+			"tags":              tags[app.ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"applications": applications})
+}
+
+// reopenApplication reativa uma candidatura retirada, substituindo a carta,
+// as respostas e o currículo anexado pelos enviados na nova candidatura.
+// Devolve a chave do currículo antigo, que deve ser apagado após o commit.
+func reopenApplication(tx *sql.Tx, appID int, status, coverLetter string, now time.Time) (string, error) {
+	_, err := tx.Exec(`
+		UPDATE applications SET status = ?, cover_letter = ?, withdrawn_at = NULL, withdrawal_reason = '',
+		       rejection_reason_id = NULL, rejection_note = '', rejected_at = NULL, updated_at = ?
+		WHERE id = ?`, status, coverLetter, now, appID)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec("DELETE FROM application_answers WHERE application_id = ?", appID); err != nil {
+		return "", err
+	}
+
+	var oldKey string
+	err = tx.QueryRow("SELECT storage_key FROM application_resumes WHERE application_id = ?", appID).Scan(&oldKey)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec("DELETE FROM application_resumes WHERE application_id = ?", appID); err != nil {
+		return "", err
+	}
+	return oldKey, nil
+}
+
+// recordStatusChange grava a mudança de status no histórico da candidatura e
+// avisa o candidato quando a mudança foi feita por outra pessoa. Reprovações
+// não geram aviso: o candidato fica sabendo pela mensagem de reprovação, que
+// pode ter sido agendada para mais tarde.
+func recordStatusChange(q dbtx, appID int, from, to, reason string, changedBy int) error {
+	_, err := q.Exec(`
+		INSERT INTO application_status_history (application_id, from_status, to_status, reason, changed_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		appID, from, to, reason, changedBy, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var candidateID, jobID int
+	var jobTitle, candidateName, candidateEmail string
+	err = q.QueryRow(`
+		SELECT a.user_id, u.name, u.email, j.id, j.title FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		JOIN users u ON a.user_id = u.id
+		WHERE a.id = ?`, appID).Scan(&candidateID, &candidateName, &candidateEmail, &jobID, &jobTitle)
+	if err != nil {
+		return err
+	}
+
+	// Eventos em tempo real para a equipe e, exceto na reprovação, para o candidato
+	team, err := hiringTeamIDs(q, jobID)
+	if err != nil {
+		return err
+	}
+	event := gin.H{"application_id": appID, "job_id": jobID, "job_title": jobTitle, "from_status": from, "to_status": to}
+	if from == "" {
+		event["candidate_name"] = candidateName
+		publishEvent(q, "application.created", event, team...)
+	} else if to == "rejected" {
+		publishEvent(q, "application.status_changed", event, team...)
+	} else {
+		publishEvent(q, "application.status_changed", event, append(team, candidateID)...)
+	}
+
+	// Webhooks das integrações (ATS/HRIS) recebem também os dados do candidato
+	webhookType := "application.status_changed"
+	if from == "" {
+		webhookType = "application.created"
+	}
+	err = enqueueWebhookEvent(q, webhookType, jobID, gin.H{
+		"application_id": appID,
+		"job_id":         jobID,
+		"job_title":      jobTitle,
+		"candidate":      gin.H{"id": candidateID, "name": candidateName, "email": candidateEmail},
+		"from_status":    from,
+		"to_status":      to,
+		"reason":         reason,
+		"changed_by":     changedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	if from == "" || to == "rejected" || candidateID == changedBy {
+		return nil
+	}
+
+	message := fmt.Sprintf("Sua candidatura para a vaga %s agora está: %s", jobTitle, applicationStatusLabels[to])
+	return createNotification(q, candidateID, "status_changed", message, appID)
+}
+
+func getApplicationHistoryHandler(c *gin.Context) {
 	appID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
@@ -197,24 +600,143 @@ func deleteApplicationHandler(c *gin.Context) {
 
 	userID := c.GetInt("user_id")
 
-	// Verificar se a candidatura existe e pertence ao usuário
-	var existingApp Application
-	err = db.QueryRow("SELECT user_id FROM applications WHERE id = ?", appID).Scan(&existingApp.UserID)
+	// O candidato e a equipe de contratação podem ver o histórico
+	var jobID int
+	err = db.QueryRow(`
+		SELECT a.job_id FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		WHERE a.id = ? AND (a.user_id = ? OR `+hiringSideSQL+`)`, appID, userID, userID, userID).Scan(&jobID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
 		return
 	}
 
-	if existingApp.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para excluir esta candidatura"})
+	history, err := listStatusHistory(appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar histórico"})
+		return
+	}
+	if !isHiringSide(jobID, userID) {
+		history = candidateStatusHistory(appID, history)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+func listStatusHistory(appID int) ([]ApplicationStatusChange, error) {
+	rows, err := db.Query(`
+		SELECT id, application_id, from_status, to_status, reason, changed_by, created_at
+		FROM application_status_history
+		WHERE application_id = ?
+		ORDER BY created_at, id`, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []ApplicationStatusChange{}
+	for rows.Next() {
+		var change ApplicationStatusChange
+		err := rows.Scan(&change.ID, &change.ApplicationID, &change.FromStatus, &change.ToStatus,
+			&change.Reason, &change.ChangedBy, &change.CreatedAt)
+		if err != nil {
+			continue
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+// candidateStatusHistory prepara o histórico para o candidato: o motivo
+// interno da reprovação não aparece e, enquanto a mensagem de reprovação
+// estiver agendada, a própria reprovação fica oculta. Ao reabrir a
+// candidatura a mensagem pendente é cancelada, então ela sempre se refere à
+// última reprovação.
+func candidateStatusHistory(appID int, history []ApplicationStatusChange) []ApplicationStatusChange {
+	var pending int
+	db.QueryRow(`
+		SELECT COUNT(*) FROM candidate_messages
+		WHERE application_id = ? AND kind = 'rejection' AND sent_at IS NULL AND cancelled_at IS NULL`, appID).Scan(&pending)
+
+	visible := []ApplicationStatusChange{}
+	last := -1
+	for i, change := range history {
+		if change.ToStatus == "rejected" {
+			last = i
+		}
+	}
+	for i, change := range history {
+		if change.ToStatus == "rejected" {
+			if pending > 0 && i == last {
+				continue
+			}
+			change.Reason = ""
+		}
+		visible = append(visible, change)
+	}
+	return visible
+}
+
+// getApplicationTimelineHandler junta em ordem cronológica as mudanças de
+// status e, para a equipe de contratação, as notas internas
+func getApplicationTimelineHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var applicantID, jobID int
+	err = db.QueryRow("SELECT user_id, job_id FROM applications WHERE id = ?", appID).Scan(&applicantID, &jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	hiringSide := isHiringSide(jobID, userID)
+	if applicantID != userID && !hiringSide {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
 		return
 	}
 
-	_, err = db.Exec("DELETE FROM applications WHERE id = ?", appID)
+	history, err := listStatusHistory(appID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir candidatura"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar histórico"})
 		return
 	}
+	if !hiringSide {
+		history = candidateStatusHistory(appID, history)
+	}
+
+	timeline := []gin.H{}
+	for _, change := range history {
+		timeline = append(timeline, gin.H{
+			"type":       "status_change",
+			"created_at": change.CreatedAt,
+			"data":       change,
+		})
+	}
+
+	if hiringSide {
+		notes, err := listApplicationNotes(appID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar notas"})
+			return
+		}
+		for _, note := range notes {
+			timeline = append(timeline, gin.H{
+				"type":       "note",
+				"created_at": note.CreatedAt,
+				"data":       note,
+			})
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i]["created_at"].(time.Time).Before(timeline[j]["created_at"].(time.Time))
+	})
 
-	c.JSON(http.StatusOK, gin.H{"message": "Candidatura excluída com sucesso"})
+	c.JSON(http.StatusOK, gin.H{"timeline": timeline})
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCandidateStatusHistory(t *testing.T) {
+	setupTestDB(t)
+	db.Exec("INSERT INTO users (id, email, password, name) VALUES (1, 'dono@x.com', '', 'Dono'), (3, 'ana@x.com', '', 'Ana')")
+	db.Exec("INSERT INTO jobs (id, title, description, company, location, type, user_id) VALUES (1, 'Dev', 'Dev', 'X', 'SP', 'full-time', 1)")
+	db.Exec("INSERT INTO applications (id, job_id, user_id) VALUES (1, 1, 3)")
+
+	now := time.Now()
+	history := []ApplicationStatusChange{
+		{ID: 1, ToStatus: "pending", Reason: ""},
+		{ID: 2, FromStatus: "pending", ToStatus: "rejected", Reason: "Expectativa salarial incompatível"},
+		{ID: 3, FromStatus: "rejected", ToStatus: "pending", Reason: "Reaberta"},
+		{ID: 4, FromStatus: "pending", ToStatus: "rejected", Reason: "Eliminado nas perguntas de triagem"},
+	}
+
+	setMessage := func(sentAt, cancelledAt interface{}) {
+		db.Exec("DELETE FROM candidate_messages")
+		db.Exec(`INSERT INTO candidate_messages (application_id, kind, subject, body, send_at, sent_at, cancelled_at, created_by)
+			VALUES (1, 'rejection', 'Atualização', 'Obrigado', ?, ?, ?, 1)`, now.Add(time.Hour), sentAt, cancelledAt)
+	}
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantIDs []int
+	}{
+		{name: "sem mensagem agendada", setup: func() { db.Exec("DELETE FROM candidate_messages") }, wantIDs: []int{1, 2, 3, 4}},
+		{name: "mensagem ainda não enviada", setup: func() { setMessage(nil, nil) }, wantIDs: []int{1, 2, 3}},
+		{name: "mensagem enviada", setup: func() { setMessage(now, nil) }, wantIDs: []int{1, 2, 3, 4}},
+		{name: "mensagem cancelada", setup: func() { setMessage(nil, now) }, wantIDs: []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			got := candidateStatusHistory(1, append([]ApplicationStatusChange(nil), history...))
+
+			var ids []int
+			for _, change := range got {
+				ids = append(ids, change.ID)
+				if change.ToStatus == "rejected" && change.Reason != "" {
+					t.Errorf("motivo interno visível: %q", change.Reason)
+				}
+				if change.ToStatus != "rejected" && change.Reason != history[change.ID-1].Reason {
+					t.Errorf("motivo de outra mudança alterado: %q", change.Reason)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("entradas %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +18,22 @@ import (
 
 var jwtSecret = []byte("sua_chave_secreta_aqui")
 
+// O token de redefinição foi consumido por outra requisição
+var errResetTokenUsed = errors.New("token de redefinição já utilizado")
+
+// Emails que recebem o papel de administrador
+var adminEmails = parseAdminEmails(getEnv("ADMIN_EMAILS", ""))
+
+func parseAdminEmails(value string) map[string]bool {
+	emails := make(map[string]bool)
+	for _, email := range strings.Split(value, ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			emails[email] = true
+		}
+	}
+	return emails
+}
+
 func registerHandler(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -27,6 +49,12 @@ func registerHandler(c *gin.Context) {
 		return
 	}
 
+	// Validar política de senha
+	if err := validatePassword(req.Password, req.Email, req.Name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Hash da senha
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -34,11 +62,16 @@ func registerHandler(c *gin.Context) {
 		return
 	}
 
+	role := "user"
+	if adminEmails[req.Email] {
+		role = "admin"
+	}
+
 	// Inserir usuário
 	result, err := db.Exec(`
-		INSERT INTO users (email, password, name, created_at, updated_at) 
-		VALUES (?, ?, ?, ?, ?)`,
-		req.Email, string(hashedPassword), req.Name, time.Now(), time.Now())
+		INSERT INTO users (email, password, name, role, created_at, updated_at) 
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		req.Email, string(hashedPassword), req.Name, role, time.Now(), time.Now())
 	
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar usuário"})
@@ -124,6 +157,23 @@ func authMiddleware() gin.HandlerFunc {
 			tokenString = tokenString[7:]
 		}
 
+		// Chaves de API são aceitas como alternativa ao JWT
+		if strings.HasPrefix(tokenString, apiKeyPrefix) {
+			userID, email, scopes, ok := authenticateAPIKey(tokenString)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Chave de API inválida ou expirada"})
+				c.Abort()
+				return
+			}
+
+			c.Set("user_id", userID)
+			c.Set("email", email)
+			c.Set("auth_method", "api_key")
+			c.Set("scopes", scopes)
+			c.Next()
+			return
+		}
+
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			return jwtSecret, nil
 		})
@@ -140,6 +190,192 @@ func authMiddleware() gin.HandlerFunc {
 
 		c.Set("user_id", userID)
 		c.Set("email", email)
+		c.Set("auth_method", "jwt")
+		c.Next()
+	}
+}
+
+// randomToken gera n bytes aleatórios codificados em hexadecimal
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken calcula o SHA-256 de tokens de alta entropia antes de armazená-los
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func changePasswordHandler(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var user User
+	err := db.QueryRow("SELECT email, password, name FROM users WHERE id = ?", userID).Scan(
+		&user.Email, &user.Password, &user.Name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Senha atual incorreta"})
+		return
+	}
+
+	if err := validatePassword(req.NewPassword, user.Email, user.Name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar senha"})
+		return
+	}
+
+	_, err = db.Exec("UPDATE users SET password = ?, updated_at = ? WHERE id = ?", string(hashedPassword), time.Now(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao alterar senha"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Senha alterada com sucesso"})
+}
+
+func forgotPasswordHandler(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A resposta é sempre a mesma para não revelar quais emails estão cadastrados
+	response := gin.H{"message": "Se o email estiver cadastrado, você receberá as instruções para redefinir a senha"}
+
+	var userID int
+	err := db.QueryRow("SELECT id FROM users WHERE email = ?", req.Email).Scan(&userID)
+	if err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
+		return
+	}
+
+	now := time.Now()
+	err = withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO password_resets (user_id, token_hash, expires_at, created_at)
+			VALUES (?, ?, ?, ?)`,
+			userID, hashToken(token), now.Add(time.Hour), now)
+		if err != nil {
+			return err
+		}
+
+		return enqueueEmail(tx, Email{
+			To:      []string{req.Email},
+			Subject: "Redefinição de senha",
+			Body: fmt.Sprintf("Recebemos um pedido para redefinir sua senha.\n\n"+
+				"Use o token abaixo em até 1 hora:\n\n%s\n\n"+
+				"Se você não fez este pedido, ignore este email.", token),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func resetPasswordHandler(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var resetID int
+	var user User
+	var expiresAt time.Time
+	var usedAt sql.NullTime
+	err := db.QueryRow(`
+		SELECT r.id, r.expires_at, r.used_at, u.id, u.email, u.name
+		FROM password_resets r
+		JOIN users u ON r.user_id = u.id
+		WHERE r.token_hash = ?`, hashToken(req.Token)).Scan(
+		&resetID, &expiresAt, &usedAt, &user.ID, &user.Email, &user.Name)
+
+	if err != nil || usedAt.Valid || time.Now().After(expiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token inválido ou expirado"})
+		return
+	}
+
+	if err := validatePassword(req.NewPassword, user.Email, user.Name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar senha"})
+		return
+	}
+
+	// Consumir o token e trocar a senha juntos; o token só pode ser usado uma vez
+	now := time.Now()
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec("UPDATE password_resets SET used_at = ? WHERE id = ? AND used_at IS NULL", now, resetID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errResetTokenUsed
+		}
+
+		_, err = tx.Exec("UPDATE users SET password = ?, updated_at = ? WHERE id = ?", string(hashedPassword), now, user.ID)
+		return err
+	})
+	if errors.Is(err, errResetTokenUsed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token inválido ou expirado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao redefinir senha"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Senha redefinida com sucesso"})
+}
+
+// isAdmin indica se o usuário tem o papel de administrador
+func isAdmin(userID int) bool {
+	var role string
+	err := db.QueryRow("SELECT role FROM users WHERE id = ?", userID).Scan(&role)
+	return err == nil && role == "admin"
+}
+
+// requireAdmin restringe a rota a administradores
+func requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdmin(c.GetInt("user_id")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Acesso restrito a administradores"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
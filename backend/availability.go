@@ -0,0 +1,543 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Os horários oferecidos começam em múltiplos deste passo
+const slotStep = 30 * time.Minute
+
+// Antecedência mínima, em horas, para um horário ser oferecido
+var schedulingMinNotice = time.Duration(getEnvInt("SCHEDULING_MIN_NOTICE_HOURS", 4)) * time.Hour
+
+// Maior período, em dias, considerado no cálculo de horários livres
+const maxSlotRangeDays = 31
+
+// timeRange é um intervalo semiaberto [Start, End)
+type timeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// mergeRanges ordena os intervalos e une os que se sobrepõem ou se tocam
+func mergeRanges(ranges []timeRange) []timeRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start.Before(ranges[j].Start) })
+
+	merged := []timeRange{}
+	for _, r := range ranges {
+		if !r.End.After(r.Start) {
+			continue
+		}
+		if n := len(merged); n > 0 && !r.Start.After(merged[n-1].End) {
+			if r.End.After(merged[n-1].End) {
+				merged[n-1].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// intersectRanges devolve os trechos comuns a duas listas já unidas e ordenadas
+func intersectRanges(a, b []timeRange) []timeRange {
+	result := []timeRange{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if end.After(start) {
+			result = append(result, timeRange{start, end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// subtractRanges remove de ranges os trechos ocupados por busy
+func subtractRanges(ranges, busy []timeRange) []timeRange {
+	for _, cut := range busy {
+		next := []timeRange{}
+		for _, r := range ranges {
+			if !cut.Start.Before(r.End) || !cut.End.After(r.Start) {
+				next = append(next, r)
+				continue
+			}
+			if cut.Start.After(r.Start) {
+				next = append(next, timeRange{r.Start, cut.Start})
+			}
+			if cut.End.Before(r.End) {
+				next = append(next, timeRange{cut.End, r.End})
+			}
+		}
+		ranges = next
+	}
+	return ranges
+}
+
+// parseClock converte "HH:MM" em minutos desde a meia-noite
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("Horário inválido: %s (use HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func listAvailabilityWindows(q dbtx, userID int) ([]AvailabilityWindow, error) {
+	rows, err := q.Query(`
+		SELECT id, user_id, weekday, start_time, end_time, time_zone
+		FROM availability_windows
+		WHERE user_id = ?
+		ORDER BY weekday, start_time`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []AvailabilityWindow{}
+	for rows.Next() {
+		var w AvailabilityWindow
+		if err := rows.Scan(&w.ID, &w.UserID, &w.Weekday, &w.StartTime, &w.EndTime, &w.TimeZone); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+func listAvailabilityBlackouts(q dbtx, userID int) ([]AvailabilityBlackout, error) {
+	rows, err := q.Query(`
+		SELECT id, user_id, starts_on, ends_on, starts_at, ends_at, reason, created_at
+		FROM availability_blackouts
+		WHERE user_id = ?
+		ORDER BY starts_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blackouts := []AvailabilityBlackout{}
+	for rows.Next() {
+		var b AvailabilityBlackout
+		if err := rows.Scan(&b.ID, &b.UserID, &b.StartsOn, &b.EndsOn, &b.StartsAt, &b.EndsAt, &b.Reason, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		blackouts = append(blackouts, b)
+	}
+	return blackouts, rows.Err()
+}
+
+// availabilityTimeZone devolve o fuso da disponibilidade do usuário (UTC se
+// ele ainda não publicou nenhuma)
+func availabilityTimeZone(userID int) string {
+	var tz string
+	if err := db.QueryRow("SELECT time_zone FROM availability_windows WHERE user_id = ? LIMIT 1", userID).Scan(&tz); err != nil {
+		return "UTC"
+	}
+	return tz
+}
+
+// freeRanges calcula os períodos livres do entrevistador em [from, to): os
+// intervalos semanais publicados, menos bloqueios e entrevistas agendadas
+func freeRanges(q dbtx, userID int, from, to time.Time) ([]timeRange, error) {
+	windows, err := listAvailabilityWindows(q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	available := []timeRange{}
+	for _, w := range windows {
+		loc, err := time.LoadLocation(w.TimeZone)
+		if err != nil {
+			continue
+		}
+		startMin, err1 := parseClock(w.StartTime)
+		endMin, err2 := parseClock(w.EndTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		// Percorre os dias no fuso do entrevistador, com um dia de folga em
+		// cada ponta para cobrir a diferença de fuso
+		day := from.In(loc).AddDate(0, 0, -1)
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+		for !day.After(to.In(loc).AddDate(0, 0, 1)) {
+			if int(day.Weekday()) == w.Weekday {
+				y, m, d := day.Date()
+				start := time.Date(y, m, d, startMin/60, startMin%60, 0, 0, loc)
+				end := time.Date(y, m, d, endMin/60, endMin%60, 0, 0, loc)
+				available = append(available, timeRange{start.UTC(), end.UTC()})
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	available = intersectRanges(mergeRanges(available), []timeRange{{from, to}})
+
+	busy := []timeRange{}
+	rows, err := q.Query(`
+		SELECT starts_at, ends_at FROM availability_blackouts
+		WHERE user_id = ? AND starts_at < ? AND ends_at > ?`, userID, to, from)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var r timeRange
+		if err := rows.Scan(&r.Start, &r.End); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		busy = append(busy, r)
+	}
+	rows.Close()
+
+	rows, err = q.Query(`
+		SELECT i.starts_at, i.ends_at
+		FROM interviews i
+		JOIN interview_interviewers ii ON ii.interview_id = i.id
+		WHERE ii.user_id = ? AND i.status = 'scheduled' AND i.starts_at < ? AND i.ends_at > ?`, userID, to, from)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var r timeRange
+		if err := rows.Scan(&r.Start, &r.End); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		busy = append(busy, r)
+	}
+	rows.Close()
+
+	return subtractRanges(available, busy), nil
+}
+
+// computeOpenSlots devolve os horários de duração informada em que todos os
+// entrevistadores estão livres, respeitando a antecedência mínima
+func computeOpenSlots(q dbtx, interviewerIDs []int, from, to time.Time, duration time.Duration) ([]timeRange, error) {
+	if earliest := time.Now().UTC().Add(schedulingMinNotice); from.Before(earliest) {
+		from = earliest
+	}
+	if !to.After(from) {
+		return []timeRange{}, nil
+	}
+
+	common := []timeRange{{from, to}}
+	for _, id := range interviewerIDs {
+		free, err := freeRanges(q, id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		common = intersectRanges(common, free)
+	}
+
+	slots := []timeRange{}
+	for _, r := range common {
+		start := r.Start.Truncate(slotStep)
+		if start.Before(r.Start) {
+			start = start.Add(slotStep)
+		}
+		for !start.Add(duration).After(r.End) {
+			slots = append(slots, timeRange{start, start.Add(duration)})
+			start = start.Add(slotStep)
+		}
+	}
+	return slots, nil
+}
+
+// toAvailableSlots formata os horários para a resposta, com o horário local
+func toAvailableSlots(slots []timeRange, loc *time.Location) []AvailableSlot {
+	result := make([]AvailableSlot, 0, len(slots))
+	for _, s := range slots {
+		result = append(result, AvailableSlot{
+			StartsAt: s.Start,
+			EndsAt:   s.End,
+			Local:    s.Start.In(loc).Format("2006-01-02T15:04"),
+		})
+	}
+	return result
+}
+
+// parseDateRange interpreta as datas (YYYY-MM-DD, inclusivas) no fuso
+// informado. Sem datas, considera os próximos 14 dias.
+func parseDateRange(fromValue, toValue string, loc *time.Location) (from, to time.Time, err error) {
+	now := time.Now().In(loc)
+	from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if fromValue != "" {
+		if from, err = time.ParseInLocation("2006-01-02", fromValue, loc); err != nil {
+			return from, to, fmt.Errorf("Data inválida: %s (use AAAA-MM-DD)", fromValue)
+		}
+	}
+
+	to = from.AddDate(0, 0, 14)
+	if toValue != "" {
+		last, err := time.ParseInLocation("2006-01-02", toValue, loc)
+		if err != nil {
+			return from, to, fmt.Errorf("Data inválida: %s (use AAAA-MM-DD)", toValue)
+		}
+		to = last.AddDate(0, 0, 1)
+	}
+
+	if !to.After(from) {
+		return from, to, errors.New("A data final deve ser igual ou posterior à inicial")
+	}
+	if to.Sub(from) > maxSlotRangeDays*24*time.Hour {
+		return from, to, fmt.Errorf("O período não pode passar de %d dias", maxSlotRangeDays)
+	}
+	return from.UTC(), to.UTC(), nil
+}
+
+func getAvailabilityHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	windows, err := listAvailabilityWindows(db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar disponibilidade"})
+		return
+	}
+
+	blackouts, err := listAvailabilityBlackouts(db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar bloqueios"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"time_zone": availabilityTimeZone(userID),
+		"windows":   windows,
+		"blackouts": blackouts,
+	})
+}
+
+// updateAvailabilityHandler substitui a disponibilidade semanal do usuário
+func updateAvailabilityHandler(c *gin.Context) {
+	var req AvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fuso horário inválido: " + req.TimeZone})
+		return
+	}
+
+	byDay := map[int][][2]int{}
+	for _, w := range req.Windows {
+		start, err := parseClock(w.StartTime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		end, err := parseClock(w.EndTime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if end <= start {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("O intervalo %s-%s termina antes de começar", w.StartTime, w.EndTime)})
+			return
+		}
+
+		for _, other := range byDay[w.Weekday] {
+			if start < other[1] && end > other[0] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Há intervalos sobrepostos no mesmo dia da semana"})
+				return
+			}
+		}
+		byDay[w.Weekday] = append(byDay[w.Weekday], [2]int{start, end})
+	}
+
+	userID := c.GetInt("user_id")
+
+	err := withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM availability_windows WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		for _, w := range req.Windows {
+			_, err := tx.Exec(`
+				INSERT INTO availability_windows (user_id, weekday, start_time, end_time, time_zone)
+				VALUES (?, ?, ?, ?, ?)`, userID, w.Weekday, w.StartTime, w.EndTime, req.TimeZone)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar disponibilidade"})
+		return
+	}
+
+	windows, _ := listAvailabilityWindows(db, userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Disponibilidade atualizada com sucesso",
+		"time_zone": req.TimeZone,
+		"windows":   windows,
+	})
+}
+
+// createBlackoutHandler bloqueia dias inteiros no fuso da disponibilidade do usuário
+func createBlackoutHandler(c *gin.Context) {
+	var req BlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	loc, err := time.LoadLocation(availabilityTimeZone(userID))
+	if err != nil {
+		loc = time.UTC
+	}
+
+	startsAt, err := time.ParseInLocation("2006-01-02", req.StartsOn, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data inicial inválida (use AAAA-MM-DD)"})
+		return
+	}
+	lastDay, err := time.ParseInLocation("2006-01-02", req.EndsOn, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data final inválida (use AAAA-MM-DD)"})
+		return
+	}
+	if lastDay.Before(startsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A data final deve ser igual ou posterior à inicial"})
+		return
+	}
+
+	blackout := AvailabilityBlackout{
+		UserID:    userID,
+		StartsOn:  req.StartsOn,
+		EndsOn:    req.EndsOn,
+		StartsAt:  startsAt.UTC(),
+		EndsAt:    lastDay.AddDate(0, 0, 1).UTC(),
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedAt: time.Now(),
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO availability_blackouts (user_id, starts_on, ends_on, starts_at, ends_at, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, userID, blackout.StartsOn, blackout.EndsOn,
+		blackout.StartsAt, blackout.EndsAt, blackout.Reason, blackout.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar bloqueio"})
+		return
+	}
+	id, _ := result.LastInsertId()
+	blackout.ID = int(id)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Bloqueio criado com sucesso",
+		"blackout": blackout,
+	})
+}
+
+func deleteBlackoutHandler(c *gin.Context) {
+	blackoutID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM availability_blackouts WHERE id = ? AND user_id = ?", blackoutID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir bloqueio"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bloqueio não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bloqueio excluído com sucesso"})
+}
+
+// getApplicationSlotsHandler calcula os horários em que todos os
+// entrevistadores informados estão livres
+func getApplicationSlotsHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ctx, ok := requireHiringAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	duration, err := strconv.Atoi(c.DefaultQuery("duration", "60"))
+	if err != nil || duration < 15 || duration > 480 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Duração deve estar entre 15 e 480 minutos"})
+		return
+	}
+
+	var ids []int
+	for _, value := range strings.Split(c.Query("interviewer_ids"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "interviewer_ids inválido"})
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe ao menos um entrevistador em interviewer_ids"})
+		return
+	}
+
+	interviewers, err := loadInterviewers(ctx.JobID, ids)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tz := c.DefaultQuery("time_zone", "UTC")
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fuso horário inválido: " + tz})
+		return
+	}
+
+	from, to, err := parseDateRange(c.Query("from"), c.Query("to"), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slots, err := computeOpenSlots(db, ids, from, to, time.Duration(duration)*time.Minute)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular horários"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"time_zone":        tz,
+		"duration_minutes": duration,
+		"interviewers":     interviewers,
+		"slots":            toAvailableSlots(slots, loc),
+	})
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// at monta um horário UTC em 07/01/2030 (segunda-feira) a partir de "HH:MM"
+func at(clock string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", "2030-01-07 "+clock)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func rng(start, end string) timeRange {
+	return timeRange{at(start), at(end)}
+}
+
+func TestMergeRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []timeRange
+		want []timeRange
+	}{
+		{"vazio", nil, []timeRange{}},
+		{"fora de ordem", []timeRange{rng("13:00", "14:00"), rng("09:00", "10:00")}, []timeRange{rng("09:00", "10:00"), rng("13:00", "14:00")}},
+		{"sobrepostos", []timeRange{rng("09:00", "11:00"), rng("10:00", "12:00")}, []timeRange{rng("09:00", "12:00")}},
+		{"encostados", []timeRange{rng("09:00", "10:00"), rng("10:00", "11:00")}, []timeRange{rng("09:00", "11:00")}},
+		{"contido", []timeRange{rng("09:00", "12:00"), rng("10:00", "11:00")}, []timeRange{rng("09:00", "12:00")}},
+		{"vazio ou invertido", []timeRange{rng("09:00", "09:00"), rng("11:00", "10:00")}, []timeRange{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRanges(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntersectRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []timeRange
+		want []timeRange
+	}{
+		{"sem sobreposição", []timeRange{rng("09:00", "10:00")}, []timeRange{rng("10:00", "11:00")}, []timeRange{}},
+		{"parcial", []timeRange{rng("09:00", "12:00")}, []timeRange{rng("11:00", "13:00")}, []timeRange{rng("11:00", "12:00")}},
+		{
+			"vários trechos",
+			[]timeRange{rng("09:00", "12:00"), rng("13:00", "18:00")},
+			[]timeRange{rng("08:00", "10:00"), rng("11:00", "14:00"), rng("17:00", "19:00")},
+			[]timeRange{rng("09:00", "10:00"), rng("11:00", "12:00"), rng("13:00", "14:00"), rng("17:00", "18:00")},
+		},
+		{"lista vazia", []timeRange{rng("09:00", "12:00")}, nil, []timeRange{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := intersectRanges(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtractRanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		ranges, busy []timeRange
+		want         []timeRange
+	}{
+		{"sem ocupação", []timeRange{rng("09:00", "12:00")}, nil, []timeRange{rng("09:00", "12:00")}},
+		{"no meio", []timeRange{rng("09:00", "12:00")}, []timeRange{rng("10:00", "11:00")}, []timeRange{rng("09:00", "10:00"), rng("11:00", "12:00")}},
+		{"no início", []timeRange{rng("09:00", "12:00")}, []timeRange{rng("08:00", "10:00")}, []timeRange{rng("10:00", "12:00")}},
+		{"tudo", []timeRange{rng("09:00", "12:00")}, []timeRange{rng("08:00", "13:00")}, []timeRange{}},
+		{"encostado", []timeRange{rng("09:00", "12:00")}, []timeRange{rng("12:00", "13:00")}, []timeRange{rng("09:00", "12:00")}},
+		{
+			"vários",
+			[]timeRange{rng("09:00", "12:00"), rng("13:00", "15:00")},
+			[]timeRange{rng("11:30", "13:30"), rng("14:00", "14:30")},
+			[]timeRange{rng("09:00", "11:30"), rng("13:30", "14:00"), rng("14:30", "15:00")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subtractRanges(tt.ranges, tt.busy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeOpenSlots(t *testing.T) {
+	setupTestDB(t)
+
+	exec := func(query string, args ...interface{}) {
+		t.Helper()
+		if _, err := db.Exec(query, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exec("INSERT INTO users (id, email, password, name) VALUES (1, 'a@x.com', '', 'A'), (2, 'b@x.com', '', 'B'), (3, 'c@x.com', '', 'C')")
+	exec("INSERT INTO jobs (id, title, description, company, location, type, user_id) VALUES (1, 'Dev', 'Dev', 'X', 'SP', 'full-time', 1)")
+	exec("INSERT INTO applications (id, job_id, user_id) VALUES (1, 1, 3)")
+
+	// Segunda-feira: 09h-12h em São Paulo (12h-15h UTC) e 13h-17h em UTC
+	exec("INSERT INTO availability_windows (user_id, weekday, start_time, end_time, time_zone) VALUES (1, 1, '09:00', '12:00', 'America/Sao_Paulo')")
+	exec("INSERT INTO availability_windows (user_id, weekday, start_time, end_time, time_zone) VALUES (2, 1, '13:00', '17:00', 'UTC')")
+	// Entrevista já marcada para o segundo entrevistador
+	exec(`INSERT INTO interviews (application_id, type, starts_at, ends_at, time_zone, uid, created_by)
+		VALUES (1, 'video', ?, ?, 'UTC', 'i1', 1)`, at("13:30"), at("14:00"))
+	// Entrevista cancelada não ocupa a agenda
+	exec(`INSERT INTO interviews (application_id, type, starts_at, ends_at, time_zone, status, uid, created_by)
+		VALUES (1, 'video', ?, ?, 'UTC', 'cancelled', 'i2', 1)`, at("14:00"), at("15:00"))
+	exec("INSERT INTO interview_interviewers (interview_id, user_id) VALUES (1, 2), (2, 2)")
+	// A segunda-feira seguinte está bloqueada para o primeiro entrevistador
+	exec(`INSERT INTO availability_blackouts (user_id, starts_on, ends_on, starts_at, ends_at)
+		VALUES (1, '2030-01-14', '2030-01-14', ?, ?)`, at("03:00").AddDate(0, 0, 7), at("03:00").AddDate(0, 0, 8))
+
+	week := [2]time.Time{at("00:00"), at("00:00").AddDate(0, 0, 14)}
+
+	tests := []struct {
+		name         string
+		interviewers []int
+		from, to     time.Time
+		duration     time.Duration
+		want         []timeRange
+	}{
+		{
+			name:         "horários comuns aos dois",
+			interviewers: []int{1, 2},
+			from:         week[0], to: week[1],
+			duration: 30 * time.Minute,
+			want:     []timeRange{rng("13:00", "13:30"), rng("14:00", "14:30"), rng("14:30", "15:00")},
+		},
+		{
+			name:         "uma hora",
+			interviewers: []int{1, 2},
+			from:         week[0], to: week[1],
+			duration: time.Hour,
+			want:     []timeRange{rng("14:00", "15:00")},
+		},
+		{
+			name:         "início fora do passo",
+			interviewers: []int{1},
+			from:         at("14:10"), to: week[1],
+			duration: 30 * time.Minute,
+			want:     []timeRange{rng("14:30", "15:00")},
+		},
+		{
+			name:         "bloqueio remove a semana seguinte",
+			interviewers: []int{1},
+			from:         at("00:00").AddDate(0, 0, 7), to: week[1],
+			duration: 30 * time.Minute,
+			want:     []timeRange{},
+		},
+		{
+			name:         "período no passado",
+			interviewers: []int{1, 2},
+			from:         time.Now().AddDate(0, 0, -7), to: time.Now(),
+			duration: 30 * time.Minute,
+			want:     []timeRange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := computeOpenSlots(db, tt.interviewers, tt.from, tt.to, tt.duration)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				got[i] = timeRange{got[i].Start.UTC(), got[i].End.UTC()}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("fuso America/Sao_Paulo indisponível")
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     [2]string
+		wantErr  bool
+	}{
+		{name: "um dia", from: "2030-01-07", to: "2030-01-07", want: [2]string{"2030-01-07T03:00:00Z", "2030-01-08T03:00:00Z"}},
+		{name: "sem fim", from: "2030-01-07", want: [2]string{"2030-01-07T03:00:00Z", "2030-01-21T03:00:00Z"}},
+		{name: "data inválida", from: "07/01/2030", wantErr: true},
+		{name: "fim antes do início", from: "2030-01-07", to: "2030-01-06", wantErr: true},
+		{name: "período longo demais", from: "2030-01-01", to: "2030-03-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseDateRange(tt.from, tt.to, saoPaulo)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, obteve %v - %v", from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := [2]string{from.Format(time.RFC3339), to.Format(time.RFC3339)}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
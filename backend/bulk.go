@@ -0,0 +1,286 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errBulkForbidden  = errors.New("Apenas a equipe de contratação pode alterar esta candidatura")
+	errBulkNotMovable = errors.New("Candidaturas retiradas ou contratadas não podem ser movidas")
+	errBulkWithdrawn  = errors.New("Candidaturas retiradas não recebem mensagens")
+	// No modo atômico, as candidaturas válidas não são alteradas se outra falhar
+	errBulkNotApplied = errors.New("Não aplicada: outra candidatura do lote falhou")
+)
+
+// bulkAction guarda os parâmetros da ação em lote já validados
+type bulkAction struct {
+	req        *BulkApplicationRequest
+	userID     int
+	reason     *RejectionReason
+	addTags    []string
+	removeTags []string
+}
+
+// bulkItem é uma candidatura do lote que passou nas verificações e está
+// pronta para ser gravada
+type bulkItem struct {
+	ctx     *interviewContext
+	message *CandidateMessage
+}
+
+// newBulkAction valida os parâmetros que não dependem das candidaturas
+func newBulkAction(req *BulkApplicationRequest, userID int) (*bulkAction, error) {
+	action := &bulkAction{req: req, userID: userID}
+
+	switch req.Action {
+	case "move":
+		if req.Status == "" {
+			return nil, errors.New("Informe o status de destino")
+		}
+	case "reject":
+		reason, err := loadActiveRejectionReason(req.ReasonID)
+		if err != nil {
+			return nil, err
+		}
+		action.reason = reason
+	case "tag":
+		action.addTags = normalizeTags(req.AddTags)
+		action.removeTags = normalizeTags(req.RemoveTags)
+		if len(action.addTags) == 0 && len(action.removeTags) == 0 {
+			return nil, errors.New("Informe as tags a adicionar ou remover")
+		}
+	case "message":
+		if req.TemplateID == nil {
+			req.Subject = strings.TrimSpace(req.Subject)
+			req.Body = strings.TrimSpace(req.Body)
+			if req.Subject == "" || req.Body == "" {
+				return nil, errors.New("Informe template_id ou subject e body")
+			}
+			if err := validateMessageTemplate(&MessageTemplateRequest{Subject: req.Subject, Body: req.Body}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return action, nil
+}
+
+// prepare carrega a candidatura e confirma que o usuário pode aplicar a ação
+// a ela. Nada é gravado aqui.
+func (a *bulkAction) prepare(appID int) (*bulkItem, error) {
+	ctx, err := loadInterviewContext(appID)
+	if err != nil {
+		return nil, errors.New("Candidatura não encontrada")
+	}
+	if !isHiringSide(ctx.JobID, a.userID) {
+		return nil, errBulkForbidden
+	}
+
+	item := &bulkItem{ctx: ctx}
+	switch a.req.Action {
+	case "move":
+		if ctx.Status == "withdrawn" || ctx.Status == "hired" {
+			return nil, errBulkNotMovable
+		}
+	case "reject":
+		if err := checkRejectable(ctx.Status); err != nil {
+			return nil, err
+		}
+		item.message, err = newRejectionMessage(&RejectRequest{
+			NotifyCandidate: a.req.NotifyCandidate,
+			TemplateID:      a.req.TemplateID,
+			DelayMinutes:    a.req.DelayMinutes,
+		}, a.userID, ctx)
+		if err != nil {
+			return nil, err
+		}
+	case "message":
+		if ctx.Status == "withdrawn" {
+			return nil, errBulkWithdrawn
+		}
+		subject, body, err := composeCandidateMessage(a.req.TemplateID, a.userID, a.req.Subject, a.req.Body, ctx)
+		if err != nil {
+			return nil, err
+		}
+		sendAt := time.Now().UTC()
+		if a.req.DelayMinutes != nil {
+			sendAt = sendAt.Add(time.Duration(*a.req.DelayMinutes) * time.Minute)
+		}
+		item.message = &CandidateMessage{
+			ApplicationID: appID,
+			Kind:          "message",
+			Subject:       subject,
+			Body:          body,
+			SendAt:        sendAt,
+			CreatedBy:     a.userID,
+			CreatedAt:     time.Now(),
+		}
+	}
+	return item, nil
+}
+
+// apply grava a ação para uma candidatura dentro da transação
+func (a *bulkAction) apply(tx *sql.Tx, item *bulkItem) error {
+	ctx := item.ctx
+	switch a.req.Action {
+	case "move":
+		if ctx.Status == a.req.Status {
+			return nil
+		}
+		// Sair de "rejected" desfaz a reprovação; a mensagem ainda não enviada
+		// é descartada pelo envio agendado
+		result, err := tx.Exec(`
+			UPDATE applications SET status = ?, rejection_reason_id = NULL, rejection_note = '', rejected_at = NULL, updated_at = ?
+			WHERE id = ? AND status = ?`, a.req.Status, time.Now(), ctx.ApplicationID, ctx.Status)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errApplicationChanged
+		}
+		return recordStatusChange(tx, ctx.ApplicationID, ctx.Status, a.req.Status, "", a.userID)
+	case "reject":
+		return rejectApplication(tx, ctx, a.reason, strings.TrimSpace(a.req.Note), item.message, a.userID)
+	case "tag":
+		for _, tag := range a.addTags {
+			_, err := tx.Exec(`
+				INSERT OR IGNORE INTO application_tags (application_id, tag, created_by, created_at)
+				VALUES (?, ?, ?, ?)`, ctx.ApplicationID, tag, a.userID, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+		for _, tag := range a.removeTags {
+			if _, err := tx.Exec("DELETE FROM application_tags WHERE application_id = ? AND tag = ?", ctx.ApplicationID, tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "message":
+		return insertCandidateMessage(tx, item.message)
+	}
+	return nil
+}
+
+// bulkApplicationsHandler aplica uma ação a várias candidaturas, verificando a
+// permissão de cada uma. No modo best_effort (padrão) cada candidatura é
+// gravada separadamente; no modo atomic, ou todas são alteradas ou nenhuma.
+func bulkApplicationsHandler(c *gin.Context) {
+	var req BulkApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = "best_effort"
+	}
+
+	action, err := newBulkAction(&req, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results := []BulkItemResult{}
+	items := map[int]*bulkItem{}
+	seen := map[int]bool{}
+	failed := false
+	for _, appID := range req.ApplicationIDs {
+		if seen[appID] {
+			continue
+		}
+		seen[appID] = true
+
+		result := BulkItemResult{ApplicationID: appID}
+		item, err := action.prepare(appID)
+		if err != nil {
+			result.Error = err.Error()
+			failed = true
+		} else {
+			items[appID] = item
+		}
+		results = append(results, result)
+	}
+
+	if req.Mode == "atomic" {
+		if !failed {
+			err = withTx(func(tx *sql.Tx) error {
+				dueNow := false
+				for i := range results {
+					item := items[results[i].ApplicationID]
+					if err := action.apply(tx, item); err != nil {
+						if errors.Is(err, errApplicationChanged) {
+							results[i].Error = err.Error()
+						}
+						return err
+					}
+					dueNow = dueNow || messageDueNow(item.message)
+				}
+				if dueNow {
+					return enqueueBackgroundJob(tx, "candidate_messages.send", nil)
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errApplicationChanged) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao aplicar ação em lote"})
+				return
+			}
+			failed = err != nil
+		}
+
+		for i := range results {
+			if failed && results[i].Error == "" {
+				results[i].Error = errBulkNotApplied.Error()
+			}
+			results[i].Success = !failed
+		}
+	} else {
+		for i := range results {
+			item, ok := items[results[i].ApplicationID]
+			if !ok {
+				continue
+			}
+			err := withTx(func(tx *sql.Tx) error {
+				if err := action.apply(tx, item); err != nil {
+					return err
+				}
+				if messageDueNow(item.message) {
+					return enqueueBackgroundJob(tx, "candidate_messages.send", nil)
+				}
+				return nil
+			})
+			switch {
+			case err == nil:
+				results[i].Success = true
+			case errors.Is(err, errApplicationChanged):
+				results[i].Error = err.Error()
+			default:
+				results[i].Error = "Erro ao atualizar candidatura"
+			}
+		}
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	status := http.StatusOK
+	if req.Mode == "atomic" && succeeded == 0 {
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"action":    req.Action,
+		"mode":      req.Mode,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
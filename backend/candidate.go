@@ -0,0 +1,514 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Formato usado nas datas de experiências e formação
+const monthLayout = "2006-01"
+
+// validatePeriod confere o formato AAAA-MM e se o fim não é anterior ao início
+func validatePeriod(start, end string, current bool) error {
+	startDate, err := time.Parse(monthLayout, start)
+	if err != nil {
+		return errors.New("Data de início inválida, use o formato AAAA-MM")
+	}
+
+	if current && end != "" {
+		return errors.New("Uma experiência atual não pode ter data de término")
+	}
+
+	if end == "" {
+		return nil
+	}
+
+	endDate, err := time.Parse(monthLayout, end)
+	if err != nil {
+		return errors.New("Data de término inválida, use o formato AAAA-MM")
+	}
+	if endDate.Before(startDate) {
+		return errors.New("A data de término não pode ser anterior à data de início")
+	}
+	return nil
+}
+
+// normalizeSkill remove espaços extras e gera a chave usada para evitar duplicatas
+func normalizeSkill(name string) (display, slug string) {
+	display = strings.Join(strings.Fields(name), " ")
+	slug = strings.ToLower(strings.ReplaceAll(display, " ", "-"))
+	return display, slug
+}
+
+func listExperiences(userID int) ([]Experience, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id, title, company, location, start_date, end_date, current, description, created_at, updated_at
+		FROM experiences
+		WHERE user_id = ?
+		ORDER BY current DESC, start_date DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	experiences := []Experience{}
+	for rows.Next() {
+		var exp Experience
+		err := rows.Scan(&exp.ID, &exp.UserID, &exp.Title, &exp.Company, &exp.Location, &exp.StartDate,
+			&exp.EndDate, &exp.Current, &exp.Description, &exp.CreatedAt, &exp.UpdatedAt)
+		if err != nil {
+			continue
+		}
+		experiences = append(experiences, exp)
+	}
+	return experiences, rows.Err()
+}
+
+func listEducations(userID int) ([]Education, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id, institution, degree, field_of_study, start_date, end_date, description, created_at, updated_at
+		FROM educations
+		WHERE user_id = ?
+		ORDER BY start_date DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	educations := []Education{}
+	for rows.Next() {
+		var edu Education
+		err := rows.Scan(&edu.ID, &edu.UserID, &edu.Institution, &edu.Degree, &edu.FieldOfStudy,
+			&edu.StartDate, &edu.EndDate, &edu.Description, &edu.CreatedAt, &edu.UpdatedAt)
+		if err != nil {
+			continue
+		}
+		educations = append(educations, edu)
+	}
+	return educations, rows.Err()
+}
+
+func listUserSkills(userID int) ([]UserSkill, error) {
+	rows, err := db.Query("SELECT user_id, name, slug FROM user_skills WHERE user_id = ? ORDER BY name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := []UserSkill{}
+	for rows.Next() {
+		var skill UserSkill
+		if err := rows.Scan(&skill.UserID, &skill.Name, &skill.Slug); err != nil {
+			continue
+		}
+		skills = append(skills, skill)
+	}
+	return skills, rows.Err()
+}
+
+func listProfileLinks(userID int) ([]ProfileLink, error) {
+	rows, err := db.Query("SELECT id, user_id, label, url FROM profile_links WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []ProfileLink{}
+	for rows.Next() {
+		var link ProfileLink
+		if err := rows.Scan(&link.ID, &link.UserID, &link.Label, &link.URL); err != nil {
+			continue
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// Experiências profissionais
+
+func getExperiencesHandler(c *gin.Context) {
+	experiences, err := listExperiences(c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar experiências"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"experiences": experiences})
+}
+
+func createExperienceHandler(c *gin.Context) {
+	var req ExperienceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validatePeriod(req.StartDate, req.EndDate, req.Current); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	now := time.Now()
+
+	result, err := db.Exec(`
+		INSERT INTO experiences (user_id, title, company, location, start_date, end_date, current, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, req.Title, req.Company, req.Location, req.StartDate, req.EndDate, req.Current, req.Description, now, now)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar experiência"})
+		return
+	}
+
+	expID, _ := result.LastInsertId()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Experiência adicionada com sucesso",
+		"experience_id": expID,
+	})
+}
+
+func updateExperienceHandler(c *gin.Context) {
+	expID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ExperienceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validatePeriod(req.StartDate, req.EndDate, req.Current); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec(`
+		UPDATE experiences SET title = ?, company = ?, location = ?, start_date = ?, end_date = ?, current = ?, description = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?`,
+		req.Title, req.Company, req.Location, req.StartDate, req.EndDate, req.Current, req.Description, time.Now(), expID, userID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar experiência"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Experiência não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Experiência atualizada com sucesso"})
+}
+
+func deleteExperienceHandler(c *gin.Context) {
+	expID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec("DELETE FROM experiences WHERE id = ? AND user_id = ?", expID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir experiência"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Experiência não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Experiência excluída com sucesso"})
+}
+
+// Formação acadêmica
+
+func getEducationsHandler(c *gin.Context) {
+	educations, err := listEducations(c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar formação"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"educations": educations})
+}
+
+func createEducationHandler(c *gin.Context) {
+	var req EducationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validatePeriod(req.StartDate, req.EndDate, false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	now := time.Now()
+
+	result, err := db.Exec(`
+		INSERT INTO educations (user_id, institution, degree, field_of_study, start_date, end_date, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, req.Institution, req.Degree, req.FieldOfStudy, req.StartDate, req.EndDate, req.Description, now, now)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar formação"})
+		return
+	}
+
+	eduID, _ := result.LastInsertId()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Formação adicionada com sucesso",
+		"education_id": eduID,
+	})
+}
+
+func updateEducationHandler(c *gin.Context) {
+	eduID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req EducationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validatePeriod(req.StartDate, req.EndDate, false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec(`
+		UPDATE educations SET institution = ?, degree = ?, field_of_study = ?, start_date = ?, end_date = ?, description = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?`,
+		req.Institution, req.Degree, req.FieldOfStudy, req.StartDate, req.EndDate, req.Description, time.Now(), eduID, userID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar formação"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Formação não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Formação atualizada com sucesso"})
+}
+
+func deleteEducationHandler(c *gin.Context) {
+	eduID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec("DELETE FROM educations WHERE id = ? AND user_id = ?", eduID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir formação"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Formação não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Formação excluída com sucesso"})
+}
+
+// Habilidades
+
+func getSkillsHandler(c *gin.Context) {
+	skills, err := listUserSkills(c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar habilidades"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"skills": skills})
+}
+
+// addSkillsHandler adiciona habilidades sem duplicar as já cadastradas
+func addSkillsHandler(c *gin.Context) {
+	var req SkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	err := withTx(func(tx *sql.Tx) error {
+		for _, name := range req.Skills {
+			display, slug, err := canonicalSkill(tx, name)
+			if err != nil {
+				return err
+			}
+			if slug == "" {
+				continue
+			}
+
+			_, err = tx.Exec("INSERT OR IGNORE INTO user_skills (user_id, name, slug) VALUES (?, ?, ?)", userID, display, slug)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao adicionar habilidades"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Habilidades adicionadas com sucesso"})
+}
+
+// replaceSkillsHandler substitui toda a lista de habilidades do usuário
+func replaceSkillsHandler(c *gin.Context) {
+	var req SkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	err := withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM user_skills WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+
+		for _, name := range req.Skills {
+			display, slug, err := canonicalSkill(tx, name)
+			if err != nil {
+				return err
+			}
+			if slug == "" {
+				continue
+			}
+
+			_, err = tx.Exec("INSERT OR IGNORE INTO user_skills (user_id, name, slug) VALUES (?, ?, ?)", userID, display, slug)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar habilidades"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Habilidades atualizadas com sucesso"})
+}
+
+func deleteSkillHandler(c *gin.Context) {
+	_, slug, err := canonicalSkill(db, c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir habilidade"})
+		return
+	}
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec("DELETE FROM user_skills WHERE user_id = ? AND slug = ?", userID, slug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir habilidade"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Habilidade não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Habilidade excluída com sucesso"})
+}
+
+// Links (LinkedIn, GitHub, portfólio...)
+
+func getProfileLinksHandler(c *gin.Context) {
+	links, err := listProfileLinks(c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+func createProfileLinkHandler(c *gin.Context) {
+	var req ProfileLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "O link deve começar com http:// ou https://"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec("INSERT INTO profile_links (user_id, label, url) VALUES (?, ?, ?)", userID, req.Label, req.URL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar link"})
+		return
+	}
+
+	linkID, _ := result.LastInsertId()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Link adicionado com sucesso",
+		"link_id": linkID,
+	})
+}
+
+func deleteProfileLinkHandler(c *gin.Context) {
+	linkID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec("DELETE FROM profile_links WHERE id = ? AND user_id = ?", linkID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir link"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Link excluído com sucesso"})
+}
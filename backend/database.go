@@ -2,20 +2,34 @@ package main
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 var db *sql.DB
 
 func initDB() {
+	openDB("./recruitment.db")
+}
+
+// openDB abre o banco SQLite no caminho indicado e cria as tabelas
+func openDB(path string) {
 	var err error
-	db, err = sql.Open("sqlite3", "./recruitment.db")
+	// As opções valem para cada conexão do pool: chaves estrangeiras ativas,
+	// espera por locks em vez de falhar na hora e transações que já reservam a
+	// escrita no início (evita deadlock entre leitura e escrita concorrentes)
+	db, err = sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate")
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	var foreignKeys int
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil || foreignKeys != 1 {
+		log.Fatal("Não foi possível ativar as chaves estrangeiras do SQLite")
+	}
+
 	// Criar tabelas
 	createTables()
 }
@@ -61,20 +75,804 @@ func createTables() {
 		FOREIGN KEY (user_id) REFERENCES users (id)
 	);`
 
-	_, err := db.Exec(createUsersTable)
+	// Tabela de chaves de API
+	createAPIKeysTable := `
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		prefix TEXT UNIQUE NOT NULL,
+		key_hash TEXT NOT NULL,
+		scopes TEXT NOT NULL,
+		expires_at DATETIME,
+		last_used_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	// Tabela de tokens de redefinição de senha
+	createPasswordResetsTable := `
+	CREATE TABLE IF NOT EXISTS password_resets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		token_hash TEXT UNIQUE NOT NULL,
+		expires_at DATETIME NOT NULL,
+		used_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	// Tabelas do perfil do candidato
+	createProfileLinksTable := `
+	CREATE TABLE IF NOT EXISTS profile_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		label TEXT NOT NULL,
+		url TEXT NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createExperiencesTable := `
+	CREATE TABLE IF NOT EXISTS experiences (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		company TEXT NOT NULL,
+		location TEXT DEFAULT '',
+		start_date TEXT NOT NULL,
+		end_date TEXT DEFAULT '',
+		current BOOLEAN DEFAULT 0,
+		description TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createEducationsTable := `
+	CREATE TABLE IF NOT EXISTS educations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		institution TEXT NOT NULL,
+		degree TEXT NOT NULL,
+		field_of_study TEXT DEFAULT '',
+		start_date TEXT NOT NULL,
+		end_date TEXT DEFAULT '',
+		description TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createUserSkillsTable := `
+	CREATE TABLE IF NOT EXISTS user_skills (
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL,
+		PRIMARY KEY (user_id, slug),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	// Tabelas de currículos
+	createResumesTable := `
+	CREATE TABLE IF NOT EXISTS resumes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		storage_key TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createApplicationResumesTable := `
+	CREATE TABLE IF NOT EXISTS application_resumes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER UNIQUE NOT NULL,
+		resume_id INTEGER NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		storage_key TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id)
+	);`
+
+	// Tabelas de perguntas de triagem e respostas
+	createScreeningQuestionsTable := `
+	CREATE TABLE IF NOT EXISTS screening_questions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_id INTEGER NOT NULL,
+		question TEXT NOT NULL,
+		type TEXT NOT NULL,
+		options TEXT DEFAULT '[]',
+		required BOOLEAN DEFAULT 0,
+		knockout_values TEXT DEFAULT '[]',
+		min_value REAL,
+		max_value REAL,
+		position INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (job_id) REFERENCES jobs (id)
+	);`
+
+	createApplicationAnswersTable := `
+	CREATE TABLE IF NOT EXISTS application_answers (
+		application_id INTEGER NOT NULL,
+		question_id INTEGER NOT NULL,
+		question TEXT NOT NULL,
+		value TEXT NOT NULL,
+		knockout BOOLEAN DEFAULT 0,
+		PRIMARY KEY (application_id, question_id),
+		FOREIGN KEY (application_id) REFERENCES applications (id)
+	);`
+
+	// Histórico de status das candidaturas
+	createApplicationStatusHistoryTable := `
+	CREATE TABLE IF NOT EXISTS application_status_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		from_status TEXT DEFAULT '',
+		to_status TEXT NOT NULL,
+		reason TEXT DEFAULT '',
+		changed_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id)
+	);`
+
+	// Equipe de contratação de cada vaga, além do dono
+	createJobTeamMembersTable := `
+	CREATE TABLE IF NOT EXISTS job_team_members (
+		job_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		added_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (job_id, user_id),
+		FOREIGN KEY (job_id) REFERENCES jobs (id),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	// Notas internas das candidaturas e usuários mencionados
+	createApplicationNotesTable := `
+	CREATE TABLE IF NOT EXISTS application_notes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		author_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		edited_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (author_id) REFERENCES users (id)
+	);`
+
+	createNoteMentionsTable := `
+	CREATE TABLE IF NOT EXISTS note_mentions (
+		note_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		PRIMARY KEY (note_id, user_id),
+		FOREIGN KEY (note_id) REFERENCES application_notes (id),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	// Notificações exibidas dentro do sistema
+	createNotificationsTable := `
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		message TEXT NOT NULL,
+		application_id INTEGER,
+		read_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	// Critérios de avaliação das vagas e scorecards dos entrevistadores
+	createScorecardCriteriaTable := `
+	CREATE TABLE IF NOT EXISTS scorecard_criteria (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT DEFAULT '',
+		scale_max INTEGER NOT NULL DEFAULT 5,
+		position INTEGER DEFAULT 0,
+		FOREIGN KEY (job_id) REFERENCES jobs (id)
+	);`
+
+	createScorecardsTable := `
+	CREATE TABLE IF NOT EXISTS scorecards (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		interviewer_id INTEGER NOT NULL,
+		recommendation TEXT DEFAULT '',
+		comments TEXT DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'draft',
+		submitted_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (application_id, interviewer_id),
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (interviewer_id) REFERENCES users (id)
+	);`
+
+	createScorecardRatingsTable := `
+	CREATE TABLE IF NOT EXISTS scorecard_ratings (
+		scorecard_id INTEGER NOT NULL,
+		criterion_id INTEGER NOT NULL,
+		criterion TEXT NOT NULL,
+		rating INTEGER NOT NULL,
+		scale_max INTEGER NOT NULL,
+		comment TEXT DEFAULT '',
+		PRIMARY KEY (scorecard_id, criterion_id),
+		FOREIGN KEY (scorecard_id) REFERENCES scorecards (id)
+	);`
+
+	// Entrevistas das candidaturas e seus entrevistadores
+	createInterviewsTable := `
+	CREATE TABLE IF NOT EXISTS interviews (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		starts_at DATETIME NOT NULL,
+		ends_at DATETIME NOT NULL,
+		time_zone TEXT NOT NULL,
+		location TEXT DEFAULT '',
+		video_link TEXT DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'scheduled',
+		sequence INTEGER NOT NULL DEFAULT 0,
+		uid TEXT UNIQUE NOT NULL,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (created_by) REFERENCES users (id)
+	);`
+
+	createInterviewInterviewersTable := `
+	CREATE TABLE IF NOT EXISTS interview_interviewers (
+		interview_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		PRIMARY KEY (interview_id, user_id),
+		FOREIGN KEY (interview_id) REFERENCES interviews (id),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createAvailabilityWindowsTable := `
+	CREATE TABLE IF NOT EXISTS availability_windows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		weekday INTEGER NOT NULL,
+		start_time TEXT NOT NULL,
+		end_time TEXT NOT NULL,
+		time_zone TEXT NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createAvailabilityBlackoutsTable := `
+	CREATE TABLE IF NOT EXISTS availability_blackouts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		starts_on TEXT NOT NULL,
+		ends_on TEXT NOT NULL,
+		starts_at DATETIME NOT NULL,
+		ends_at DATETIME NOT NULL,
+		reason TEXT DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createSchedulingLinksTable := `
+	CREATE TABLE IF NOT EXISTS scheduling_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		duration_minutes INTEGER NOT NULL,
+		time_zone TEXT NOT NULL,
+		location TEXT DEFAULT '',
+		video_link TEXT DEFAULT '',
+		window_start DATETIME NOT NULL,
+		window_end DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		interview_id INTEGER,
+		revoked_at DATETIME,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (interview_id) REFERENCES interviews (id),
+		FOREIGN KEY (created_by) REFERENCES users (id)
+	);`
+
+	createSchedulingLinkInterviewersTable := `
+	CREATE TABLE IF NOT EXISTS scheduling_link_interviewers (
+		link_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		PRIMARY KEY (link_id, user_id),
+		FOREIGN KEY (link_id) REFERENCES scheduling_links (id),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createJobOfferApproversTable := `
+	CREATE TABLE IF NOT EXISTS job_offer_approvers (
+		job_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (job_id, user_id),
+		FOREIGN KEY (job_id) REFERENCES jobs (id),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createOfferTemplatesTable := `
+	CREATE TABLE IF NOT EXISTS offer_templates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createOffersTable := `
+	CREATE TABLE IF NOT EXISTS offers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'draft',
+		salary REAL NOT NULL,
+		currency TEXT NOT NULL,
+		pay_period TEXT NOT NULL,
+		bonus TEXT DEFAULT '',
+		start_date TEXT NOT NULL,
+		expires_at DATETIME NOT NULL,
+		notes TEXT DEFAULT '',
+		template_id INTEGER,
+		letter TEXT DEFAULT '',
+		decline_reason TEXT DEFAULT '',
+		created_by INTEGER NOT NULL,
+		sent_at DATETIME,
+		responded_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (template_id) REFERENCES offer_templates (id) ON DELETE SET NULL,
+		FOREIGN KEY (created_by) REFERENCES users (id)
+	);`
+
+	createOfferApprovalsTable := `
+	CREATE TABLE IF NOT EXISTS offer_approvals (
+		offer_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		comment TEXT DEFAULT '',
+		decided_at DATETIME,
+		PRIMARY KEY (offer_id, user_id),
+		FOREIGN KEY (offer_id) REFERENCES offers (id),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createRejectionReasonsTable := `
+	CREATE TABLE IF NOT EXISTS rejection_reasons (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		code TEXT UNIQUE NOT NULL,
+		label TEXT NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	createMessageTemplatesTable := `
+	CREATE TABLE IF NOT EXISTS message_templates (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createCandidateMessagesTable := `
+	CREATE TABLE IF NOT EXISTS candidate_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		body TEXT NOT NULL,
+		send_at DATETIME NOT NULL,
+		sent_at DATETIME,
+		cancelled_at DATETIME,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (created_by) REFERENCES users (id)
+	);`
+
+	createApplicationTagsTable := `
+	CREATE TABLE IF NOT EXISTS application_tags (
+		application_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		created_by INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (application_id, tag),
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (created_by) REFERENCES users (id)
+	);`
+
+	createThreadMessagesTable := `
+	CREATE TABLE IF NOT EXISTS thread_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		application_id INTEGER NOT NULL,
+		sender_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (application_id) REFERENCES applications (id),
+		FOREIGN KEY (sender_id) REFERENCES users (id)
+	);`
+
+	createThreadRecipientsTable := `
+	CREATE TABLE IF NOT EXISTS thread_message_recipients (
+		message_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		read_at DATETIME,
+		emailed_at DATETIME,
+		PRIMARY KEY (message_id, user_id),
+		FOREIGN KEY (message_id) REFERENCES thread_messages (id),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createThreadAttachmentsTable := `
+	CREATE TABLE IF NOT EXISTS thread_attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		message_id INTEGER NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		storage_key TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (message_id) REFERENCES thread_messages (id)
+	);`
+
+	createNotificationSettingsTable := `
+	CREATE TABLE IF NOT EXISTS notification_settings (
+		user_id INTEGER PRIMARY KEY,
+		email_digest TEXT NOT NULL DEFAULT 'off',
+		webhook_url TEXT NOT NULL DEFAULT '',
+		webhook_secret TEXT NOT NULL DEFAULT '',
+		last_digest_at DATETIME,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createNotificationPreferencesTable := `
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id INTEGER NOT NULL,
+		event TEXT NOT NULL,
+		in_app BOOLEAN NOT NULL,
+		email BOOLEAN NOT NULL,
+		webhook BOOLEAN NOT NULL,
+		PRIMARY KEY (user_id, event),
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createNotificationDeliveriesTable := `
+	CREATE TABLE IF NOT EXISTS notification_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		event TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		message TEXT NOT NULL,
+		application_id INTEGER,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		sent_at DATETIME,
+		failed_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id),
+		FOREIGN KEY (application_id) REFERENCES applications (id)
+	);`
+
+	createWebhookEndpointsTable := `
+	CREATE TABLE IF NOT EXISTS webhook_endpoints (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL,
+		all_jobs BOOLEAN NOT NULL DEFAULT 0,
+		active BOOLEAN NOT NULL DEFAULT 1,
+		consecutive_failures INTEGER NOT NULL DEFAULT 0,
+		disabled_at DATETIME,
+		disabled_reason TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createWebhookDeliveriesTable := `
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		endpoint_id INTEGER NOT NULL,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME,
+		last_status_code INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		replay_of INTEGER,
+		delivered_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id),
+		FOREIGN KEY (replay_of) REFERENCES webhook_deliveries (id)
+	);`
+
+	createSavedJobsTable := `
+	CREATE TABLE IF NOT EXISTS saved_jobs (
+		user_id INTEGER NOT NULL,
+		job_id INTEGER NOT NULL,
+		reminded_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, job_id),
+		FOREIGN KEY (user_id) REFERENCES users (id),
+		FOREIGN KEY (job_id) REFERENCES jobs (id)
+	);`
+
+	createJobAlertsTable := `
+	CREATE TABLE IF NOT EXISTS job_alerts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		keywords TEXT NOT NULL DEFAULT '',
+		location TEXT NOT NULL DEFAULT '',
+		type TEXT NOT NULL DEFAULT '',
+		min_salary INTEGER NOT NULL DEFAULT 0,
+		frequency TEXT NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT 1,
+		last_run_at DATETIME NOT NULL,
+		last_sent_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users (id)
+	);`
+
+	createJobSkillsTable := `
+	CREATE TABLE IF NOT EXISTS job_skills (
+		job_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL,
+		required BOOLEAN NOT NULL DEFAULT 1,
+		PRIMARY KEY (job_id, slug),
+		FOREIGN KEY (job_id) REFERENCES jobs (id)
+	);`
+
+	createSkillsTable := `
+	CREATE TABLE IF NOT EXISTS skills (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL UNIQUE,
+		category TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	createSkillAliasesTable := `
+	CREATE TABLE IF NOT EXISTS skill_aliases (
+		slug TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		skill_id INTEGER NOT NULL,
+		FOREIGN KEY (skill_id) REFERENCES skills (id)
+	);`
+
+	createBackgroundJobsTable := `
+	CREATE TABLE IF NOT EXISTS background_jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL,
+		run_at DATETIME NOT NULL,
+		lease_owner TEXT NOT NULL DEFAULT '',
+		lease_expires_at DATETIME,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		finished_at DATETIME
+	);`
+
+	createScheduledTasksTable := `
+	CREATE TABLE IF NOT EXISTS scheduled_tasks (
+		kind TEXT PRIMARY KEY,
+		next_run_at DATETIME NOT NULL,
+		last_enqueued_at DATETIME
+	);`
+
+	tables := []string{
+		createUsersTable,
+		createJobsTable,
+		createApplicationsTable,
+		createAPIKeysTable,
+		createPasswordResetsTable,
+		createProfileLinksTable,
+		createExperiencesTable,
+		createEducationsTable,
+		createUserSkillsTable,
+		createResumesTable,
+		createApplicationResumesTable,
+		createScreeningQuestionsTable,
+		createApplicationAnswersTable,
+		createApplicationStatusHistoryTable,
+		createJobTeamMembersTable,
+		createApplicationNotesTable,
+		createNoteMentionsTable,
+		createNotificationsTable,
+		createScorecardCriteriaTable,
+		createScorecardsTable,
+		createScorecardRatingsTable,
+		createInterviewsTable,
+		createInterviewInterviewersTable,
+		createAvailabilityWindowsTable,
+		createAvailabilityBlackoutsTable,
+		createSchedulingLinksTable,
+		createSchedulingLinkInterviewersTable,
+		createJobOfferApproversTable,
+		createOfferTemplatesTable,
+		createOffersTable,
+		createOfferApprovalsTable,
+		createRejectionReasonsTable,
+		createMessageTemplatesTable,
+		createCandidateMessagesTable,
+		createApplicationTagsTable,
+		createThreadMessagesTable,
+		createThreadRecipientsTable,
+		createThreadAttachmentsTable,
+		createNotificationSettingsTable,
+		createNotificationPreferencesTable,
+		createNotificationDeliveriesTable,
+		createWebhookEndpointsTable,
+		createWebhookDeliveriesTable,
+		createBackgroundJobsTable,
+		createScheduledTasksTable,
+		createSavedJobsTable,
+		createJobAlertsTable,
+		createJobSkillsTable,
+		createSkillsTable,
+		createSkillAliasesTable,
+	}
+
+	for _, table := range tables {
+		if _, err := db.Exec(table); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Colunas adicionadas depois da criação das tabelas originais
+	columns := []struct{ table, column, definition string }{
+		{"users", "headline", "TEXT DEFAULT ''"},
+		{"users", "summary", "TEXT DEFAULT ''"},
+		{"users", "location", "TEXT DEFAULT ''"},
+		{"users", "phone", "TEXT DEFAULT ''"},
+		{"applications", "cover_letter", "TEXT DEFAULT ''"},
+		{"applications", "withdrawn_at", "DATETIME"},
+		{"applications", "withdrawal_reason", "TEXT DEFAULT ''"},
+		{"users", "role", "TEXT DEFAULT 'user'"},
+		{"jobs", "deleted_at", "DATETIME"},
+		{"applications", "job_closed_at", "DATETIME"},
+		{"applications", "rejection_reason_id", "INTEGER REFERENCES rejection_reasons (id)"},
+		{"applications", "rejection_note", "TEXT DEFAULT ''"},
+		{"applications", "rejected_at", "DATETIME"},
+		{"jobs", "closes_at", "DATETIME"},
+		{"jobs", "work_mode", "TEXT DEFAULT ''"},
+		{"jobs", "seniority", "TEXT DEFAULT ''"},
+		{"users", "desired_salary", "INTEGER DEFAULT 0"},
+		{"users", "seniority", "TEXT DEFAULT ''"},
+		{"users", "work_mode", "TEXT DEFAULT ''"},
+		{"users", "open_to_work", "BOOLEAN DEFAULT 0"},
+		{"job_skills", "level", "TEXT DEFAULT ''"},
+		{"notification_deliveries", "next_attempt_at", "DATETIME"},
+	}
+
+	for _, col := range columns {
+		if err := addColumnIfMissing(col.table, col.column, col.definition); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Uma candidatura por usuário em cada vaga. O índice equivale a
+	// UNIQUE(job_id, user_id) e também se aplica a bancos já existentes.
+	_, err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_job_user ON applications (job_id, user_id)")
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Erro ao criar restrição de candidatura única (existem candidaturas duplicadas?): %v", err)
+	}
+
+	// Motivos de reprovação padrão; os administradores podem incluir outros
+	for _, reason := range defaultRejectionReasons {
+		if _, err := db.Exec("INSERT OR IGNORE INTO rejection_reasons (code, label) VALUES (?, ?)", reason.Code, reason.Label); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	_, err = db.Exec(createJobsTable)
+	// Promover os administradores configurados em ADMIN_EMAILS
+	for email := range adminEmails {
+		if _, err := db.Exec("UPDATE users SET role = 'admin' WHERE email = ?", email); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Println("Tabelas criadas com sucesso!")
+}
+
+// addColumnIfMissing adiciona a coluna em bancos criados antes dela existir
+func addColumnIfMissing(table, column, definition string) error {
+	rows, err := db.Query("PRAGMA table_info(" + table + ")")
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
+}
+
+// dbtx é implementada por *sql.DB e *sql.Tx, permitindo que funções auxiliares
+// sejam usadas dentro ou fora de uma transação
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
 
-	_, err = db.Exec(createApplicationsTable)
+// withTx executa fn dentro de uma transação. Se fn retornar erro (ou entrar em
+// pânico) tudo é desfeito; caso contrário a transação é confirmada.
+func withTx(fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.Begin()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	log.Println("Tabelas criadas com sucesso!")
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			finishTxEvents(tx, false)
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+		// Os eventos de tempo real só saem se a transação foi confirmada
+		finishTxEvents(tx, err == nil)
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isUniqueViolation indica se o erro veio de uma restrição UNIQUE ou de chave primária
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
 }
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamEvent é um evento enviado em tempo real aos usuários em userIDs
+type StreamEvent struct {
+	ID      int64
+	Type    string
+	Data    interface{}
+	userIDs map[int]bool
+}
+
+type eventSubscriber struct {
+	userID int
+	ch     chan *StreamEvent
+}
+
+// EventBroker distribui os eventos entre as conexões abertas, dentro do
+// processo, e guarda os mais recentes para que clientes reconectados recebam
+// o que perderam a partir do Last-Event-ID
+type EventBroker struct {
+	mu          sync.Mutex
+	nextID      int64
+	buffer      []*StreamEvent
+	size        int
+	subscribers map[*eventSubscriber]bool
+}
+
+// Eventos que cabem na fila de cada conexão; quem ficar para trás é
+// desconectado e retoma pelo Last-Event-ID
+const subscriberQueueSize = 64
+
+var eventBroker = NewEventBroker(getEnvInt("EVENTS_REPLAY_BUFFER", 1000))
+
+// Intervalo dos comentários enviados para manter a conexão aberta
+var eventsHeartbeat = time.Duration(getEnvInt("EVENTS_HEARTBEAT_SECONDS", 15)) * time.Second
+
+func NewEventBroker(size int) *EventBroker {
+	return &EventBroker{
+		// Os IDs começam no horário de início, em milissegundos, para que um ID
+		// anterior a um reinício nunca seja confundido com um evento novo
+		nextID:      time.Now().UnixMilli(),
+		size:        size,
+		subscribers: map[*eventSubscriber]bool{},
+	}
+}
+
+func (b *EventBroker) Publish(eventType string, data interface{}, userIDs ...int) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	ev := &StreamEvent{Type: eventType, Data: data, userIDs: map[int]bool{}}
+	for _, id := range userIDs {
+		ev.userIDs[id] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ev.ID = b.nextID
+	b.nextID++
+	b.buffer = append(b.buffer, ev)
+	if len(b.buffer) > b.size {
+		b.buffer = b.buffer[len(b.buffer)-b.size:]
+	}
+
+	for sub := range b.subscribers {
+		if !ev.userIDs[sub.userID] {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe registra uma conexão e devolve os eventos do usuário posteriores a
+// lastID. complete é false quando parte deles já saiu do buffer, e o cliente
+// precisa recarregar o estado.
+func (b *EventBroker) Subscribe(userID int, lastID int64) (sub *eventSubscriber, replay []*StreamEvent, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &eventSubscriber{userID: userID, ch: make(chan *StreamEvent, subscriberQueueSize)}
+	b.subscribers[sub] = true
+
+	if lastID == 0 {
+		return sub, nil, true
+	}
+
+	oldest := b.nextID
+	if len(b.buffer) > 0 {
+		oldest = b.buffer[0].ID
+	}
+	complete = lastID+1 >= oldest && lastID < b.nextID
+
+	for _, ev := range b.buffer {
+		if ev.ID > lastID && ev.userIDs[userID] {
+			replay = append(replay, ev)
+		}
+	}
+	return sub, replay, complete
+}
+
+func (b *EventBroker) Unsubscribe(sub *eventSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[sub] {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// Eventos gerados dentro de uma transação ficam guardados até o commit; se a
+// transação for desfeita, são descartados
+var txEvents = struct {
+	sync.Mutex
+	pending map[*sql.Tx][]*StreamEvent
+}{pending: map[*sql.Tx][]*StreamEvent{}}
+
+// publishEvent publica o evento agora ou, se q for uma transação, depois do commit
+func publishEvent(q dbtx, eventType string, data interface{}, userIDs ...int) {
+	tx, ok := q.(*sql.Tx)
+	if !ok {
+		eventBroker.Publish(eventType, data, userIDs...)
+		return
+	}
+
+	ev := &StreamEvent{Type: eventType, Data: data, userIDs: map[int]bool{}}
+	for _, id := range userIDs {
+		ev.userIDs[id] = true
+	}
+
+	txEvents.Lock()
+	txEvents.pending[tx] = append(txEvents.pending[tx], ev)
+	txEvents.Unlock()
+}
+
+// finishTxEvents publica (committed) ou descarta os eventos da transação
+func finishTxEvents(tx *sql.Tx, committed bool) {
+	txEvents.Lock()
+	events := txEvents.pending[tx]
+	delete(txEvents.pending, tx)
+	txEvents.Unlock()
+
+	if !committed {
+		return
+	}
+	for _, ev := range events {
+		ids := make([]int, 0, len(ev.userIDs))
+		for id := range ev.userIDs {
+			ids = append(ids, id)
+		}
+		eventBroker.Publish(ev.Type, ev.Data, ids...)
+	}
+}
+
+func writeStreamEvent(c *gin.Context, ev *StreamEvent) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(ev.ID, 10),
+		Event: ev.Type,
+		Data:  ev.Data,
+	})
+	c.Writer.Flush()
+}
+
+// eventsHandler mantém aberta uma conexão Server-Sent Events com os eventos do
+// usuário logado. Ao reconectar, o cliente envia o Last-Event-ID (ou
+// ?last_event_id=) e recebe o que perdeu; se o buffer já não tiver esses
+// eventos, recebe um evento "reset" e deve recarregar os dados.
+func eventsHandler(c *gin.Context) {
+	lastID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	if lastID == 0 {
+		lastID, _ = strconv.ParseInt(c.Query("last_event_id"), 10, 64)
+	}
+
+	sub, replay, complete := eventBroker.Subscribe(c.GetInt("user_id"), lastID)
+	defer eventBroker.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !complete {
+		c.Render(-1, sse.Event{Event: "reset", Data: gin.H{"message": "Alguns eventos foram perdidos, recarregue os dados"}})
+	}
+	for _, ev := range replay {
+		writeStreamEvent(c, ev)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case ev, ok := <-sub.ch:
+			if !ok {
+				return false
+			}
+			writeStreamEvent(c, ev)
+			return true
+		case <-heartbeat.C:
+			w.Write([]byte(": heartbeat\n\n"))
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
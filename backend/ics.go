@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsEvent descreve um VEVENT. Os horários são emitidos em UTC, o que
+// dispensa componentes VTIMEZONE e é interpretado por qualquer calendário.
+type icsEvent struct {
+	UID         string
+	Sequence    int
+	Status      string // CONFIRMED, CANCELLED
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Organizer   InterviewParticipant
+	Attendees   []InterviewParticipant
+}
+
+// buildICS gera um iCalendar (RFC 5545) com um único evento. method é
+// REQUEST para convites e atualizações ou CANCEL para cancelamentos; os
+// clientes de calendário usam UID e SEQUENCE para substituir a versão anterior.
+func buildICS(method string, ev icsEvent) []byte {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Recruitment System//Entrevistas//PT",
+		"CALSCALE:GREGORIAN",
+		"METHOD:" + method,
+		"BEGIN:VEVENT",
+		"UID:" + ev.UID,
+		"SEQUENCE:" + fmt.Sprint(ev.Sequence),
+		"DTSTAMP:" + icsTime(ev.Stamp),
+		"DTSTART:" + icsTime(ev.Start),
+		"DTEND:" + icsTime(ev.End),
+		"SUMMARY:" + icsEscape(ev.Summary),
+		"STATUS:" + ev.Status,
+	}
+	if ev.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icsEscape(ev.Description))
+	}
+	if ev.Location != "" {
+		lines = append(lines, "LOCATION:"+icsEscape(ev.Location))
+	}
+	if ev.URL != "" {
+		lines = append(lines, "URL:"+ev.URL)
+	}
+	lines = append(lines, fmt.Sprintf("ORGANIZER;CN=%s:mailto:%s", icsParam(ev.Organizer.Name), ev.Organizer.Email))
+	for _, attendee := range ev.Attendees {
+		lines = append(lines, fmt.Sprintf("ATTENDEE;CN=%s;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:%s",
+			icsParam(attendee.Name), attendee.Email))
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(icsFold(line))
+		sb.WriteString("\r\n")
+	}
+	return []byte(sb.String())
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsEscape escapa valores TEXT (barra invertida, ponto e vírgula, vírgula e quebras de linha)
+func icsEscape(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, ";", "\\;")
+	value = strings.ReplaceAll(value, ",", "\\,")
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.ReplaceAll(value, "\n", "\\n")
+}
+
+// icsParam coloca o valor de um parâmetro entre aspas; aspas não são permitidas
+func icsParam(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r == '"' || r < 32 {
+			return -1
+		}
+		return r
+	}, value)
+	return `"` + value + `"`
+}
+
+// icsFold quebra linhas com mais de 75 octetos, sem partir caracteres UTF-8.
+// As linhas de continuação começam com um espaço.
+func icsFold(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	var sb strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+		// O espaço inicial conta no limite das linhas seguintes
+		limit = 74
+	}
+	sb.WriteString(line)
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestICSEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Entrevista", "Entrevista"},
+		{"a;b,c", `a\;b\,c`},
+		{`C:\pasta`, `C:\\pasta`},
+		{"linha 1\r\nlinha 2\nlinha 3", `linha 1\nlinha 2\nlinha 3`},
+	}
+	for _, tt := range tests {
+		if got := icsEscape(tt.in); got != tt.want {
+			t.Errorf("icsEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestICSParam(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Ana Lima", `"Ana Lima"`},
+		{`Ana "Aninha" Lima`, `"Ana Aninha Lima"`},
+		{"Ana\r\nLima", `"AnaLima"`},
+		{"Silva; Souza", `"Silva; Souza"`},
+	}
+	for _, tt := range tests {
+		if got := icsParam(tt.in); got != tt.want {
+			t.Errorf("icsParam(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestICSFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"curta", "SUMMARY:Entrevista"},
+		{"exatamente 75", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"longa ASCII", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		{"longa com acentos", "DESCRIPTION:" + strings.Repeat("ação ", 60)},
+		{"emojis", "SUMMARY:" + strings.Repeat("🎉", 50)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := icsFold(tt.line)
+			parts := strings.Split(folded, "\r\n")
+			for i, part := range parts {
+				if len(part) > 75 {
+					t.Errorf("linha %d com %d octetos", i, len(part))
+				}
+				if !utf8.ValidString(part) {
+					t.Errorf("linha %d parte um caractere UTF-8: %q", i, part)
+				}
+				if i > 0 && !strings.HasPrefix(part, " ") {
+					t.Errorf("linha de continuação %d sem espaço inicial", i)
+				}
+			}
+			if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("desdobrar não devolve a linha original")
+			}
+			if len(tt.line) <= 75 && len(parts) != 1 {
+				t.Errorf("linha curta foi quebrada: %q", folded)
+			}
+		})
+	}
+}
+
+func TestBuildICS(t *testing.T) {
+	start := time.Date(2026, 10, 20, 14, 0, 0, 0, time.FixedZone("BRT", -3*3600))
+	base := icsEvent{
+		UID:       "interview-7@recruitment",
+		Sequence:  2,
+		Status:    "CONFIRMED",
+		Start:     start,
+		End:       start.Add(time.Hour),
+		Stamp:     time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Summary:   "Entrevista: Dev Go, Backend",
+		Organizer: InterviewParticipant{Name: "Carla", Email: "carla@x.com"},
+		Attendees: []InterviewParticipant{
+			{Name: "Ana Lima", Email: "ana@x.com"},
+			{Name: "Bruno", Email: "bruno@x.com"},
+		},
+	}
+
+	withDetails := base
+	withDetails.Description = "Traga seu portfólio;\nduração de 1h"
+	withDetails.Location = "Sala 3, 2º andar"
+	withDetails.URL = "https://meet.example.com/abc"
+
+	cancelled := base
+	cancelled.Status = "CANCELLED"
+	cancelled.Sequence = 3
+
+	tests := []struct {
+		name    string
+		method  string
+		event   icsEvent
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "convite",
+			method: "REQUEST",
+			event:  base,
+			want: []string{
+				"BEGIN:VCALENDAR", "METHOD:REQUEST", "UID:interview-7@recruitment", "SEQUENCE:2",
+				"DTSTAMP:20261019T120000Z", "DTSTART:20261020T170000Z", "DTEND:20261020T180000Z",
+				`SUMMARY:Entrevista: Dev Go\, Backend`, "STATUS:CONFIRMED",
+				`ORGANIZER;CN="Carla":mailto:carla@x.com`,
+				`ATTENDEE;CN="Ana Lima";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:ana@x.com`,
+				`ATTENDEE;CN="Bruno";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:bruno@x.com`,
+				"END:VEVENT", "END:VCALENDAR",
+			},
+			notWant: []string{"DESCRIPTION:", "LOCATION:", "URL:"},
+		},
+		{
+			name:   "com detalhes",
+			method: "REQUEST",
+			event:  withDetails,
+			want: []string{
+				`DESCRIPTION:Traga seu portfólio\;\nduração de 1h`,
+				`LOCATION:Sala 3\, 2º andar`,
+				"URL:https://meet.example.com/abc",
+			},
+		},
+		{
+			name:   "cancelamento",
+			method: "CANCEL",
+			event:  cancelled,
+			want:   []string{"METHOD:CANCEL", "STATUS:CANCELLED", "SEQUENCE:3", "UID:interview-7@recruitment"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ics := string(buildICS(tt.method, tt.event))
+			if !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+				t.Errorf("iCalendar não termina com END:VCALENDAR e CRLF")
+			}
+			if strings.Contains(strings.ReplaceAll(ics, "\r\n", ""), "\n") {
+				t.Errorf("quebra de linha sem CR")
+			}
+
+			lines := strings.Split(strings.ReplaceAll(ics, "\r\n ", ""), "\r\n")
+			has := map[string]bool{}
+			for _, line := range lines {
+				has[line] = true
+			}
+			for _, w := range tt.want {
+				if !has[w] {
+					t.Errorf("linha ausente: %q", w)
+				}
+			}
+			for _, prefix := range tt.notWant {
+				if strings.Contains(ics, "\r\n"+prefix) {
+					t.Errorf("linha inesperada: %q", prefix)
+				}
+			}
+		})
+	}
+}
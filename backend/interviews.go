@@ -0,0 +1,688 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // fusos horários disponíveis mesmo sem o banco do sistema
+
+	"github.com/gin-gonic/gin"
+)
+
+var interviewTypeLabels = map[string]string{
+	"phone":     "Entrevista por telefone",
+	"video":     "Entrevista por vídeo",
+	"onsite":    "Entrevista presencial",
+	"technical": "Entrevista técnica",
+}
+
+var errInterviewConflict = errors.New("Um ou mais entrevistadores já têm entrevista neste horário")
+
+// interviewContext reúne os dados usados nos convites de uma candidatura
+type interviewContext struct {
+	ApplicationID int
+	JobID         int
+	JobTitle      string
+	Company       string
+	Status        string
+	Candidate     InterviewParticipant
+	Organizer     InterviewParticipant
+}
+
+func loadInterviewContext(appID int) (*interviewContext, error) {
+	ctx := &interviewContext{ApplicationID: appID}
+	err := db.QueryRow(`
+		SELECT a.status, j.id, j.title, j.company, c.id, c.name, c.email, o.id, o.name, o.email
+		FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		JOIN users c ON a.user_id = c.id
+		JOIN users o ON j.user_id = o.id
+		WHERE a.id = ?`, appID).Scan(&ctx.Status, &ctx.JobID, &ctx.JobTitle, &ctx.Company,
+		&ctx.Candidate.UserID, &ctx.Candidate.Name, &ctx.Candidate.Email,
+		&ctx.Organizer.UserID, &ctx.Organizer.Name, &ctx.Organizer.Email)
+	if err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// parseInterviewTime aceita RFC 3339 ou horário local no fuso informado
+func parseInterviewTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Horário inválido: %s", value)
+}
+
+// validateInterviewRequest confere horários, fuso e entrevistadores. Os
+// entrevistadores precisam fazer parte da equipe de contratação da vaga.
+func validateInterviewRequest(req *InterviewRequest, jobID int) (start, end time.Time, interviewers []InterviewParticipant, err error) {
+	loc, err := time.LoadLocation(req.TimeZone)
+	if err != nil {
+		return start, end, nil, fmt.Errorf("Fuso horário inválido: %s", req.TimeZone)
+	}
+
+	if start, err = parseInterviewTime(req.StartsAt, loc); err != nil {
+		return start, end, nil, err
+	}
+	if end, err = parseInterviewTime(req.EndsAt, loc); err != nil {
+		return start, end, nil, err
+	}
+	start, end = start.UTC().Truncate(time.Minute), end.UTC().Truncate(time.Minute)
+
+	if !end.After(start) {
+		return start, end, nil, errors.New("O término deve ser depois do início")
+	}
+	if end.Sub(start) > 8*time.Hour {
+		return start, end, nil, errors.New("A entrevista não pode durar mais de 8 horas")
+	}
+	if req.Type == "video" && req.VideoLink == "" {
+		return start, end, nil, errors.New("Informe o link da chamada para entrevistas por vídeo")
+	}
+	if req.Type == "onsite" && strings.TrimSpace(req.Location) == "" {
+		return start, end, nil, errors.New("Informe o local da entrevista presencial")
+	}
+
+	interviewers, err = loadInterviewers(jobID, req.InterviewerIDs)
+	return start, end, interviewers, err
+}
+
+// loadInterviewers remove ids repetidos e confirma que cada entrevistador faz
+// parte da equipe de contratação da vaga
+func loadInterviewers(jobID int, ids []int) ([]InterviewParticipant, error) {
+	interviewers := []InterviewParticipant{}
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if !isHiringSide(jobID, id) {
+			return nil, fmt.Errorf("O usuário %d não faz parte da equipe de contratação da vaga", id)
+		}
+		var p InterviewParticipant
+		if err := db.QueryRow("SELECT id, name, email FROM users WHERE id = ?", id).Scan(&p.UserID, &p.Name, &p.Email); err != nil {
+			return nil, fmt.Errorf("Usuário %d não encontrado", id)
+		}
+		interviewers = append(interviewers, p)
+	}
+	return interviewers, nil
+}
+
+// insertInterview grava a entrevista e notifica o candidato. A verificação de
+// conflitos roda na mesma transação, para que dois agendamentos simultâneos
+// não ocupem o mesmo horário.
+func insertInterview(tx *sql.Tx, iv *Interview, ctx *interviewContext) ([]InterviewConflict, error) {
+	conflicts, err := findInterviewConflicts(tx, iv.Interviewers, iv.StartsAt, iv.EndsAt, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return conflicts, errInterviewConflict
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO interviews (application_id, type, starts_at, ends_at, time_zone, location, video_link,
+		                        status, sequence, uid, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'scheduled', 0, ?, ?, ?, ?)`,
+		iv.ApplicationID, iv.Type, iv.StartsAt, iv.EndsAt, iv.TimeZone, iv.Location, iv.VideoLink,
+		iv.UID, iv.CreatedBy, iv.CreatedAt, iv.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := result.LastInsertId()
+	iv.ID = int(id)
+
+	for _, p := range iv.Interviewers {
+		if _, err := tx.Exec("INSERT INTO interview_interviewers (interview_id, user_id) VALUES (?, ?)", iv.ID, p.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	message := fmt.Sprintf("Entrevista agendada para a vaga %s", ctx.JobTitle)
+	return nil, createNotification(tx, ctx.Candidate.UserID, "interview_scheduled", message, iv.ApplicationID)
+}
+
+// newInterviewUID gera o UID do evento, que se mantém nas atualizações do convite
+func newInterviewUID() (string, error) {
+	token, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	return token + "@recruitment-system", nil
+}
+
+// findInterviewConflicts procura entrevistas agendadas dos entrevistadores que
+// se sobrepõem ao intervalo [start, end), ignorando a própria entrevista
+func findInterviewConflicts(q dbtx, interviewers []InterviewParticipant, start, end time.Time, excludeID int) ([]InterviewConflict, error) {
+	conflicts := []InterviewConflict{}
+	for _, p := range interviewers {
+		rows, err := q.Query(`
+			SELECT i.id, i.starts_at, i.ends_at
+			FROM interviews i
+			JOIN interview_interviewers ii ON ii.interview_id = i.id
+			WHERE ii.user_id = ? AND i.status = 'scheduled' AND i.id != ?
+			AND i.starts_at < ? AND i.ends_at > ?`, p.UserID, excludeID, end, start)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			conflict := InterviewConflict{InterviewerID: p.UserID, Interviewer: p.Name}
+			if err := rows.Scan(&conflict.InterviewID, &conflict.StartsAt, &conflict.EndsAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			conflicts = append(conflicts, conflict)
+		}
+		rows.Close()
+	}
+	return conflicts, nil
+}
+
+// listInterviews carrega entrevistas com seus entrevistadores
+func listInterviews(where string, args ...interface{}) ([]Interview, error) {
+	rows, err := db.Query(`
+		SELECT i.id, i.application_id, i.type, i.starts_at, i.ends_at, i.time_zone, i.location, i.video_link,
+		       i.status, i.sequence, i.uid, i.created_by, i.created_at, i.updated_at
+		FROM interviews i
+		WHERE `+where+`
+		ORDER BY i.starts_at, i.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	interviews := []Interview{}
+	for rows.Next() {
+		var iv Interview
+		if err := rows.Scan(&iv.ID, &iv.ApplicationID, &iv.Type, &iv.StartsAt, &iv.EndsAt, &iv.TimeZone,
+			&iv.Location, &iv.VideoLink, &iv.Status, &iv.Sequence, &iv.UID, &iv.CreatedBy,
+			&iv.CreatedAt, &iv.UpdatedAt); err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, iv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range interviews {
+		interviews[i].Interviewers, err = listInterviewers(interviews[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return interviews, nil
+}
+
+func listInterviewers(interviewID int) ([]InterviewParticipant, error) {
+	rows, err := db.Query(`
+		SELECT u.id, u.name, u.email
+		FROM interview_interviewers ii
+		JOIN users u ON ii.user_id = u.id
+		WHERE ii.interview_id = ?
+		ORDER BY u.name`, interviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	interviewers := []InterviewParticipant{}
+	for rows.Next() {
+		var p InterviewParticipant
+		if err := rows.Scan(&p.UserID, &p.Name, &p.Email); err != nil {
+			return nil, err
+		}
+		interviewers = append(interviewers, p)
+	}
+	return interviewers, rows.Err()
+}
+
+// interviewICS gera o convite da entrevista com o método adequado ao status
+func interviewICS(iv *Interview, ctx *interviewContext) (method string, data []byte) {
+	method, status := "REQUEST", "CONFIRMED"
+	if iv.Status == "cancelled" {
+		method, status = "CANCEL", "CANCELLED"
+	}
+
+	loc, err := time.LoadLocation(iv.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	description := fmt.Sprintf("%s para a vaga %s (%s).\nHorário: %s às %s (%s)",
+		interviewTypeLabels[iv.Type], ctx.JobTitle, ctx.Company,
+		iv.StartsAt.In(loc).Format("02/01/2006 15:04"), iv.EndsAt.In(loc).Format("15:04"), iv.TimeZone)
+	if iv.VideoLink != "" {
+		description += "\nLink da chamada: " + iv.VideoLink
+	}
+
+	location := iv.Location
+	if location == "" {
+		location = iv.VideoLink
+	}
+
+	attendees := append([]InterviewParticipant{ctx.Candidate}, iv.Interviewers...)
+
+	return method, buildICS(method, icsEvent{
+		UID:         iv.UID,
+		Sequence:    iv.Sequence,
+		Status:      status,
+		Start:       iv.StartsAt,
+		End:         iv.EndsAt,
+		Stamp:       time.Now(),
+		Summary:     fmt.Sprintf("%s: %s - %s", interviewTypeLabels[iv.Type], ctx.Candidate.Name, ctx.JobTitle),
+		Description: description,
+		Location:    location,
+		URL:         iv.VideoLink,
+		Organizer:   ctx.Organizer,
+		Attendees:   attendees,
+	})
+}
+
+// sendInterviewInvites enfileira o convite (ou cancelamento) ao candidato e aos
+// entrevistadores, e a quem foi removido da entrevista um cancelamento
+func sendInterviewInvites(q dbtx, iv *Interview, ctx *interviewContext, removed []InterviewParticipant) error {
+	method, data := interviewICS(iv, ctx)
+
+	subject := "Convite: "
+	switch {
+	case iv.Status == "cancelled":
+		subject = "Cancelada: "
+	case iv.Sequence > 0:
+		subject = "Atualizada: "
+	}
+	subject += fmt.Sprintf("%s - %s", interviewTypeLabels[iv.Type], ctx.JobTitle)
+
+	recipients := append([]InterviewParticipant{ctx.Candidate}, iv.Interviewers...)
+	for _, p := range recipients {
+		err := enqueueEmail(q, Email{
+			To:      []string{p.Email},
+			Subject: subject,
+			Body:    fmt.Sprintf("Olá, %s.\n\nO convite da entrevista está anexado.", p.Name),
+			Attachments: []EmailAttachment{{
+				Filename:    "convite.ics",
+				ContentType: fmt.Sprintf("text/calendar; method=%s; charset=UTF-8", method),
+				Data:        data,
+			}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	// Quem saiu da entrevista recebe o cancelamento apenas do seu lado
+	cancelled := *iv
+	cancelled.Status = "cancelled"
+	_, cancelData := interviewICS(&cancelled, ctx)
+	for _, p := range removed {
+		err := enqueueEmail(q, Email{
+			To:      []string{p.Email},
+			Subject: fmt.Sprintf("Cancelada: %s - %s", interviewTypeLabels[iv.Type], ctx.JobTitle),
+			Body:    fmt.Sprintf("Olá, %s.\n\nVocê não participa mais desta entrevista.", p.Name),
+			Attachments: []EmailAttachment{{
+				Filename:    "convite.ics",
+				ContentType: "text/calendar; method=CANCEL; charset=UTF-8",
+				Data:        cancelData,
+			}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadInterviewForHiring carrega a entrevista e confirma que o usuário faz
+// parte da equipe de contratação da vaga
+func loadInterviewForHiring(c *gin.Context) (*Interview, *interviewContext, bool) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return nil, nil, false
+	}
+
+	interviews, err := listInterviews("i.id = ?", interviewID)
+	if err != nil || len(interviews) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entrevista não encontrada"})
+		return nil, nil, false
+	}
+	iv := &interviews[0]
+
+	ctx, err := loadInterviewContext(iv.ApplicationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return nil, nil, false
+	}
+
+	if !isHiringSide(ctx.JobID, c.GetInt("user_id")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas a equipe de contratação pode alterar entrevistas"})
+		return nil, nil, false
+	}
+	return iv, ctx, true
+}
+
+// getApplicationInterviewsHandler lista as entrevistas para o candidato e a equipe
+func getApplicationInterviewsHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	ctx, err := loadInterviewContext(appID)
+	if err != nil || (ctx.Candidate.UserID != userID && !isHiringSide(ctx.JobID, userID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	interviews, err := listInterviews("i.application_id = ?", appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar entrevistas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"interviews": interviews})
+}
+
+// getMyInterviewsHandler lista as entrevistas do usuário, como entrevistador ou
+// candidato. Por padrão mostra as que ainda não terminaram.
+func getMyInterviewsHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	from := time.Now().UTC()
+	if value := c.Query("from"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parâmetro 'from' deve estar no formato RFC 3339"})
+			return
+		}
+		from = t.UTC()
+	}
+
+	interviews, err := listInterviews(`i.ends_at > ? AND (
+		EXISTS (SELECT 1 FROM interview_interviewers ii WHERE ii.interview_id = i.id AND ii.user_id = ?)
+		OR EXISTS (SELECT 1 FROM applications a WHERE a.id = i.application_id AND a.user_id = ?))`,
+		from, userID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar entrevistas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"interviews": interviews})
+}
+
+func createInterviewHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req InterviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, appID); !ok {
+		return
+	}
+
+	ctx, err := loadInterviewContext(appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	if ctx.Status == "withdrawn" || ctx.Status == "rejected" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Não é possível agendar entrevistas para esta candidatura"})
+		return
+	}
+
+	start, end, interviewers, err := validateInterviewRequest(&req, ctx.JobID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uid, err := newInterviewUID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao agendar entrevista"})
+		return
+	}
+
+	now := time.Now()
+	iv := &Interview{
+		ApplicationID: appID,
+		Type:          req.Type,
+		StartsAt:      start,
+		EndsAt:        end,
+		TimeZone:      req.TimeZone,
+		Location:      req.Location,
+		VideoLink:     req.VideoLink,
+		Status:        "scheduled",
+		UID:           uid,
+		Interviewers:  interviewers,
+		CreatedBy:     c.GetInt("user_id"),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	var conflicts []InterviewConflict
+	err = withTx(func(tx *sql.Tx) error {
+		conflicts, err = insertInterview(tx, iv, ctx)
+		if err != nil {
+			return err
+		}
+		return sendInterviewInvites(tx, iv, ctx, nil)
+	})
+	if errors.Is(err, errInterviewConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflicts})
+		return
+	}
+	if err != nil {
+		log.Printf("Erro ao agendar entrevista: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao agendar entrevista"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Entrevista agendada com sucesso",
+		"interview": iv,
+	})
+}
+
+// updateInterviewHandler remarca a entrevista. O SEQUENCE é incrementado para
+// que os calendários substituam o convite anterior.
+func updateInterviewHandler(c *gin.Context) {
+	var req InterviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	iv, ctx, ok := loadInterviewForHiring(c)
+	if !ok {
+		return
+	}
+
+	if iv.Status == "cancelled" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Entrevistas canceladas não podem ser alteradas"})
+		return
+	}
+
+	start, end, interviewers, err := validateInterviewRequest(&req, ctx.JobID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current := map[int]bool{}
+	for _, p := range interviewers {
+		current[p.UserID] = true
+	}
+	var removed []InterviewParticipant
+	for _, p := range iv.Interviewers {
+		if !current[p.UserID] {
+			removed = append(removed, p)
+		}
+	}
+
+	var conflicts []InterviewConflict
+	err = withTx(func(tx *sql.Tx) error {
+		conflicts, err = findInterviewConflicts(tx, interviewers, start, end, iv.ID)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return errInterviewConflict
+		}
+
+		result, err := tx.Exec(`
+			UPDATE interviews SET type = ?, starts_at = ?, ends_at = ?, time_zone = ?, location = ?, video_link = ?,
+			                      sequence = sequence + 1, updated_at = ?
+			WHERE id = ? AND status = 'scheduled'`,
+			req.Type, start, end, req.TimeZone, req.Location, req.VideoLink, time.Now(), iv.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+
+		if _, err := tx.Exec("DELETE FROM interview_interviewers WHERE interview_id = ?", iv.ID); err != nil {
+			return err
+		}
+		for _, p := range interviewers {
+			if _, err := tx.Exec("INSERT INTO interview_interviewers (interview_id, user_id) VALUES (?, ?)", iv.ID, p.UserID); err != nil {
+				return err
+			}
+		}
+
+		message := fmt.Sprintf("Sua entrevista para a vaga %s foi remarcada", ctx.JobTitle)
+		if err := createNotification(tx, ctx.Candidate.UserID, "interview_updated", message, ctx.ApplicationID); err != nil {
+			return err
+		}
+
+		updated := *iv
+		updated.Type, updated.StartsAt, updated.EndsAt, updated.TimeZone = req.Type, start, end, req.TimeZone
+		updated.Location, updated.VideoLink, updated.Interviewers = req.Location, req.VideoLink, interviewers
+		updated.Sequence++
+		return sendInterviewInvites(tx, &updated, ctx, removed)
+	})
+	if errors.Is(err, errInterviewConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflicts})
+		return
+	}
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "A entrevista foi cancelada por outra requisição"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar entrevista"})
+		return
+	}
+
+	iv.Type, iv.StartsAt, iv.EndsAt, iv.TimeZone = req.Type, start, end, req.TimeZone
+	iv.Location, iv.VideoLink, iv.Interviewers = req.Location, req.VideoLink, interviewers
+	iv.Sequence++
+	iv.UpdatedAt = time.Now()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Entrevista atualizada com sucesso",
+		"interview": iv,
+	})
+}
+
+func cancelInterviewHandler(c *gin.Context) {
+	iv, ctx, ok := loadInterviewForHiring(c)
+	if !ok {
+		return
+	}
+
+	if iv.Status == "cancelled" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta entrevista já foi cancelada"})
+		return
+	}
+
+	err := withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE interviews SET status = 'cancelled', sequence = sequence + 1, updated_at = ?
+			WHERE id = ? AND status = 'scheduled'`, time.Now(), iv.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+
+		message := fmt.Sprintf("Sua entrevista para a vaga %s foi cancelada", ctx.JobTitle)
+		if err := createNotification(tx, ctx.Candidate.UserID, "interview_cancelled", message, ctx.ApplicationID); err != nil {
+			return err
+		}
+
+		cancelled := *iv
+		cancelled.Status = "cancelled"
+		cancelled.Sequence++
+		return sendInterviewInvites(tx, &cancelled, ctx, nil)
+	})
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta entrevista já foi cancelada"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cancelar entrevista"})
+		return
+	}
+
+	iv.Status = "cancelled"
+	iv.Sequence++
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entrevista cancelada com sucesso"})
+}
+
+// downloadInterviewICSHandler devolve o convite atual da entrevista para o
+// candidato e a equipe de contratação
+func downloadInterviewICSHandler(c *gin.Context) {
+	interviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	interviews, err := listInterviews("i.id = ?", interviewID)
+	if err != nil || len(interviews) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entrevista não encontrada"})
+		return
+	}
+	iv := &interviews[0]
+
+	userID := c.GetInt("user_id")
+	ctx, err := loadInterviewContext(iv.ApplicationID)
+	if err != nil || (ctx.Candidate.UserID != userID && !isHiringSide(ctx.JobID, userID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entrevista não encontrada"})
+		return
+	}
+
+	method, data := interviewICS(iv, ctx)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="entrevista-%d.ics"`, iv.ID))
+	c.Data(http.StatusOK, fmt.Sprintf("text/calendar; method=%s; charset=UTF-8", method), data)
+}
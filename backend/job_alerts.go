@@ -0,0 +1,409 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Endereço do frontend onde o usuário confirma o cancelamento do alerta
+var jobAlertUnsubscribeURL = strings.TrimRight(getEnv("JOB_ALERTS_UNSUBSCRIBE_URL", "http://localhost:5173/alertas/cancelar"), "/")
+
+// Intervalo entre os resumos de cada frequência
+var jobAlertPeriods = map[string]time.Duration{
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+const (
+	maxJobAlertsPerUser = 20
+	// Vagas listadas em cada email; as demais aparecem apenas na contagem
+	maxJobAlertEmailJobs = 20
+)
+
+// signJobAlertToken gera o token do link de cancelamento. A assinatura cobre o
+// id do alerta, então cada alerta tem o seu link e nenhum outro pode ser forjado.
+func signJobAlertToken(alertID int) string {
+	payload := strconv.Itoa(alertID)
+	return payload + "." + jobAlertSignature(payload)
+}
+
+func jobAlertSignature(payload string) string {
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte("job-alert:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseJobAlertToken confere a assinatura e devolve o id do alerta
+func parseJobAlertToken(token string) (int, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(jobAlertSignature(parts[0]))) {
+		return 0, false
+	}
+	alertID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	return alertID, true
+}
+
+var salaryNumberRegex = regexp.MustCompile(`(?i)(\d[\d.,]*)\s*(k|mil)?`)
+
+// parseSalary devolve o maior valor encontrado no texto livre do salário,
+// aceitando "R$ 5.000,00", "5000 - 7000", "8k" e "7,5 mil". ok é false se não
+// houver números.
+func parseSalary(text string) (max int, ok bool) {
+	for _, m := range salaryNumberRegex.FindAllStringSubmatch(text, -1) {
+		number, decimals := m[1], ""
+		// Separador seguido de até dois dígitos no final: centavos ou, com k/mil,
+		// a fração do milhar
+		if i := strings.LastIndexAny(number, ".,"); i >= 0 && len(number)-i-1 <= 2 {
+			number, decimals = number[:i], number[i+1:]
+		}
+		number = strings.NewReplacer(".", "", ",", "").Replace(number)
+
+		value, err := strconv.Atoi(number)
+		if err != nil {
+			continue
+		}
+		if m[2] != "" {
+			value *= 1000
+			if decimals != "" {
+				fraction, _ := strconv.ParseFloat("0."+decimals, 64)
+				value += int(math.Round(fraction * 1000))
+			}
+		}
+		if value > max {
+			max = value
+		}
+		ok = true
+	}
+	return max, ok
+}
+
+// matchesJobAlert confere se a vaga atende aos critérios do alerta. Todas as
+// palavras-chave precisam aparecer no título, na descrição ou na empresa.
+func matchesJobAlert(alert *JobAlert, job *Job) bool {
+	if alert.Type != "" && !strings.EqualFold(alert.Type, job.Type) {
+		return false
+	}
+	if alert.Location != "" && !strings.Contains(strings.ToLower(job.Location), strings.ToLower(alert.Location)) {
+		return false
+	}
+	if alert.MinSalary > 0 {
+		salary, ok := parseSalary(job.Salary)
+		if !ok || salary < alert.MinSalary {
+			return false
+		}
+	}
+
+	text := strings.ToLower(job.Title + " " + job.Description + " " + job.Company)
+	for _, keyword := range strings.FieldsFunc(strings.ToLower(alert.Keywords), func(r rune) bool {
+		return r == ' ' || r == ','
+	}) {
+		if !strings.Contains(text, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+const jobAlertColumns = `id, user_id, name, keywords, location, type, min_salary, frequency, active,
+	last_run_at, last_sent_at, created_at, updated_at`
+
+func scanJobAlert(row interface{ Scan(...interface{}) error }) (*JobAlert, error) {
+	var a JobAlert
+	var lastSentAt sql.NullTime
+	err := row.Scan(&a.ID, &a.UserID, &a.Name, &a.Keywords, &a.Location, &a.Type, &a.MinSalary, &a.Frequency,
+		&a.Active, &a.LastRunAt, &lastSentAt, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastSentAt.Valid {
+		a.LastSentAt = &lastSentAt.Time
+	}
+	return &a, nil
+}
+
+// requireJobAlert carrega um alerta do usuário logado
+func requireJobAlert(c *gin.Context) (*JobAlert, bool) {
+	alertID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return nil, false
+	}
+
+	alert, err := scanJobAlert(db.QueryRow("SELECT "+jobAlertColumns+" FROM job_alerts WHERE id = ? AND user_id = ?",
+		alertID, c.GetInt("user_id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alerta não encontrado"})
+		return nil, false
+	}
+	return alert, true
+}
+
+func getJobAlertsHandler(c *gin.Context) {
+	rows, err := db.Query("SELECT "+jobAlertColumns+" FROM job_alerts WHERE user_id = ? ORDER BY id", c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar alertas"})
+		return
+	}
+	defer rows.Close()
+
+	alerts := []JobAlert{}
+	for rows.Next() {
+		alert, err := scanJobAlert(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar alertas"})
+			return
+		}
+		alerts = append(alerts, *alert)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+// createJobAlertHandler salva a busca. Só vagas criadas a partir de agora
+// entram nos resumos.
+func createJobAlertHandler(c *gin.Context) {
+	var req JobAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM job_alerts WHERE user_id = ?", userID).Scan(&count); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar alerta"})
+		return
+	}
+	if count >= maxJobAlertsPerUser {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Limite de %d alertas atingido", maxJobAlertsPerUser)})
+		return
+	}
+
+	now := time.Now()
+	active := req.Active == nil || *req.Active
+	result, err := db.Exec(`
+		INSERT INTO job_alerts (user_id, name, keywords, location, type, min_salary, frequency, active, last_run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, strings.TrimSpace(req.Name), strings.TrimSpace(req.Keywords), strings.TrimSpace(req.Location),
+		strings.TrimSpace(req.Type), req.MinSalary, req.Frequency, active, now, now, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar alerta"})
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	alert, _ := scanJobAlert(db.QueryRow("SELECT "+jobAlertColumns+" FROM job_alerts WHERE id = ?", id))
+	c.JSON(http.StatusCreated, gin.H{"message": "Alerta criado com sucesso", "alert": alert})
+}
+
+// updateJobAlertHandler altera os critérios. Reativar um alerta não envia as
+// vagas publicadas enquanto ele estava desativado.
+func updateJobAlertHandler(c *gin.Context) {
+	alert, ok := requireJobAlert(c)
+	if !ok {
+		return
+	}
+
+	var req JobAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	active := alert.Active
+	if req.Active != nil {
+		active = *req.Active
+	}
+	lastRunAt := alert.LastRunAt
+	now := time.Now()
+	if active && !alert.Active {
+		lastRunAt = now
+	}
+
+	_, err := db.Exec(`
+		UPDATE job_alerts SET name = ?, keywords = ?, location = ?, type = ?, min_salary = ?, frequency = ?, active = ?,
+		                      last_run_at = ?, updated_at = ?
+		WHERE id = ?`,
+		strings.TrimSpace(req.Name), strings.TrimSpace(req.Keywords), strings.TrimSpace(req.Location),
+		strings.TrimSpace(req.Type), req.MinSalary, req.Frequency, active, lastRunAt, now, alert.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar alerta"})
+		return
+	}
+
+	updated, _ := scanJobAlert(db.QueryRow("SELECT "+jobAlertColumns+" FROM job_alerts WHERE id = ?", alert.ID))
+	c.JSON(http.StatusOK, gin.H{"message": "Alerta atualizado com sucesso", "alert": updated})
+}
+
+func deleteJobAlertHandler(c *gin.Context) {
+	alert, ok := requireJobAlert(c)
+	if !ok {
+		return
+	}
+
+	if _, err := db.Exec("DELETE FROM job_alerts WHERE id = ?", alert.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir alerta"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alerta excluído com sucesso"})
+}
+
+// getJobAlertUnsubscribeHandler mostra qual alerta o link cancela, sem
+// alterá-lo, para que a pré-visualização de links nos clientes de email não
+// cancele o alerta
+func getJobAlertUnsubscribeHandler(c *gin.Context) {
+	alertID, ok := parseJobAlertToken(c.Param("token"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link inválido"})
+		return
+	}
+
+	var name string
+	var active bool
+	err := db.QueryRow("SELECT name, active FROM job_alerts WHERE id = ?", alertID).Scan(&name, &active)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alerta não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name": name, "active": active})
+}
+
+// unsubscribeJobAlertHandler desativa o alerta do link, sem exigir login
+func unsubscribeJobAlertHandler(c *gin.Context) {
+	alertID, ok := parseJobAlertToken(c.Param("token"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link inválido"})
+		return
+	}
+
+	result, err := db.Exec("UPDATE job_alerts SET active = 0, updated_at = ? WHERE id = ?", time.Now(), alertID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cancelar alerta"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alerta não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Você não receberá mais este alerta"})
+}
+
+// runJobAlerts executa os alertas cujo período venceu contra as vagas criadas
+// desde a última execução e enfileira um email com as que atendem a cada um
+func runJobAlerts(payload []byte) error {
+	rows, err := db.Query("SELECT " + jobAlertColumns + " FROM job_alerts WHERE active = 1 ORDER BY id")
+	if err != nil {
+		return err
+	}
+	var alerts []*JobAlert
+	for rows.Next() {
+		alert, err := scanJobAlert(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		alerts = append(alerts, alert)
+	}
+	rows.Close()
+
+	now := time.Now()
+	for _, alert := range alerts {
+		if alert.LastRunAt.Add(jobAlertPeriods[alert.Frequency]).After(now) {
+			continue
+		}
+		if err := runJobAlert(alert, now); err != nil {
+			log.Printf("Erro ao executar alerta %d: %v", alert.ID, err)
+		}
+	}
+	return nil
+}
+
+func runJobAlert(alert *JobAlert, now time.Time) error {
+	rows, err := db.Query(`
+		SELECT id, title, description, company, location, salary, type
+		FROM jobs
+		WHERE created_at > ? AND created_at <= ? AND deleted_at IS NULL AND user_id != ?
+		  AND (closes_at IS NULL OR closes_at > ?)
+		ORDER BY created_at DESC`, alert.LastRunAt, now, alert.UserID, now.UTC())
+	if err != nil {
+		return err
+	}
+	var matches []Job
+	for rows.Next() {
+		var job Job
+		if err := rows.Scan(&job.ID, &job.Title, &job.Description, &job.Company, &job.Location, &job.Salary, &job.Type); err != nil {
+			rows.Close()
+			return err
+		}
+		if matchesJobAlert(alert, &job) {
+			matches = append(matches, job)
+		}
+	}
+	rows.Close()
+
+	return withTx(func(tx *sql.Tx) error {
+		// A condição em last_run_at impede que duas execuções enviem o mesmo resumo
+		result, err := tx.Exec("UPDATE job_alerts SET last_run_at = ? WHERE id = ? AND last_run_at = ?",
+			now, alert.ID, alert.LastRunAt)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 || len(matches) == 0 {
+			return nil
+		}
+
+		if _, err := tx.Exec("UPDATE job_alerts SET last_sent_at = ? WHERE id = ?", now, alert.ID); err != nil {
+			return err
+		}
+
+		var email string
+		if err := tx.QueryRow("SELECT email FROM users WHERE id = ?", alert.UserID).Scan(&email); err != nil {
+			return err
+		}
+		return enqueueEmail(tx, jobAlertEmail(alert, email, matches))
+	})
+}
+
+func jobAlertEmail(alert *JobAlert, to string, jobs []Job) Email {
+	lines := []string{}
+	for i, job := range jobs {
+		if i == maxJobAlertEmailJobs {
+			lines = append(lines, fmt.Sprintf("... e mais %d vaga(s)", len(jobs)-maxJobAlertEmailJobs))
+			break
+		}
+		line := fmt.Sprintf("- %s - %s (%s, %s)", job.Title, job.Company, job.Location, job.Type)
+		if job.Salary != "" {
+			line += " - " + job.Salary
+		}
+		lines = append(lines, line)
+	}
+
+	return Email{
+		To:      []string{to},
+		Subject: fmt.Sprintf("%d nova(s) vaga(s) para o alerta \"%s\"", len(jobs), alert.Name),
+		Body: fmt.Sprintf("Estas vagas foram publicadas desde o último resumo:\n\n%s\n\n"+
+			"Para não receber mais este alerta, acesse:\n%s/%s",
+			strings.Join(lines, "\n"), jobAlertUnsubscribeURL, signJobAlertToken(alert.ID)),
+	}
+}
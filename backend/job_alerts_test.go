@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseSalary(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"R$ 5.000,00", 5000, true},
+		{"5000 - 7000", 7000, true},
+		{"R$ 4.500,50 a R$ 6.200,99", 6200, true},
+		{"8k", 8000, true},
+		{"até 10K", 10000, true},
+		{"5.5k", 5500, true},
+		{"5,5k", 5500, true},
+		{"7,5 mil", 7500, true},
+		{"de 4 a 7,25 mil", 7250, true},
+		{"12 mil", 12000, true},
+		{"1.200.000", 1200000, true},
+		{"R$ 3.500", 3500, true},
+		{"A combinar", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSalary(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseSalary(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMatchesJobAlert(t *testing.T) {
+	job := &Job{
+		Title:       "Desenvolvedor Go Sênior",
+		Description: "Microsserviços com PostgreSQL e Kafka",
+		Company:     "Acme",
+		Location:    "São Paulo - SP",
+		Type:        "full-time",
+		Salary:      "R$ 9.000 - 12.000",
+	}
+
+	tests := []struct {
+		name  string
+		alert JobAlert
+		job   *Job
+		want  bool
+	}{
+		{name: "alerta sem critérios", alert: JobAlert{}, job: job, want: true},
+		{name: "palavras no título e na descrição", alert: JobAlert{Keywords: "go, kafka"}, job: job, want: true},
+		{name: "palavra na empresa", alert: JobAlert{Keywords: "ACME"}, job: job, want: true},
+		{name: "todas as palavras são exigidas", alert: JobAlert{Keywords: "go python"}, job: job, want: false},
+		{name: "local parcial", alert: JobAlert{Location: "são paulo"}, job: job, want: true},
+		{name: "outro local", alert: JobAlert{Location: "Recife"}, job: job, want: false},
+		{name: "tipo sem diferenciar maiúsculas", alert: JobAlert{Type: "FULL-TIME"}, job: job, want: true},
+		{name: "outro tipo", alert: JobAlert{Type: "part-time"}, job: job, want: false},
+		{name: "salário máximo atende", alert: JobAlert{MinSalary: 12000}, job: job, want: true},
+		{name: "salário abaixo do mínimo", alert: JobAlert{MinSalary: 12001}, job: job, want: false},
+		{name: "salário com multiplicador", alert: JobAlert{MinSalary: 7500}, job: &Job{Salary: "7,5 mil"}, want: true},
+		{name: "salário a combinar", alert: JobAlert{MinSalary: 1000}, job: &Job{Salary: "A combinar"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesJobAlert(&tt.alert, tt.job); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobAlertToken(t *testing.T) {
+	token := signJobAlertToken(42)
+
+	// id.assinatura, com HMAC-SHA256 de "job-alert:<id>" sob o segredo do JWT
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte("job-alert:42"))
+	if want := "42." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)); token != want {
+		t.Fatalf("token %q, want %q", token, want)
+	}
+
+	other := signJobAlertToken(43)
+	tests := []struct {
+		name   string
+		token  string
+		wantID int
+		wantOK bool
+	}{
+		{name: "válido", token: token, wantID: 42, wantOK: true},
+		{name: "id trocado", token: "43" + token[2:], wantOK: false},
+		{name: "assinatura de outro alerta", token: "42" + other[2:], wantOK: false},
+		{name: "assinatura alterada", token: token[:len(token)-1] + "A", wantOK: false},
+		{name: "sem assinatura", token: "42", wantOK: false},
+		{name: "partes demais", token: token + ".x", wantOK: false},
+		{name: "vazio", token: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := parseJobAlertToken(tt.token)
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Errorf("got %d, %v; want %d, %v", id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,20 +1,51 @@
 package main
 
 import (
+	"database/sql"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Período em que uma vaga excluída pode ser restaurada antes do expurgo
+var jobRetention = time.Duration(getEnvInt("JOB_RETENTION_DAYS", 90)) * 24 * time.Hour
+
+// getJobsHandler lista as vagas abertas. Com ?skills=go,docker, apenas as que
+// pedem todas as habilidades informadas (sinônimos valem pelo nome canônico).
 func getJobsHandler(c *gin.Context) {
+	where := "j.deleted_at IS NULL"
+	var args []interface{}
+	for _, name := range strings.Split(c.Query("skills"), ",") {
+		_, slug, err := canonicalSkill(db, name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar vagas"})
+			return
+		}
+		if slug == "" {
+			continue
+		}
+		where += " AND EXISTS (SELECT 1 FROM job_skills js WHERE js.job_id = j.id AND js.slug = ?)"
+		args = append(args, slug)
+	}
+
+	skills, err := loadJobSkills(where, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar vagas"})
+		return
+	}
+
 	rows, err := db.Query(`
-		SELECT j.id, j.title, j.description, j.company, j.location, j.salary, j.type, j.user_id, j.created_at, j.updated_at,
-		       u.name as user_name
+		SELECT j.id, j.title, j.description, j.company, j.location, j.salary, j.type, j.work_mode, j.seniority, j.user_id, j.closes_at, j.created_at, j.updated_at,
+		       u.name as user_name, s.user_id IS NOT NULL as saved
 		FROM jobs j
 		JOIN users u ON j.user_id = u.id
-		ORDER BY j.created_at DESC`)
+		LEFT JOIN saved_jobs s ON s.job_id = j.id AND s.user_id = ?
+		WHERE `+where+`
+		ORDER BY j.created_at DESC`, append([]interface{}{c.GetInt("user_id")}, args...)...)
 	
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar vagas"})
@@ -26,13 +57,18 @@ func getJobsHandler(c *gin.Context) {
 	for rows.Next() {
 		var job Job
 		var userName string
+		var closesAt sql.NullTime
+		var saved bool
 		err := rows.Scan(
 			&job.ID, &job.Title, &job.Description, &job.Company, &job.Location,
-			&job.Salary, &job.Type, &job.UserID, &job.CreatedAt, &job.UpdatedAt, &userName)
+			&job.Salary, &job.Type, &job.WorkMode, &job.Seniority, &job.UserID, &closesAt, &job.CreatedAt, &job.UpdatedAt, &userName, &saved)
 		
 		if err != nil {
 			continue
 		}
+		if closesAt.Valid {
+			job.ClosesAt = &closesAt.Time
+		}
 
 		jobs = append(jobs, gin.H{
 			"id":          job.ID,
@@ -42,8 +78,13 @@ func getJobsHandler(c *gin.Context) {
 			"location":    job.Location,
 			"salary":      job.Salary,
 			"type":        job.Type,
+			"work_mode":   job.WorkMode,
+			"seniority":   job.Seniority,
+			"skills":      jobSkillsOrEmpty(skills[job.ID]),
 			"user_id":     job.UserID,
 			"user_name":   userName,
+			"closes_at":   job.ClosesAt,
+			"saved":       saved,
 			"created_at":  job.CreatedAt,
 			"updated_at":  job.UpdatedAt,
 		})
@@ -59,20 +100,44 @@ func createJobHandler(c *gin.Context) {
 		return
 	}
 
+	if req.ClosesAt != nil && !req.ClosesAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A data de encerramento deve estar no futuro"})
+		return
+	}
+	if req.ClosesAt != nil {
+		*req.ClosesAt = req.ClosesAt.UTC()
+	}
+
 	userID := c.GetInt("user_id")
 	now := time.Now()
 
-	result, err := db.Exec(`
-		INSERT INTO jobs (title, description, company, location, salary, type, user_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		req.Title, req.Description, req.Company, req.Location, req.Salary, req.Type, userID, now, now)
+	var jobID int64
+	err := withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO jobs (title, description, company, location, salary, type, work_mode, seniority, user_id, closes_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			req.Title, req.Description, req.Company, req.Location, req.Salary, req.Type, req.WorkMode, req.Seniority,
+			userID, req.ClosesAt, now, now)
+		if err != nil {
+			return err
+		}
+		jobID, _ = result.LastInsertId()
+
+		if err := replaceJobSkills(tx, int(jobID), req.RequiredSkills, req.NiceToHaveSkills); err != nil {
+			return err
+		}
+
+		data, err := jobWebhookData(tx, int(jobID))
+		if err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, "job.created", int(jobID), data)
+	})
 	
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar vaga"})
 		return
 	}
-
-	jobID, _ := result.LastInsertId()
 	
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Vaga criada com sucesso",
@@ -89,20 +154,48 @@ func getJobHandler(c *gin.Context) {
 
 	var job Job
 	var userName string
+	var closesAt, deletedAt sql.NullTime
+	var saved bool
 	err = db.QueryRow(`
-		SELECT j.id, j.title, j.description, j.company, j.location, j.salary, j.type, j.user_id, j.created_at, j.updated_at,
-		       u.name as user_name
+		SELECT j.id, j.title, j.description, j.company, j.location, j.salary, j.type, j.work_mode, j.seniority, j.user_id, j.closes_at, j.deleted_at, j.created_at, j.updated_at,
+		       u.name as user_name, s.user_id IS NOT NULL as saved
 		FROM jobs j
 		JOIN users u ON j.user_id = u.id
-		WHERE j.id = ?`, jobID).Scan(
+		LEFT JOIN saved_jobs s ON s.job_id = j.id AND s.user_id = ?
+		WHERE j.id = ?`, c.GetInt("user_id"), jobID).Scan(
 		&job.ID, &job.Title, &job.Description, &job.Company, &job.Location,
-		&job.Salary, &job.Type, &job.UserID, &job.CreatedAt, &job.UpdatedAt, &userName)
+		&job.Salary, &job.Type, &job.WorkMode, &job.Seniority, &job.UserID, &closesAt, &deletedAt, &job.CreatedAt, &job.UpdatedAt, &userName, &saved)
 	
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
 		return
 	}
 
+	// Vagas arquivadas só são visíveis para o dono e administradores
+	if deletedAt.Valid {
+		userID := c.GetInt("user_id")
+		if job.UserID != userID && !isAdmin(userID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
+			return
+		}
+		job.DeletedAt = &deletedAt.Time
+	}
+	if closesAt.Valid {
+		job.ClosesAt = &closesAt.Time
+	}
+
+	questions, err := listScreeningQuestions(job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar perguntas da vaga"})
+		return
+	}
+
+	skills, err := listJobSkills(job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar habilidades da vaga"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"job": gin.H{
 			"id":          job.ID,
@@ -112,8 +205,15 @@ func getJobHandler(c *gin.Context) {
 			"location":    job.Location,
 			"salary":      job.Salary,
 			"type":        job.Type,
+			"work_mode":   job.WorkMode,
+			"seniority":   job.Seniority,
+			"skills":      skills,
 			"user_id":     job.UserID,
 			"user_name":   userName,
+			"questions":   candidateScreeningQuestions(questions, job.ID, c.GetInt("user_id")),
+			"closes_at":   job.ClosesAt,
+			"saved":       saved,
+			"deleted_at":  job.DeletedAt,
 			"created_at":  job.CreatedAt,
 			"updated_at":  job.UpdatedAt,
 		},
@@ -137,7 +237,8 @@ func updateJobHandler(c *gin.Context) {
 
 	// Verificar se a vaga pertence ao usuário
 	var existingJob Job
-	err = db.QueryRow("SELECT user_id FROM jobs WHERE id = ?", jobID).Scan(&existingJob.UserID)
+	var closesAt, deletedAt sql.NullTime
+	err = db.QueryRow("SELECT user_id, closes_at, deleted_at FROM jobs WHERE id = ?", jobID).Scan(&existingJob.UserID, &closesAt, &deletedAt)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
 		return
@@ -148,10 +249,41 @@ func updateJobHandler(c *gin.Context) {
 		return
 	}
 
-	_, err = db.Exec(`
-		UPDATE jobs SET title = ?, description = ?, company = ?, location = ?, salary = ?, type = ?, updated_at = ?
-		WHERE id = ?`,
-		req.Title, req.Description, req.Company, req.Location, req.Salary, req.Type, time.Now(), jobID)
+	if deletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Restaure a vaga antes de editá-la"})
+		return
+	}
+
+	if req.ClosesAt != nil {
+		*req.ClosesAt = req.ClosesAt.UTC()
+	}
+
+	// Uma data já passada só é aceita se não tiver sido alterada
+	closesAtChanged := closesAt.Valid != (req.ClosesAt != nil) || (req.ClosesAt != nil && !req.ClosesAt.Equal(closesAt.Time))
+	if closesAtChanged && req.ClosesAt != nil && !req.ClosesAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A data de encerramento deve estar no futuro"})
+		return
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE jobs SET title = ?, description = ?, company = ?, location = ?, salary = ?, type = ?, work_mode = ?, seniority = ?,
+			                closes_at = ?, updated_at = ?
+			WHERE id = ?`,
+			req.Title, req.Description, req.Company, req.Location, req.Salary, req.Type, req.WorkMode, req.Seniority,
+			req.ClosesAt, time.Now(), jobID)
+		if err != nil {
+			return err
+		}
+
+		if err := replaceJobSkills(tx, jobID, req.RequiredSkills, req.NiceToHaveSkills); err != nil || !closesAtChanged {
+			return err
+		}
+
+		// Com a nova data, quem salvou a vaga volta a ser avisado do encerramento
+		_, err = tx.Exec("UPDATE saved_jobs SET reminded_at = NULL WHERE job_id = ?", jobID)
+		return err
+	})
 	
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar vaga"})
@@ -161,6 +293,9 @@ func updateJobHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Vaga atualizada com sucesso"})
 }
 
+// deleteJobHandler arquiva a vaga. As candidaturas são preservadas e marcadas
+// como "vaga encerrada"; a exclusão definitiva acontece no expurgo, depois do
+// período de retenção.
 func deleteJobHandler(c *gin.Context) {
 	jobID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -172,30 +307,265 @@ func deleteJobHandler(c *gin.Context) {
 
 	// Verificar se a vaga pertence ao usuário
 	var existingJob Job
-	err = db.QueryRow("SELECT user_id FROM jobs WHERE id = ?", jobID).Scan(&existingJob.UserID)
+	var deletedAt sql.NullTime
+	err = db.QueryRow("SELECT user_id, deleted_at FROM jobs WHERE id = ?", jobID).Scan(&existingJob.UserID, &deletedAt)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
 		return
 	}
 
-	if existingJob.UserID != userID {
+	if existingJob.UserID != userID && !isAdmin(userID) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para excluir esta vaga"})
 		return
 	}
 
-	// Excluir candidaturas primeiro
-	_, err = db.Exec("DELETE FROM applications WHERE job_id = ?", jobID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir candidaturas"})
+	if deletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta vaga já foi excluída"})
 		return
 	}
 
-	// Excluir a vaga
-	_, err = db.Exec("DELETE FROM jobs WHERE id = ?", jobID)
+	now := time.Now()
+	err = withTx(func(tx *sql.Tx) error {
+		// Marcar as candidaturas como "vaga encerrada"
+		_, err := tx.Exec("UPDATE applications SET job_closed_at = ? WHERE job_id = ?", now, jobID)
+		if err != nil {
+			return err
+		}
+
+		// Arquivar a vaga
+		_, err = tx.Exec("UPDATE jobs SET deleted_at = ?, updated_at = ? WHERE id = ?", now, now, jobID)
+		if err != nil {
+			return err
+		}
+
+		data, err := jobWebhookData(tx, jobID)
+		if err != nil {
+			return err
+		}
+		data["closed_at"] = now
+		return enqueueWebhookEvent(tx, "job.closed", jobID, data)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir vaga"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Vaga excluída com sucesso"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Vaga excluída com sucesso",
+		"restore_until": now.Add(jobRetention),
+	})
+}
+
+func restoreJobHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var existingJob Job
+	var deletedAt sql.NullTime
+	err = db.QueryRow("SELECT user_id, deleted_at FROM jobs WHERE id = ?", jobID).Scan(&existingJob.UserID, &deletedAt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
+		return
+	}
+
+	if existingJob.UserID != userID && !isAdmin(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para restaurar esta vaga"})
+		return
+	}
+
+	if !deletedAt.Valid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta vaga não está excluída"})
+		return
+	}
+
+	if time.Since(deletedAt.Time) > jobRetention {
+		c.JSON(http.StatusGone, gin.H{"error": "O período para restaurar esta vaga expirou"})
+		return
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE applications SET job_closed_at = NULL WHERE job_id = ?", jobID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE jobs SET deleted_at = NULL, updated_at = ? WHERE id = ?", time.Now(), jobID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao restaurar vaga"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Vaga restaurada com sucesso"})
+}
+
+// getArchivedJobsHandler lista as vagas excluídas que ainda podem ser restauradas.
+// Administradores veem as vagas de todos os usuários.
+func getArchivedJobsHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	query := `
+		SELECT j.id, j.title, j.company, j.location, j.type, j.user_id, j.deleted_at, u.name as user_name
+		FROM jobs j
+		JOIN users u ON j.user_id = u.id
+		WHERE j.deleted_at IS NOT NULL`
+	args := []interface{}{}
+	if !isAdmin(userID) {
+		query += " AND j.user_id = ?"
+		args = append(args, userID)
+	}
+	query += " ORDER BY j.deleted_at DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar vagas excluídas"})
+		return
+	}
+	defer rows.Close()
+
+	jobs := []gin.H{}
+	for rows.Next() {
+		var job Job
+		var userName string
+		var deletedAt time.Time
+		err := rows.Scan(&job.ID, &job.Title, &job.Company, &job.Location, &job.Type, &job.UserID, &deletedAt, &userName)
+		if err != nil {
+			continue
+		}
+
+		jobs = append(jobs, gin.H{
+			"id":         job.ID,
+			"title":      job.Title,
+			"company":    job.Company,
+			"location":   job.Location,
+			"type":       job.Type,
+			"user_id":    job.UserID,
+			"user_name":  userName,
+			"deleted_at": deletedAt,
+			"purge_at":   deletedAt.Add(jobRetention),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// requireJobOwner carrega a vaga e confirma que pertence ao usuário logado
+func requireJobOwner(c *gin.Context, jobID int) bool {
+	var ownerID int
+	err := db.QueryRow("SELECT user_id FROM jobs WHERE id = ?", jobID).Scan(&ownerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
+		return false
+	}
+
+	if ownerID != c.GetInt("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para editar esta vaga"})
+		return false
+	}
+	return true
+}
+
+// purgeExpiredJobs remove definitivamente as vagas excluídas há mais tempo que
+// o período de retenção, junto com candidaturas, respostas e currículos anexados
+func purgeExpiredJobs() {
+	cutoff := time.Now().Add(-jobRetention)
+
+	rows, err := db.Query("SELECT id FROM jobs WHERE deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+	if err != nil {
+		log.Printf("Erro ao buscar vagas para expurgo: %v", err)
+		return
+	}
+	var jobIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			jobIDs = append(jobIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, jobID := range jobIDs {
+		if err := purgeJob(jobID); err != nil {
+			log.Printf("Erro ao expurgar vaga %d: %v", jobID, err)
+			continue
+		}
+		log.Printf("Vaga %d expurgada após o período de retenção", jobID)
+	}
+}
+
+func purgeJob(jobID int) error {
+	// Os arquivos só são apagados depois que os registros forem removidos
+	var keys []string
+	rows, err := db.Query(`
+		SELECT r.storage_key FROM application_resumes r
+		JOIN applications a ON r.application_id = a.id
+		WHERE a.job_id = ?
+		UNION ALL
+		SELECT t.storage_key FROM thread_attachments t
+		JOIN thread_messages m ON t.message_id = m.id
+		JOIN applications a ON m.application_id = a.id
+		WHERE a.job_id = ?`, jobID, jobID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	rows.Close()
+
+	statements := []string{
+		"DELETE FROM application_answers WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM application_resumes WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM application_status_history WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM note_mentions WHERE note_id IN (SELECT n.id FROM application_notes n JOIN applications a ON n.application_id = a.id WHERE a.job_id = ?)",
+		"DELETE FROM application_notes WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM notifications WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM notification_deliveries WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM scorecard_ratings WHERE scorecard_id IN (SELECT s.id FROM scorecards s JOIN applications a ON s.application_id = a.id WHERE a.job_id = ?)",
+		"DELETE FROM scorecards WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM candidate_messages WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM application_tags WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM thread_attachments WHERE message_id IN (SELECT m.id FROM thread_messages m JOIN applications a ON m.application_id = a.id WHERE a.job_id = ?)",
+		"DELETE FROM thread_message_recipients WHERE message_id IN (SELECT m.id FROM thread_messages m JOIN applications a ON m.application_id = a.id WHERE a.job_id = ?)",
+		"DELETE FROM thread_messages WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM offer_approvals WHERE offer_id IN (SELECT o.id FROM offers o JOIN applications a ON o.application_id = a.id WHERE a.job_id = ?)",
+		"DELETE FROM offers WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM scheduling_link_interviewers WHERE link_id IN (SELECT l.id FROM scheduling_links l JOIN applications a ON l.application_id = a.id WHERE a.job_id = ?)",
+		"DELETE FROM scheduling_links WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM interview_interviewers WHERE interview_id IN (SELECT i.id FROM interviews i JOIN applications a ON i.application_id = a.id WHERE a.job_id = ?)",
+		"DELETE FROM interviews WHERE application_id IN (SELECT id FROM applications WHERE job_id = ?)",
+		"DELETE FROM applications WHERE job_id = ?",
+		"DELETE FROM screening_questions WHERE job_id = ?",
+		"DELETE FROM job_team_members WHERE job_id = ?",
+		"DELETE FROM job_offer_approvers WHERE job_id = ?",
+		"DELETE FROM scorecard_criteria WHERE job_id = ?",
+		"DELETE FROM saved_jobs WHERE job_id = ?",
+		"DELETE FROM job_skills WHERE job_id = ?",
+		"DELETE FROM jobs WHERE id = ?",
+	}
+	err = withTx(func(tx *sql.Tx) error {
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt, jobID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		fileStorage.Delete(key)
+	}
+	return nil
 }
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Email é uma mensagem em texto simples com anexos opcionais
+type Email struct {
+	To          []string          `json:"to"`
+	Subject     string            `json:"subject"`
+	Body        string            `json:"body"`
+	Attachments []EmailAttachment `json:"attachments,omitempty"`
+}
+
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// Mailer abstrai o envio de emails. Sem SMTP configurado as mensagens só são
+// registradas no log, o que basta para desenvolvimento.
+type Mailer interface {
+	Send(msg Email) error
+}
+
+// Mailer usado pela aplicação
+var mailer Mailer = newMailerFromEnv()
+
+func newMailerFromEnv() Mailer {
+	host := getEnv("SMTP_HOST", "")
+	if host == "" {
+		return LogMailer{}
+	}
+	return &SMTPMailer{
+		Host:     host,
+		Port:     getEnvInt("SMTP_PORT", 587),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("SMTP_FROM", "nao-responda@localhost"),
+	}
+}
+
+// LogMailer escreve as mensagens no log do servidor em vez de enviá-las
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Email) error {
+	names := make([]string, 0, len(msg.Attachments))
+	for _, a := range msg.Attachments {
+		names = append(names, a.Filename)
+	}
+	log.Printf("Email para %s: %s\n%s\nAnexos: %v", strings.Join(msg.To, ", "), msg.Subject, msg.Body, names)
+	return nil
+}
+
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(msg Email) error {
+	data, err := buildMIMEMessage(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(fmt.Sprintf("%s:%d", m.Host, m.Port), auth, m.From, msg.To, data)
+}
+
+// buildMIMEMessage monta a mensagem multipart/mixed com o corpo em texto e os anexos
+func buildMIMEMessage(from string, msg Email) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(msg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q", writer.Boundary()),
+	}
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64Lines(part, []byte(msg.Body))
+
+	for _, a := range msg.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", a.Filename)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64Lines(part, a.Data)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64Lines codifica em base64 com linhas de 76 caracteres (RFC 2045)
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
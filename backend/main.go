@@ -23,31 +23,200 @@ func main() {
 	{
 		auth.POST("/register", registerHandler)
 		auth.POST("/login", loginHandler)
+		auth.POST("/forgot-password", forgotPasswordHandler)
+		auth.POST("/reset-password", resetPasswordHandler)
+	}
+
+	// Agendamento de entrevistas pelo candidato (link assinado, sem login)
+	scheduling := r.Group("/scheduling")
+	{
+		scheduling.GET("/:token", getPublicSchedulingHandler)
+		scheduling.POST("/:token/book", bookPublicSchedulingHandler)
+	}
+
+	// Cancelamento de alertas de vagas pelo link do email (link assinado, sem login)
+	alerts := r.Group("/alerts")
+	{
+		alerts.GET("/unsubscribe/:token", getJobAlertUnsubscribeHandler)
+		alerts.POST("/unsubscribe/:token", unsubscribeJobAlertHandler)
 	}
 
 	// Rotas protegidas
 	protected := r.Group("/api")
 	protected.Use(authMiddleware())
 	{
-		protected.GET("/jobs", getJobsHandler)
-		protected.POST("/jobs", createJobHandler)
-		protected.GET("/jobs/:id", getJobHandler)
-		protected.PUT("/jobs/:id", updateJobHandler)
-		protected.DELETE("/jobs/:id", deleteJobHandler)
+		protected.GET("/jobs", requireScope("jobs:read"), getJobsHandler)
+		protected.GET("/jobs/archived", requireScope("jobs:read"), getArchivedJobsHandler)
+		protected.GET("/jobs/recommended", requireScope("jobs:read"), getRecommendedJobsHandler)
+		protected.GET("/skills", requireScope("jobs:read"), searchSkillsHandler)
+		protected.POST("/jobs", requireScope("jobs:write"), createJobHandler)
+		protected.GET("/jobs/:id", requireScope("jobs:read"), getJobHandler)
+		protected.PUT("/jobs/:id", requireScope("jobs:write"), updateJobHandler)
+		protected.DELETE("/jobs/:id", requireScope("jobs:write"), deleteJobHandler)
+		protected.POST("/jobs/:id/restore", requireScope("jobs:write"), restoreJobHandler)
+		protected.POST("/jobs/:id/save", requireScope("profile:write"), saveJobHandler)
+		protected.DELETE("/jobs/:id/save", requireScope("profile:write"), unsaveJobHandler)
+		protected.GET("/saved-jobs", requireScope("profile:read"), getSavedJobsHandler)
+		protected.GET("/job-alerts", requireScope("profile:read"), getJobAlertsHandler)
+		protected.POST("/job-alerts", requireScope("profile:write"), createJobAlertHandler)
+		protected.PUT("/job-alerts/:id", requireScope("profile:write"), updateJobAlertHandler)
+		protected.DELETE("/job-alerts/:id", requireScope("profile:write"), deleteJobAlertHandler)
+		protected.GET("/jobs/:id/applications", requireScope("applications:read"), getJobApplicationsHandler)
+		protected.GET("/jobs/:id/suggested-candidates", requireScope("applications:read"), getSuggestedCandidatesHandler)
+		protected.GET("/jobs/:id/team", requireScope("jobs:read"), getJobTeamHandler)
+		protected.POST("/jobs/:id/team", requireScope("jobs:write"), addJobTeamMemberHandler)
+		protected.DELETE("/jobs/:id/team/:userId", requireScope("jobs:write"), removeJobTeamMemberHandler)
+		protected.GET("/jobs/:id/scorecard", requireScope("jobs:read"), getScorecardTemplateHandler)
+		protected.PUT("/jobs/:id/scorecard", requireScope("jobs:write"), updateScorecardTemplateHandler)
+
+		protected.GET("/jobs/:id/questions", requireScope("jobs:read"), getScreeningQuestionsHandler)
+		protected.POST("/jobs/:id/questions", requireScope("jobs:write"), createScreeningQuestionHandler)
+		protected.PUT("/jobs/:id/questions/:questionId", requireScope("jobs:write"), updateScreeningQuestionHandler)
+		protected.DELETE("/jobs/:id/questions/:questionId", requireScope("jobs:write"), deleteScreeningQuestionHandler)
 		
-		protected.GET("/applications", getApplicationsHandler)
-		protected.POST("/applications", createApplicationHandler)
-		protected.GET("/applications/:id", getApplicationHandler)
-		protected.PUT("/applications/:id", updateApplicationHandler)
-		protected.DELETE("/applications/:id", deleteApplicationHandler)
+		protected.GET("/applications", requireScope("applications:read"), getApplicationsHandler)
+		protected.POST("/applications", requireScope("applications:write"), createApplicationHandler)
+		protected.POST("/applications/bulk", requireScope("applications:write"), bulkApplicationsHandler)
+		protected.GET("/applications/:id", requireScope("applications:read"), getApplicationHandler)
+		protected.DELETE("/applications/:id", requireScope("applications:write"), deleteApplicationHandler)
+		protected.POST("/applications/:id/withdraw", requireScope("applications:write"), withdrawApplicationHandler)
+		protected.GET("/applications/:id/history", requireScope("applications:read"), getApplicationHistoryHandler)
+		protected.GET("/applications/:id/timeline", requireScope("applications:read"), getApplicationTimelineHandler)
+		protected.GET("/applications/:id/notes", requireScope("applications:read"), getApplicationNotesHandler)
+		protected.POST("/applications/:id/notes", requireScope("applications:write"), createApplicationNoteHandler)
+		protected.PUT("/applications/:id/notes/:noteId", requireScope("applications:write"), updateApplicationNoteHandler)
+		protected.DELETE("/applications/:id/notes/:noteId", requireScope("applications:write"), deleteApplicationNoteHandler)
+		protected.GET("/applications/:id/scorecards", requireScope("applications:read"), getScorecardsHandler)
+		protected.PUT("/applications/:id/scorecard", requireScope("applications:write"), saveScorecardHandler)
+		protected.POST("/applications/:id/scorecard/submit", requireScope("applications:write"), submitScorecardHandler)
+
+		protected.GET("/applications/:id/interviews", requireScope("applications:read"), getApplicationInterviewsHandler)
+		protected.POST("/applications/:id/interviews", requireScope("applications:write"), createInterviewHandler)
+		protected.GET("/interviews", requireScope("applications:read"), getMyInterviewsHandler)
+		protected.PUT("/interviews/:id", requireScope("applications:write"), updateInterviewHandler)
+		protected.POST("/interviews/:id/cancel", requireScope("applications:write"), cancelInterviewHandler)
+		protected.GET("/interviews/:id/invite.ics", requireScope("applications:read"), downloadInterviewICSHandler)
+		protected.GET("/applications/:id/slots", requireScope("applications:read"), getApplicationSlotsHandler)
+		protected.GET("/applications/:id/scheduling-links", requireScope("applications:read"), getSchedulingLinksHandler)
+		protected.POST("/applications/:id/scheduling-links", requireScope("applications:write"), createSchedulingLinkHandler)
+		protected.DELETE("/scheduling-links/:id", requireScope("applications:write"), revokeSchedulingLinkHandler)
+
+		protected.GET("/jobs/:id/offer-approvers", requireScope("jobs:read"), getJobOfferApproversHandler)
+		protected.PUT("/jobs/:id/offer-approvers", requireScope("jobs:write"), updateJobOfferApproversHandler)
+		protected.GET("/applications/:id/offers", requireScope("applications:read"), getApplicationOffersHandler)
+		protected.POST("/applications/:id/offers", requireScope("applications:write"), createOfferHandler)
+		protected.GET("/offers/:id", requireScope("applications:read"), getOfferHandler)
+		protected.PUT("/offers/:id", requireScope("applications:write"), updateOfferHandler)
+		protected.GET("/offers/:id/letter", requireScope("applications:read"), getOfferLetterHandler)
+		protected.POST("/offers/:id/submit", requireScope("applications:write"), submitOfferHandler)
+		protected.POST("/offers/:id/approve", requireScope("applications:write"), approveOfferHandler)
+		protected.POST("/offers/:id/reject", requireScope("applications:write"), rejectOfferHandler)
+		protected.POST("/offers/:id/send", requireScope("applications:write"), sendOfferHandler)
+		protected.POST("/offers/:id/withdraw", requireScope("applications:write"), withdrawOfferHandler)
+		protected.POST("/offers/:id/accept", requireScope("applications:write"), acceptOfferHandler)
+		protected.POST("/offers/:id/decline", requireScope("applications:write"), declineOfferHandler)
+		protected.GET("/offer-templates", requireScope("jobs:read"), getOfferTemplatesHandler)
+		protected.POST("/offer-templates", requireScope("jobs:write"), createOfferTemplateHandler)
+		protected.PUT("/offer-templates/:id", requireScope("jobs:write"), updateOfferTemplateHandler)
+		protected.DELETE("/offer-templates/:id", requireScope("jobs:write"), deleteOfferTemplateHandler)
+
+		protected.POST("/applications/:id/reject", requireScope("applications:write"), rejectApplicationHandler)
+		protected.GET("/applications/:id/messages", requireScope("applications:read"), getApplicationMessagesHandler)
+		protected.POST("/candidate-messages/:id/cancel", requireScope("applications:write"), cancelCandidateMessageHandler)
+		protected.GET("/applications/:id/thread", requireScope("applications:read"), getThreadHandler)
+		protected.POST("/applications/:id/thread", requireScope("applications:write"), sendThreadMessageHandler)
+		protected.POST("/applications/:id/thread/read", requireScope("applications:write"), markThreadReadHandler)
+		protected.GET("/threads/unread", requireScope("applications:read"), getUnreadThreadsHandler)
+		protected.GET("/thread-attachments/:id", requireScope("applications:read"), downloadThreadAttachmentHandler)
+		protected.GET("/rejection-reasons", requireScope("applications:read"), getRejectionReasonsHandler)
+		protected.POST("/rejection-reasons", requireSession(), requireAdmin(), createRejectionReasonHandler)
+		protected.PUT("/rejection-reasons/:id", requireSession(), requireAdmin(), updateRejectionReasonHandler)
+		protected.GET("/reports/rejection-reasons", requireScope("applications:read"), getRejectionReportHandler)
+		protected.GET("/message-templates", requireScope("applications:read"), getMessageTemplatesHandler)
+		protected.POST("/message-templates", requireScope("applications:write"), createMessageTemplateHandler)
+		protected.PUT("/message-templates/:id", requireScope("applications:write"), updateMessageTemplateHandler)
+		protected.DELETE("/message-templates/:id", requireScope("applications:write"), deleteMessageTemplateHandler)
+
+		protected.GET("/availability", requireScope("profile:read"), getAvailabilityHandler)
+		protected.PUT("/availability", requireScope("profile:write"), updateAvailabilityHandler)
+		protected.POST("/availability/blackouts", requireScope("profile:write"), createBlackoutHandler)
+		protected.DELETE("/availability/blackouts/:id", requireScope("profile:write"), deleteBlackoutHandler)
+		protected.GET("/applications/:id/resume", requireScope("applications:read"), downloadApplicationResumeHandler)
 		
-		protected.GET("/profile", getProfileHandler)
-		protected.PUT("/profile", updateProfileHandler)
+		protected.GET("/profile", requireScope("profile:read"), getProfileHandler)
+		protected.PUT("/profile", requireScope("profile:write"), updateProfileHandler)
+		protected.PUT("/profile/password", requireSession(), changePasswordHandler)
+		protected.GET("/profile/preferences", requireScope("profile:read"), getJobPreferencesHandler)
+		protected.PUT("/profile/preferences", requireScope("profile:write"), updateJobPreferencesHandler)
+
+		// Perfil do candidato
+		protected.GET("/profile/experiences", requireScope("profile:read"), getExperiencesHandler)
+		protected.POST("/profile/experiences", requireScope("profile:write"), createExperienceHandler)
+		protected.PUT("/profile/experiences/:id", requireScope("profile:write"), updateExperienceHandler)
+		protected.DELETE("/profile/experiences/:id", requireScope("profile:write"), deleteExperienceHandler)
+
+		protected.GET("/profile/educations", requireScope("profile:read"), getEducationsHandler)
+		protected.POST("/profile/educations", requireScope("profile:write"), createEducationHandler)
+		protected.PUT("/profile/educations/:id", requireScope("profile:write"), updateEducationHandler)
+		protected.DELETE("/profile/educations/:id", requireScope("profile:write"), deleteEducationHandler)
+
+		protected.GET("/profile/skills", requireScope("profile:read"), getSkillsHandler)
+		protected.POST("/profile/skills", requireScope("profile:write"), addSkillsHandler)
+		protected.PUT("/profile/skills", requireScope("profile:write"), replaceSkillsHandler)
+		protected.DELETE("/profile/skills/:slug", requireScope("profile:write"), deleteSkillHandler)
+
+		protected.GET("/profile/links", requireScope("profile:read"), getProfileLinksHandler)
+		protected.POST("/profile/links", requireScope("profile:write"), createProfileLinkHandler)
+		protected.DELETE("/profile/links/:id", requireScope("profile:write"), deleteProfileLinkHandler)
+
+		protected.GET("/profile/resumes", requireScope("profile:read"), getResumesHandler)
+		protected.POST("/profile/resumes", requireScope("profile:write"), uploadResumeHandler)
+		protected.GET("/profile/resumes/:id/download", requireScope("profile:read"), downloadResumeHandler)
+		protected.DELETE("/profile/resumes/:id", requireScope("profile:write"), deleteResumeHandler)
+
+		protected.POST("/profile/resume/import", requireScope("profile:write"), importResumeHandler)
+		protected.POST("/profile/resume/import/confirm", requireScope("profile:write"), confirmResumeImportHandler)
+
+		protected.GET("/candidates/:id", requireScope("applications:read"), getCandidateProfileHandler)
+
+		protected.GET("/notifications", requireScope("profile:read"), getNotificationsHandler)
+		protected.POST("/notifications/:id/read", requireScope("profile:write"), markNotificationReadHandler)
+		protected.POST("/notifications/read-all", requireScope("profile:write"), markAllNotificationsReadHandler)
+		protected.GET("/events", requireScope("profile:read"), eventsHandler)
+		protected.GET("/notification-preferences", requireScope("profile:read"), getNotificationPreferencesHandler)
+		protected.PUT("/notification-preferences", requireScope("profile:write"), updateNotificationPreferencesHandler)
+
+		// Chaves de API só podem ser gerenciadas com login via JWT
+		protected.GET("/api-keys", requireSession(), getAPIKeysHandler)
+		protected.POST("/api-keys", requireSession(), createAPIKeyHandler)
+		protected.DELETE("/api-keys/:id", requireSession(), deleteAPIKeyHandler)
+
+		// Webhooks das integrações, também restritos ao login via JWT
+		protected.GET("/webhooks", requireSession(), getWebhooksHandler)
+		protected.POST("/webhooks", requireSession(), createWebhookHandler)
+		protected.GET("/webhooks/:id", requireSession(), getWebhookHandler)
+		protected.PUT("/webhooks/:id", requireSession(), updateWebhookHandler)
+		protected.DELETE("/webhooks/:id", requireSession(), deleteWebhookHandler)
+		protected.POST("/webhooks/:id/rotate-secret", requireSession(), rotateWebhookSecretHandler)
+		protected.GET("/webhooks/:id/deliveries", requireSession(), getWebhookDeliveriesHandler)
+		protected.POST("/webhook-deliveries/:id/replay", requireSession(), replayWebhookDeliveryHandler)
+
+		// Fila de tarefas em segundo plano (administradores)
+		protected.GET("/admin/background-jobs", requireSession(), requireAdmin(), getBackgroundJobsHandler)
+		protected.GET("/admin/background-jobs/:id", requireSession(), requireAdmin(), getBackgroundJobHandler)
+		protected.POST("/admin/background-jobs/:id/retry", requireSession(), requireAdmin(), retryBackgroundJobHandler)
+
+		// Taxonomia de habilidades (administradores)
+		protected.POST("/admin/skills/import", requireSession(), requireAdmin(), importSkillsHandler)
 	}
 
 	// Inicializar banco de dados
 	initDB()
 
+	// Fila de tarefas em segundo plano, que também executa as tarefas periódicas
+	// (expurgo de vagas, mensagens agendadas, notificações e webhooks)
+	startQueueWorkers()
+
 	log.Println("Servidor rodando na porta :8080")
 	r.Run(":8080")
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownBold    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownItalic  = regexp.MustCompile(`\*([^*]+)\*`)
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^\s)]+)\)`)
+	markdownMention = regexp.MustCompile(`(^|[^\w.])@([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+)
+
+// renderMarkdown converte o subconjunto de Markdown usado nas notas em HTML:
+// títulos, listas, citações, blocos de código, negrito, itálico, código,
+// links http(s) e menções. O texto é escapado antes da formatação, então o
+// resultado pode ser exibido sem sanitização adicional.
+func renderMarkdown(src string) string {
+	var sb strings.Builder
+	var paragraph []string
+	inList, inCode := false, false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			sb.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if inList {
+			sb.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flushParagraph()
+			closeList()
+			if inCode {
+				sb.WriteString("</code></pre>\n")
+			} else {
+				sb.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			sb.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case markdownHeadingLevel(trimmed) > 0:
+			flushParagraph()
+			closeList()
+			level := markdownHeadingLevel(trimmed)
+			fmt.Fprintf(&sb, "<h%d>%s</h%d>\n", level, renderInline(trimmed[level+1:]), level)
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushParagraph()
+			if !inList {
+				sb.WriteString("<ul>\n")
+				inList = true
+			}
+			sb.WriteString("<li>" + renderInline(trimmed[2:]) + "</li>\n")
+		case strings.HasPrefix(trimmed, "> "):
+			flushParagraph()
+			closeList()
+			sb.WriteString("<blockquote>" + renderInline(trimmed[2:]) + "</blockquote>\n")
+		default:
+			closeList()
+			paragraph = append(paragraph, renderInline(trimmed))
+		}
+	}
+
+	flushParagraph()
+	closeList()
+	if inCode {
+		sb.WriteString("</code></pre>\n")
+	}
+	return sb.String()
+}
+
+// markdownHeadingLevel devolve o nível do título ("## Texto" = 2) ou 0
+func markdownHeadingLevel(line string) int {
+	level := 0
+	for level < len(line) && level < 6 && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level >= len(line) || line[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// renderInline aplica a formatação de uma linha. Trechos entre crases viram
+// código e não recebem nenhuma outra formatação.
+func renderInline(text string) string {
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		escaped := html.EscapeString(part)
+		if i%2 == 1 && i < len(parts)-1 {
+			parts[i] = "<code>" + escaped + "</code>"
+			continue
+		}
+		escaped = renderLinks(escaped)
+		if i%2 == 1 {
+			// Crase sem par é mantida como texto
+			escaped = "`" + escaped
+		}
+		parts[i] = escaped
+	}
+	return strings.Join(parts, "")
+}
+
+// renderLinks converte os links e formata o texto ao redor e o rótulo de cada
+// um separadamente, para que negrito, itálico e menções nunca alterem o href
+func renderLinks(escaped string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range markdownLink.FindAllStringSubmatchIndex(escaped, -1) {
+		sb.WriteString(renderEmphasis(escaped[last:m[0]]))
+		fmt.Fprintf(&sb, `<a href="%s" rel="nofollow noopener" target="_blank">%s</a>`,
+			escaped[m[4]:m[5]], renderEmphasis(escaped[m[2]:m[3]]))
+		last = m[1]
+	}
+	sb.WriteString(renderEmphasis(escaped[last:]))
+	return sb.String()
+}
+
+// renderEmphasis aplica negrito, itálico e menções a um trecho já escapado
+func renderEmphasis(escaped string) string {
+	escaped = markdownBold.ReplaceAllString(escaped, "<strong>$1</strong>")
+	escaped = markdownItalic.ReplaceAllString(escaped, "<em>$1</em>")
+	return markdownMention.ReplaceAllString(escaped, `$1<span class="mention">@$2</span>`)
+}
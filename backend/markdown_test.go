@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestRenderInline(t *testing.T) {
+	const attrs = ` rel="nofollow noopener" target="_blank"`
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"texto simples", "olá", "olá"},
+		{"escapa html", `<script>alert("x")</script>`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{"negrito e itálico", "**forte** e *leve*", "<strong>forte</strong> e <em>leve</em>"},
+		{"código sem formatação", "`**x**` e **y**", "<code>**x**</code> e <strong>y</strong>"},
+		{"crase sem par", "a ` b", "a ` b"},
+		{"menção", "fale com @ana@x.com.br", `fale com <span class="mention">@ana@x.com.br</span>`},
+		{"link", "[site](https://x.com/a?b=1&c=2)", `<a href="https://x.com/a?b=1&amp;c=2"` + attrs + `>site</a>`},
+		{"link não http", "[x](javascript:alert(1))", "[x](javascript:alert(1))"},
+		{
+			"asteriscos no href",
+			"[a](https://x.com/*foo*) e [b](https://x.com/**bar**)",
+			`<a href="https://x.com/*foo*"` + attrs + `>a</a> e <a href="https://x.com/**bar**"` + attrs + `>b</a>`,
+		},
+		{
+			"menção no href",
+			"[perfil](https://x.com/@ana@x.com) de @bia@y.com",
+			`<a href="https://x.com/@ana@x.com"` + attrs + `>perfil</a> de <span class="mention">@bia@y.com</span>`,
+		},
+		{
+			"itálico atravessando links",
+			"[a](https://x.com/*) meio [b](https://y.com/*)",
+			`<a href="https://x.com/*"` + attrs + `>a</a> meio <a href="https://y.com/*"` + attrs + `>b</a>`,
+		},
+		{"rótulo formatado", "[**vaga**](https://x.com)", `<a href="https://x.com"` + attrs + `><strong>vaga</strong></a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderInline(tt.in); got != tt.want {
+				t.Errorf("renderInline(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"parágrafos", "a\nb\n\nc", "<p>a<br>b</p>\n<p>c</p>\n"},
+		{"título", "## Resumo", "<h2>Resumo</h2>\n"},
+		{"não é título", "#tag", "<p>#tag</p>\n"},
+		{"lista", "- um\n* dois\n\nfim", "<ul>\n<li>um</li>\n<li>dois</li>\n</ul>\n<p>fim</p>\n"},
+		{"citação", "> dito", "<blockquote>dito</blockquote>\n"},
+		{"bloco de código", "```\n<b>**x**</b>\n```", "<pre><code>&lt;b&gt;**x**&lt;/b&gt;\n</code></pre>\n"},
+		{"bloco sem fechamento", "```\nx", "<pre><code>x\n</code></pre>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderMarkdown(tt.in); got != tt.want {
+				t.Errorf("renderMarkdown(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,606 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Peso de cada critério na nota de compatibilidade. Critérios sem dados do
+// candidato ou da vaga ficam de fora e a nota é proporcional aos demais.
+const (
+	matchWeightRequiredSkills   = 40
+	matchWeightNiceToHaveSkills = 10
+	matchWeightLocation         = 20
+	matchWeightSalary           = 15
+	matchWeightSeniority        = 15
+)
+
+// Níveis em ordem crescente, usados para medir a distância entre candidato e vaga
+var seniorityLevels = []string{"junior", "mid", "senior", "lead"}
+
+var seniorityLabels = map[string]string{
+	"junior": "júnior",
+	"mid":    "pleno",
+	"senior": "sênior",
+	"lead":   "liderança",
+}
+
+var workModeLabels = map[string]string{
+	"onsite": "presencial",
+	"hybrid": "híbrida",
+	"remote": "remota",
+}
+
+// matchCandidate reúne os dados do candidato usados no cálculo
+type matchCandidate struct {
+	ID          int
+	Name        string
+	Headline    string
+	Location    string
+	Preferences JobPreferences
+	Skills      map[string]string // slug -> nome
+	// Meses de experiência, sem contar duas vezes períodos sobrepostos
+	ExperienceMonths int
+}
+
+// replaceJobSkills substitui as habilidades da vaga, já com os nomes
+// canônicos da taxonomia. Uma habilidade informada nas duas listas fica como
+// obrigatória.
+func replaceJobSkills(q dbtx, jobID int, required, niceToHave []JobSkillRequest) error {
+	if _, err := q.Exec("DELETE FROM job_skills WHERE job_id = ?", jobID); err != nil {
+		return err
+	}
+
+	insert := func(skills []JobSkillRequest, isRequired bool) error {
+		for _, skill := range skills {
+			display, slug, err := canonicalSkill(q, skill.Name)
+			if err != nil {
+				return err
+			}
+			if slug == "" {
+				continue
+			}
+			_, err = q.Exec("INSERT OR IGNORE INTO job_skills (job_id, name, slug, required, level) VALUES (?, ?, ?, ?, ?)",
+				jobID, display, slug, isRequired, skill.Level)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := insert(required, true); err != nil {
+		return err
+	}
+	return insert(niceToHave, false)
+}
+
+func listJobSkills(jobID int) ([]JobSkill, error) {
+	skills, err := loadJobSkills("j.id = ?", jobID)
+	if err != nil {
+		return nil, err
+	}
+	return jobSkillsOrEmpty(skills[jobID]), nil
+}
+
+// jobSkillsOrEmpty evita "null" no JSON de vagas sem habilidades
+func jobSkillsOrEmpty(skills []JobSkill) []JobSkill {
+	if skills == nil {
+		return []JobSkill{}
+	}
+	return skills
+}
+
+// loadJobSkills carrega as habilidades das vagas que atendem à condição where,
+// que usa o alias "j" para a tabela jobs
+func loadJobSkills(where string, args ...interface{}) (map[int][]JobSkill, error) {
+	rows, err := db.Query(`
+		SELECT s.job_id, s.name, s.slug, s.required, s.level, COALESCE(k.category, '')
+		FROM job_skills s
+		JOIN jobs j ON s.job_id = j.id
+		LEFT JOIN skills k ON k.slug = s.slug
+		WHERE `+where+`
+		ORDER BY s.required DESC, s.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := map[int][]JobSkill{}
+	for rows.Next() {
+		var skill JobSkill
+		if err := rows.Scan(&skill.JobID, &skill.Name, &skill.Slug, &skill.Required, &skill.Level, &skill.Category); err != nil {
+			return nil, err
+		}
+		skills[skill.JobID] = append(skills[skill.JobID], skill)
+	}
+	return skills, rows.Err()
+}
+
+// loadMatchCandidates carrega perfil, preferências, habilidades e experiências
+// dos usuários que atendem à condição where, que usa o alias "u" para users
+func loadMatchCandidates(where string, args ...interface{}) ([]*matchCandidate, error) {
+	rows, err := db.Query(`
+		SELECT u.id, u.name, u.headline, u.location, u.desired_salary, u.seniority, u.work_mode, u.open_to_work
+		FROM users u
+		WHERE `+where+`
+		ORDER BY u.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*matchCandidate
+	byID := map[int]*matchCandidate{}
+	for rows.Next() {
+		cand := &matchCandidate{Skills: map[string]string{}}
+		err := rows.Scan(&cand.ID, &cand.Name, &cand.Headline, &cand.Location, &cand.Preferences.DesiredSalary,
+			&cand.Preferences.Seniority, &cand.Preferences.WorkMode, &cand.Preferences.OpenToWork)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		candidates = append(candidates, cand)
+		byID[cand.ID] = cand
+	}
+	rows.Close()
+
+	rows, err = db.Query(`
+		SELECT s.user_id, s.name, s.slug
+		FROM user_skills s
+		JOIN users u ON s.user_id = u.id
+		WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var userID int
+		var name, slug string
+		if err := rows.Scan(&userID, &name, &slug); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if cand := byID[userID]; cand != nil {
+			cand.Skills[slug] = name
+		}
+	}
+	rows.Close()
+
+	rows, err = db.Query(`
+		SELECT e.user_id, e.start_date, e.end_date, e.current
+		FROM experiences e
+		JOIN users u ON e.user_id = u.id
+		WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	periods := map[int][][2]time.Time{}
+	now := time.Now()
+	for rows.Next() {
+		var userID int
+		var start, end string
+		var current bool
+		if err := rows.Scan(&userID, &start, &end, &current); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		startDate, err := time.Parse(monthLayout, start)
+		if err != nil {
+			continue
+		}
+		endDate := now
+		if !current && end != "" {
+			if endDate, err = time.Parse(monthLayout, end); err != nil {
+				continue
+			}
+			// O mês de término conta como trabalhado
+			endDate = endDate.AddDate(0, 1, 0)
+		}
+		periods[userID] = append(periods[userID], [2]time.Time{startDate, endDate})
+	}
+	rows.Close()
+
+	for userID, list := range periods {
+		if cand := byID[userID]; cand != nil {
+			cand.ExperienceMonths = experienceMonths(list)
+		}
+	}
+	return candidates, nil
+}
+
+// experienceMonths soma os períodos, juntando os que se sobrepõem
+func experienceMonths(periods [][2]time.Time) int {
+	sort.Slice(periods, func(i, j int) bool { return periods[i][0].Before(periods[j][0]) })
+
+	var total time.Duration
+	var start, end time.Time
+	for i, p := range periods {
+		if i > 0 && !p[0].After(end) {
+			if p[1].After(end) {
+				end = p[1]
+			}
+			continue
+		}
+		if i > 0 {
+			total += end.Sub(start)
+		}
+		start, end = p[0], p[1]
+	}
+	if len(periods) > 0 {
+		total += end.Sub(start)
+	}
+	return int(total.Hours() / 24 / 30)
+}
+
+// estimatedSeniority deduz o nível pelo tempo de experiência quando o
+// candidato não informou um
+func estimatedSeniority(months int) string {
+	switch years := months / 12; {
+	case years < 2:
+		return "junior"
+	case years < 5:
+		return "mid"
+	case years < 8:
+		return "senior"
+	default:
+		return "lead"
+	}
+}
+
+func seniorityIndex(level string) int {
+	for i, l := range seniorityLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// cityOf extrai a cidade de textos como "São Paulo - SP" ou "Recife, PE"
+func cityOf(location string) string {
+	city := strings.ToLower(location)
+	if i := strings.IndexAny(city, ",/("); i >= 0 {
+		city = city[:i]
+	}
+	if i := strings.Index(city, " - "); i >= 0 {
+		city = city[:i]
+	}
+	return strings.Join(strings.Fields(city), " ")
+}
+
+// scoreMatch calcula a compatibilidade entre o candidato e a vaga
+func scoreMatch(cand *matchCandidate, job *Job, skills []JobSkill) MatchResult {
+	var reasons []MatchReason
+
+	// Habilidades obrigatórias e desejáveis
+	for _, required := range []bool{true, false} {
+		reason := MatchReason{Criterion: "nice_to_have_skills", Matched: []string{}, Missing: []string{}}
+		weight := float64(matchWeightNiceToHaveSkills)
+		kind := "desejáveis"
+		if required {
+			reason.Criterion = "required_skills"
+			weight = matchWeightRequiredSkills
+			kind = "obrigatórias"
+		}
+
+		total := 0
+		for _, skill := range skills {
+			if skill.Required != required {
+				continue
+			}
+			total++
+			if _, ok := cand.Skills[skill.Slug]; ok {
+				reason.Matched = append(reason.Matched, skill.Name)
+			} else {
+				reason.Missing = append(reason.Missing, skill.Name)
+			}
+		}
+		if total == 0 {
+			reason.Detail = fmt.Sprintf("A vaga não lista habilidades %s", kind)
+		} else {
+			reason.MaxPoints = weight
+			reason.Points = weight * float64(len(reason.Matched)) / float64(total)
+			reason.Detail = fmt.Sprintf("Possui %d de %d habilidades %s", len(reason.Matched), total, kind)
+		}
+		reasons = append(reasons, reason)
+	}
+
+	// Local e modelo de trabalho
+	location := MatchReason{Criterion: "location", MaxPoints: matchWeightLocation}
+	preferred := cand.Preferences.WorkMode
+	switch {
+	case job.WorkMode == "remote" && preferred == "onsite":
+		location.Points = matchWeightLocation / 2.0
+		location.Detail = "Vaga remota, mas o candidato prefere trabalho presencial"
+	case job.WorkMode == "remote":
+		location.Points = matchWeightLocation
+		location.Detail = "Vaga remota"
+	case cityOf(cand.Location) == "" || cityOf(job.Location) == "":
+		location.MaxPoints = 0
+		location.Detail = "Localização do candidato ou da vaga não informada"
+	case cityOf(cand.Location) != cityOf(job.Location):
+		location.Detail = fmt.Sprintf("Candidato em %s; vaga em %s", cand.Location, job.Location)
+		if mode := workModeLabels[job.WorkMode]; mode != "" {
+			location.Detail += fmt.Sprintf(" (%s)", mode)
+		}
+	case preferred == "remote":
+		location.Points = matchWeightLocation / 2.0
+		location.Detail = "Mesma cidade da vaga, mas o candidato prefere trabalho remoto"
+	default:
+		location.Points = matchWeightLocation
+		location.Detail = "Mesma cidade da vaga"
+	}
+	reasons = append(reasons, location)
+
+	// Pretensão salarial contra o teto informado na vaga. O valor não aparece
+	// na explicação, que também é mostrada à equipe de contratação.
+	salary := MatchReason{Criterion: "salary", MaxPoints: matchWeightSalary}
+	jobMax, ok := parseSalary(job.Salary)
+	desired := cand.Preferences.DesiredSalary
+	switch {
+	case !ok || desired == 0:
+		salary.MaxPoints = 0
+		salary.Detail = "Pretensão salarial ou salário da vaga não informado"
+	case desired <= jobMax:
+		salary.Points = matchWeightSalary
+		salary.Detail = "Pretensão salarial dentro da faixa da vaga"
+	case float64(desired) <= float64(jobMax)*1.2:
+		salary.Points = matchWeightSalary / 2.0
+		salary.Detail = "Pretensão salarial até 20% acima do teto da vaga"
+	default:
+		salary.Detail = "Pretensão salarial mais de 20% acima do teto da vaga"
+	}
+	reasons = append(reasons, salary)
+
+	// Nível de senioridade, informado pelo candidato ou estimado pela experiência
+	seniority := MatchReason{Criterion: "seniority", MaxPoints: matchWeightSeniority}
+	level := cand.Preferences.Seniority
+	source := "informado pelo candidato"
+	if level == "" && cand.ExperienceMonths > 0 {
+		level = estimatedSeniority(cand.ExperienceMonths)
+		source = fmt.Sprintf("estimado por %d ano(s) de experiência", cand.ExperienceMonths/12)
+	}
+	if job.Seniority == "" || level == "" {
+		seniority.MaxPoints = 0
+		seniority.Detail = "Nível da vaga ou do candidato não informado"
+	} else {
+		distance := seniorityIndex(level) - seniorityIndex(job.Seniority)
+		if distance < 0 {
+			distance = -distance
+		}
+		switch distance {
+		case 0:
+			seniority.Points = matchWeightSeniority
+		case 1:
+			seniority.Points = matchWeightSeniority / 2.0
+		}
+		seniority.Detail = fmt.Sprintf("Nível %s (%s); a vaga pede %s",
+			seniorityLabels[level], source, seniorityLabels[job.Seniority])
+	}
+	reasons = append(reasons, seniority)
+
+	var points, maxPoints float64
+	for i := range reasons {
+		reasons[i].Points = math.Round(reasons[i].Points*10) / 10
+		points += reasons[i].Points
+		maxPoints += reasons[i].MaxPoints
+	}
+	result := MatchResult{Reasons: reasons}
+	if maxPoints > 0 {
+		result.Score = int(math.Round(100 * points / maxPoints))
+	}
+	return result
+}
+
+// limitParam lê o parâmetro limit, com padrão e teto
+func limitParam(c *gin.Context, fallback, max int) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		return fallback
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+// getRecommendedJobsHandler ordena as vagas abertas pela compatibilidade com o
+// perfil do usuário logado. Vagas do próprio usuário, já candidatadas ou sem
+// nenhuma compatibilidade ficam de fora.
+func getRecommendedJobsHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	candidates, err := loadMatchCandidates("u.id = ?", userID)
+	if err != nil || len(candidates) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar recomendações"})
+		return
+	}
+	cand := candidates[0]
+
+	where := `j.deleted_at IS NULL AND j.user_id != ? AND (j.closes_at IS NULL OR j.closes_at > ?)
+		AND NOT EXISTS (SELECT 1 FROM applications a WHERE a.job_id = j.id AND a.user_id = ?)`
+	args := []interface{}{userID, time.Now().UTC(), userID}
+
+	rows, err := db.Query(`
+		SELECT j.id, j.title, j.company, j.location, j.salary, j.type, j.work_mode, j.seniority, j.closes_at, j.created_at
+		FROM jobs j
+		WHERE `+where, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar recomendações"})
+		return
+	}
+	var jobs []Job
+	for rows.Next() {
+		var job Job
+		var closesAt sql.NullTime
+		err := rows.Scan(&job.ID, &job.Title, &job.Company, &job.Location, &job.Salary, &job.Type,
+			&job.WorkMode, &job.Seniority, &closesAt, &job.CreatedAt)
+		if err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar recomendações"})
+			return
+		}
+		if closesAt.Valid {
+			job.ClosesAt = &closesAt.Time
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+
+	skills, err := loadJobSkills(where, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar recomendações"})
+		return
+	}
+
+	type scoredJob struct {
+		job   Job
+		match MatchResult
+	}
+	var scored []scoredJob
+	for _, job := range jobs {
+		match := scoreMatch(cand, &job, skills[job.ID])
+		if match.Score > 0 {
+			scored = append(scored, scoredJob{job, match})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].match.Score != scored[j].match.Score {
+			return scored[i].match.Score > scored[j].match.Score
+		}
+		return scored[i].job.CreatedAt.After(scored[j].job.CreatedAt)
+	})
+	if limit := limitParam(c, 20, 50); len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	result := []gin.H{}
+	for _, s := range scored {
+		result = append(result, gin.H{
+			"id":         s.job.ID,
+			"title":      s.job.Title,
+			"company":    s.job.Company,
+			"location":   s.job.Location,
+			"salary":     s.job.Salary,
+			"type":       s.job.Type,
+			"work_mode":  s.job.WorkMode,
+			"seniority":  s.job.Seniority,
+			"closes_at":  s.job.ClosesAt,
+			"created_at": s.job.CreatedAt,
+			"match":      s.match,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": result})
+}
+
+// getSuggestedCandidatesHandler ordena pela compatibilidade com a vaga quem se
+// candidatou a ela e quem marcou no perfil que está aberto a oportunidades.
+// Membros da equipe de contratação não aparecem.
+func getSuggestedCandidatesHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if !requireHiringTeam(c, jobID) {
+		return
+	}
+
+	var job Job
+	err = db.QueryRow("SELECT id, location, salary, work_mode, seniority FROM jobs WHERE id = ?", jobID).Scan(
+		&job.ID, &job.Location, &job.Salary, &job.WorkMode, &job.Seniority)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
+		return
+	}
+
+	skills, err := listJobSkills(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar candidatos"})
+		return
+	}
+
+	candidates, err := loadMatchCandidates(`u.open_to_work = 1 OR u.id IN (
+		SELECT user_id FROM applications WHERE job_id = ? AND status != 'withdrawn')`, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar candidatos"})
+		return
+	}
+
+	type application struct {
+		id     int
+		status string
+	}
+	applications := map[int]application{}
+	rows, err := db.Query("SELECT id, user_id, status FROM applications WHERE job_id = ? AND status != 'withdrawn'", jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar candidatos"})
+		return
+	}
+	for rows.Next() {
+		var app application
+		var userID int
+		if err := rows.Scan(&app.id, &userID, &app.status); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar candidatos"})
+			return
+		}
+		applications[userID] = app
+	}
+	rows.Close()
+
+	team, err := hiringTeamIDs(db, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar candidatos"})
+		return
+	}
+	onTeam := map[int]bool{}
+	for _, id := range team {
+		onTeam[id] = true
+	}
+
+	type scoredCandidate struct {
+		cand  *matchCandidate
+		match MatchResult
+	}
+	var scored []scoredCandidate
+	for _, cand := range candidates {
+		if onTeam[cand.ID] {
+			continue
+		}
+		scored = append(scored, scoredCandidate{cand, scoreMatch(cand, &job, skills)})
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].match.Score > scored[j].match.Score })
+	if limit := limitParam(c, 20, 100); len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	result := []gin.H{}
+	for _, s := range scored {
+		entry := gin.H{
+			"user_id":        s.cand.ID,
+			"name":           s.cand.Name,
+			"headline":       s.cand.Headline,
+			"location":       s.cand.Location,
+			"applied":        false,
+			"application_id": nil,
+			"status":         nil,
+			"match":          s.match,
+		}
+		if app, ok := applications[s.cand.ID]; ok {
+			entry["applied"] = true
+			entry["application_id"] = app.id
+			entry["status"] = app.status
+		}
+		result = append(result, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"candidates": result})
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestExperienceMonths(t *testing.T) {
+	tests := []struct {
+		name    string
+		periods [][2]time.Time
+		want    int
+	}{
+		{"sem experiência", nil, 0},
+		{"um ano", [][2]time.Time{{day("2020-01-01"), day("2021-01-01")}}, 12},
+		{
+			"períodos separados",
+			[][2]time.Time{{day("2018-01-01"), day("2019-01-01")}, {day("2020-01-01"), day("2020-07-01")}},
+			18,
+		},
+		{
+			"sobrepostos contam uma vez",
+			[][2]time.Time{{day("2020-01-01"), day("2021-01-01")}, {day("2020-07-01"), day("2021-07-01")}},
+			18,
+		},
+		{
+			"contido em outro",
+			[][2]time.Time{{day("2015-01-01"), day("2020-01-01")}, {day("2016-01-01"), day("2017-01-01")}},
+			60,
+		},
+		{
+			"fora de ordem e encostados",
+			[][2]time.Time{{day("2021-01-01"), day("2022-01-01")}, {day("2020-01-01"), day("2021-01-01")}},
+			24,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := experienceMonths(tt.periods); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimatedSeniority(t *testing.T) {
+	tests := []struct {
+		months int
+		want   string
+	}{
+		{0, "junior"}, {23, "junior"}, {24, "mid"}, {59, "mid"}, {60, "senior"}, {95, "senior"}, {96, "lead"},
+	}
+	for _, tt := range tests {
+		if got := estimatedSeniority(tt.months); got != tt.want {
+			t.Errorf("estimatedSeniority(%d) = %s, want %s", tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestCityOf(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"São Paulo - SP", "são paulo"},
+		{"São  Paulo, SP", "são paulo"},
+		{"Recife/PE", "recife"},
+		{"Belo Horizonte (MG)", "belo horizonte"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := cityOf(tt.in); got != tt.want {
+			t.Errorf("cityOf(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScoreMatch(t *testing.T) {
+	skills := []JobSkill{
+		{Name: "Go", Slug: "go", Required: true},
+		{Name: "SQL", Slug: "sql", Required: true},
+		{Name: "Docker", Slug: "docker"},
+		{Name: "Kubernetes", Slug: "kubernetes"},
+		{Name: "AWS", Slug: "aws"},
+	}
+	job := &Job{Location: "São Paulo, SP", Salary: "R$ 6.000 - 10.000", WorkMode: "onsite", Seniority: "senior"}
+
+	tests := []struct {
+		name       string
+		cand       matchCandidate
+		job        *Job
+		skills     []JobSkill
+		wantScore  int
+		wantPoints map[string]float64
+		// Critérios sem dados suficientes ficam fora do cálculo
+		notConsidered []string
+	}{
+		{
+			name: "compatível em tudo",
+			cand: matchCandidate{
+				Location:    "São Paulo - SP",
+				Skills:      map[string]string{"go": "Go", "sql": "SQL", "docker": "Docker", "kubernetes": "Kubernetes", "aws": "AWS"},
+				Preferences: JobPreferences{DesiredSalary: 8000, Seniority: "senior"},
+			},
+			job: job, skills: skills,
+			wantScore:  100,
+			wantPoints: map[string]float64{"required_skills": 40, "nice_to_have_skills": 10, "location": 20, "salary": 15, "seniority": 15},
+		},
+		{
+			name: "parcial",
+			cand: matchCandidate{
+				Location:         "Recife, PE",
+				Skills:           map[string]string{"go": "Go", "docker": "Docker"},
+				Preferences:      JobPreferences{DesiredSalary: 11500},
+				ExperienceMonths: 36,
+			},
+			job: job, skills: skills,
+			// 20 + 3,3 + 0 + 7,5 + 7,5 = 38,3 de 100
+			wantScore:  38,
+			wantPoints: map[string]float64{"required_skills": 20, "nice_to_have_skills": 3.3, "location": 0, "salary": 7.5, "seniority": 7.5},
+		},
+		{
+			name: "pretensão muito acima e nível distante",
+			cand: matchCandidate{
+				Location:    "São Paulo",
+				Preferences: JobPreferences{DesiredSalary: 20000, Seniority: "junior"},
+			},
+			job: &Job{Location: "São Paulo", Salary: "até 10k", Seniority: "lead"},
+			// 20 de 50: sem habilidades na vaga, só local, salário e nível contam
+			wantScore:  40,
+			wantPoints: map[string]float64{"location": 20, "salary": 0, "seniority": 0},
+		},
+		{
+			name:          "vaga remota, candidato prefere presencial",
+			cand:          matchCandidate{Preferences: JobPreferences{WorkMode: "onsite"}},
+			job:           &Job{WorkMode: "remote"},
+			wantScore:     50,
+			wantPoints:    map[string]float64{"location": 10},
+			notConsidered: []string{"required_skills", "nice_to_have_skills", "salary", "seniority"},
+		},
+		{
+			name:       "mesma cidade, candidato prefere remoto",
+			cand:       matchCandidate{Location: "Recife", Preferences: JobPreferences{WorkMode: "remote"}},
+			job:        &Job{Location: "Recife - PE", WorkMode: "hybrid"},
+			wantScore:  50,
+			wantPoints: map[string]float64{"location": 10},
+		},
+		{
+			name:          "sem dados",
+			cand:          matchCandidate{},
+			job:           &Job{},
+			wantScore:     0,
+			notConsidered: []string{"required_skills", "nice_to_have_skills", "location", "salary", "seniority"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scoreMatch(&tt.cand, tt.job, tt.skills)
+			if result.Score != tt.wantScore {
+				t.Errorf("score = %d, want %d (%+v)", result.Score, tt.wantScore, result.Reasons)
+			}
+
+			byCriterion := map[string]MatchReason{}
+			for _, r := range result.Reasons {
+				byCriterion[r.Criterion] = r
+			}
+			if len(byCriterion) != 5 {
+				t.Errorf("esperava 5 critérios, obteve %d", len(byCriterion))
+			}
+			for criterion, want := range tt.wantPoints {
+				if got := byCriterion[criterion]; got.Points != want || got.MaxPoints == 0 {
+					t.Errorf("%s = %v de %v, want %v", criterion, got.Points, got.MaxPoints, want)
+				}
+			}
+			for _, criterion := range tt.notConsidered {
+				if got := byCriterion[criterion]; got.MaxPoints != 0 || got.Points != 0 {
+					t.Errorf("%s deveria ficar fora do cálculo: %+v", criterion, got)
+				}
+			}
+		})
+	}
+}
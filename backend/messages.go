@@ -0,0 +1,377 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultRejectionSubject = "Sua candidatura para {{job_title}}"
+
+const defaultRejectionBody = `Olá, {{candidate_name}}.
+
+Agradecemos seu interesse na vaga de {{job_title}} na {{company}} e o tempo
+dedicado ao processo seletivo.
+
+Depois de avaliar as candidaturas, decidimos seguir com outros candidatos neste
+momento. Seu perfil continua em nosso banco de talentos para futuras
+oportunidades.
+
+Desejamos sucesso na sua carreira.`
+
+// Campos disponíveis nos modelos de mensagem
+var messagePlaceholders = []string{"candidate_name", "job_title", "company"}
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// fillPlaceholders substitui os campos {{campo}} conhecidos; os demais ficam
+// como estão, para que erros de digitação apareçam no texto revisado
+func fillPlaceholders(text string, values map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		key := templatePlaceholder.FindStringSubmatch(match)[1]
+		if value, ok := values[key]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// composeCandidateMessage preenche o modelo escolhido (ou o padrão) com os
+// dados da candidatura
+func composeCandidateMessage(templateID *int, userID int, defaultSubject, defaultBody string, ctx *interviewContext) (string, string, error) {
+	subject, body := defaultSubject, defaultBody
+	if templateID != nil {
+		err := db.QueryRow("SELECT subject, body FROM message_templates WHERE id = ? AND user_id = ?", *templateID, userID).Scan(&subject, &body)
+		if err != nil {
+			return "", "", errors.New("Modelo de mensagem não encontrado")
+		}
+	}
+
+	values := map[string]string{
+		"candidate_name": ctx.Candidate.Name,
+		"job_title":      ctx.JobTitle,
+		"company":        ctx.Company,
+	}
+	return fillPlaceholders(subject, values), fillPlaceholders(body, values), nil
+}
+
+// messageDueNow indica se a mensagem deve sair já, sem esperar a próxima
+// varredura agendada
+func messageDueNow(m *CandidateMessage) bool {
+	return m != nil && !m.SendAt.After(time.Now())
+}
+
+func insertCandidateMessage(q dbtx, m *CandidateMessage) error {
+	result, err := q.Exec(`
+		INSERT INTO candidate_messages (application_id, kind, subject, body, send_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, m.ApplicationID, m.Kind, m.Subject, m.Body, m.SendAt, m.CreatedBy, m.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, _ := result.LastInsertId()
+	m.ID = int(id)
+	return nil
+}
+
+func listCandidateMessages(where string, args ...interface{}) ([]CandidateMessage, error) {
+	rows, err := db.Query(`
+		SELECT id, application_id, kind, subject, body, send_at, sent_at, cancelled_at, created_by, created_at
+		FROM candidate_messages
+		WHERE `+where+`
+		ORDER BY send_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []CandidateMessage{}
+	for rows.Next() {
+		var m CandidateMessage
+		var sentAt, cancelledAt sql.NullTime
+		if err := rows.Scan(&m.ID, &m.ApplicationID, &m.Kind, &m.Subject, &m.Body, &m.SendAt,
+			&sentAt, &cancelledAt, &m.CreatedBy, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		if sentAt.Valid {
+			m.SentAt = &sentAt.Time
+		}
+		if cancelledAt.Valid {
+			m.CancelledAt = &cancelledAt.Time
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// Evita que o envio periódico e o disparado por uma requisição rodem juntos
+var candidateMessageMu sync.Mutex
+
+// sendDueCandidateMessages envia as mensagens cujo horário chegou. Mensagens
+// de reprovação de candidaturas que deixaram de estar reprovadas são canceladas.
+func sendDueCandidateMessages() error {
+	candidateMessageMu.Lock()
+	defer candidateMessageMu.Unlock()
+
+	due, err := listCandidateMessages("sent_at IS NULL AND cancelled_at IS NULL AND send_at <= ?", time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for _, m := range due {
+		ctx, err := loadInterviewContext(m.ApplicationID)
+		if err != nil {
+			log.Printf("Erro ao carregar candidatura da mensagem %d: %v", m.ID, err)
+			continue
+		}
+
+		if m.Kind == "rejection" && ctx.Status != "rejected" {
+			db.Exec("UPDATE candidate_messages SET cancelled_at = ? WHERE id = ? AND sent_at IS NULL", time.Now(), m.ID)
+			continue
+		}
+
+		// A mensagem é marcada como enviada na mesma transação em que o email
+		// entra na fila, para não ser enviada duas vezes nem se perder
+		err = withTx(func(tx *sql.Tx) error {
+			result, err := tx.Exec(`
+				UPDATE candidate_messages SET sent_at = ?
+				WHERE id = ? AND sent_at IS NULL AND cancelled_at IS NULL`, time.Now(), m.ID)
+			if err != nil {
+				return err
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				return sql.ErrNoRows
+			}
+			publishEvent(tx, "message.created", gin.H{
+				"application_id": m.ApplicationID,
+				"message_id":     m.ID,
+				"subject":        m.Subject,
+				"kind":           m.Kind,
+			}, ctx.Candidate.UserID)
+			if err := createNotification(tx, ctx.Candidate.UserID, "candidate_message", m.Subject, m.ApplicationID); err != nil {
+				return err
+			}
+			return enqueueEmail(tx, Email{To: []string{ctx.Candidate.Email}, Subject: m.Subject, Body: m.Body})
+		})
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("Erro ao enviar mensagem %d: %v", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// runCandidateMessages é a tarefa periódica da fila que envia as mensagens agendadas
+func runCandidateMessages(payload []byte) error {
+	return sendDueCandidateMessages()
+}
+
+// getApplicationMessagesHandler lista as mensagens da candidatura. O candidato
+// vê apenas as já enviadas; a equipe vê também as agendadas e canceladas.
+func getApplicationMessagesHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	ctx, err := loadInterviewContext(appID)
+	if err != nil || (ctx.Candidate.UserID != userID && !isHiringSide(ctx.JobID, userID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	where := "application_id = ?"
+	if ctx.Candidate.UserID == userID {
+		where += " AND sent_at IS NOT NULL"
+	}
+
+	messages, err := listCandidateMessages(where, appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar mensagens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
+
+// cancelCandidateMessageHandler cancela uma mensagem ainda não enviada
+func cancelCandidateMessageHandler(c *gin.Context) {
+	messageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var appID int
+	if err := db.QueryRow("SELECT application_id FROM candidate_messages WHERE id = ?", messageID).Scan(&appID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mensagem não encontrada"})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, appID); !ok {
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE candidate_messages SET cancelled_at = ?
+		WHERE id = ? AND sent_at IS NULL AND cancelled_at IS NULL`, time.Now(), messageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao cancelar mensagem"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A mensagem já foi enviada ou cancelada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mensagem cancelada com sucesso"})
+}
+
+func getMessageTemplatesHandler(c *gin.Context) {
+	rows, err := db.Query(`
+		SELECT id, user_id, name, subject, body, created_at, updated_at
+		FROM message_templates WHERE user_id = ?
+		ORDER BY name`, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar modelos"})
+		return
+	}
+	defer rows.Close()
+
+	templates := []MessageTemplate{}
+	for rows.Next() {
+		var t MessageTemplate
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Subject, &t.Body, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler modelos"})
+			return
+		}
+		templates = append(templates, t)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates":    templates,
+		"placeholders": messagePlaceholders,
+		"default_rejection": gin.H{
+			"subject": defaultRejectionSubject,
+			"body":    defaultRejectionBody,
+		},
+	})
+}
+
+// validateMessageTemplate recusa campos que não existem, que seriam enviados
+// ao candidato sem substituição
+func validateMessageTemplate(req *MessageTemplateRequest) error {
+	known := map[string]bool{}
+	for _, p := range messagePlaceholders {
+		known[p] = true
+	}
+	for _, m := range templatePlaceholder.FindAllStringSubmatch(req.Subject+"\n"+req.Body, -1) {
+		if !known[m[1]] {
+			return fmt.Errorf("Campo desconhecido no modelo: {{%s}}", m[1])
+		}
+	}
+	return nil
+}
+
+func createMessageTemplateHandler(c *gin.Context) {
+	var req MessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateMessageTemplate(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	t := MessageTemplate{
+		UserID:    c.GetInt("user_id"),
+		Name:      strings.TrimSpace(req.Name),
+		Subject:   strings.TrimSpace(req.Subject),
+		Body:      req.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO message_templates (user_id, name, subject, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, t.UserID, t.Name, t.Subject, t.Body, now, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar modelo"})
+		return
+	}
+	id, _ := result.LastInsertId()
+	t.ID = int(id)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Modelo criado com sucesso",
+		"template": t,
+	})
+}
+
+func updateMessageTemplateHandler(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req MessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateMessageTemplate(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE message_templates SET name = ?, subject = ?, body = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?`, strings.TrimSpace(req.Name), strings.TrimSpace(req.Subject), req.Body,
+		time.Now(), templateID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar modelo"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Modelo não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Modelo atualizado com sucesso"})
+}
+
+// deleteMessageTemplateHandler exclui o modelo. Mensagens já agendadas
+// guardam o texto final e não são afetadas.
+func deleteMessageTemplateHandler(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM message_templates WHERE id = ? AND user_id = ?", templateID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir modelo"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Modelo não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Modelo excluído com sucesso"})
+}
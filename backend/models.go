@@ -9,32 +9,565 @@ type User struct {
 	Email     string    `json:"email" db:"email"`
 	Password  string    `json:"-" db:"password"`
 	Name      string    `json:"name" db:"name"`
+	Role      string    `json:"role" db:"role"` // user, admin
+	Headline  string    `json:"headline" db:"headline"`
+	Summary   string    `json:"summary" db:"summary"`
+	Location  string    `json:"location" db:"location"`
+	Phone     string    `json:"phone" db:"phone"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
-type Job struct {
+// JobPreferences são as preferências do candidato usadas na recomendação de
+// vagas. Campos vazios não entram no cálculo.
+type JobPreferences struct {
+	DesiredSalary int    `json:"desired_salary" db:"desired_salary"` // pretensão mensal
+	Seniority     string `json:"seniority" db:"seniority"`           // junior, mid, senior, lead
+	WorkMode      string `json:"work_mode" db:"work_mode"`           // onsite, hybrid, remote
+	OpenToWork    bool   `json:"open_to_work" db:"open_to_work"`     // aparece nas sugestões de todas as vagas
+}
+
+type ProfileLink struct {
+	ID     int    `json:"id" db:"id"`
+	UserID int    `json:"user_id" db:"user_id"`
+	Label  string `json:"label" db:"label"`
+	URL    string `json:"url" db:"url"`
+}
+
+type Experience struct {
 	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
 	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
 	Company     string    `json:"company" db:"company"`
 	Location    string    `json:"location" db:"location"`
-	Salary      string    `json:"salary" db:"salary"`
-	Type        string    `json:"type" db:"type"` // full-time, part-time, contract
-	UserID      int       `json:"user_id" db:"user_id"`
+	StartDate   string    `json:"start_date" db:"start_date"` // AAAA-MM
+	EndDate     string    `json:"end_date" db:"end_date"`     // vazio quando atual
+	Current     bool      `json:"current" db:"current"`
+	Description string    `json:"description" db:"description"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
-type Application struct {
+type Education struct {
+	ID           int       `json:"id" db:"id"`
+	UserID       int       `json:"user_id" db:"user_id"`
+	Institution  string    `json:"institution" db:"institution"`
+	Degree       string    `json:"degree" db:"degree"`
+	FieldOfStudy string    `json:"field_of_study" db:"field_of_study"`
+	StartDate    string    `json:"start_date" db:"start_date"` // AAAA-MM
+	EndDate      string    `json:"end_date" db:"end_date"`
+	Description  string    `json:"description" db:"description"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UserSkill struct {
+	UserID int    `json:"user_id" db:"user_id"`
+	Name   string `json:"name" db:"name"`
+	Slug   string `json:"slug" db:"slug"`
+}
+
+// JobSkill é uma habilidade pedida pela vaga, obrigatória ou desejável
+type JobSkill struct {
+	JobID    int    `json:"job_id" db:"job_id"`
+	Name     string `json:"name" db:"name"`
+	Slug     string `json:"slug" db:"slug"`
+	Required bool   `json:"required" db:"required"`
+	Level    string `json:"level" db:"level"`       // basic, intermediate, advanced ou vazio
+	Category string `json:"category" db:"category"` // categoria na taxonomia, se cadastrada
+}
+
+// Skill é uma habilidade da taxonomia, com nome canônico e sinônimos. Nomes e
+// sinônimos informados em vagas e perfis são gravados com o nome canônico.
+type Skill struct {
 	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Slug      string    `json:"slug" db:"slug"`
+	Category  string    `json:"category" db:"category"`
+	Aliases   []string  `json:"aliases" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MatchResult é a compatibilidade entre um candidato e uma vaga, de 0 a 100,
+// com a explicação de cada critério
+type MatchResult struct {
+	Score   int           `json:"score"`
+	Reasons []MatchReason `json:"reasons"`
+}
+
+// MatchReason explica um critério do cálculo. MaxPoints zero indica que o
+// critério não foi considerado por falta de dados.
+type MatchReason struct {
+	Criterion string   `json:"criterion"` // required_skills, nice_to_have_skills, location, salary, seniority
+	Points    float64  `json:"points"`
+	MaxPoints float64  `json:"max_points"`
+	Detail    string   `json:"detail"`
+	Matched   []string `json:"matched,omitempty"`
+	Missing   []string `json:"missing,omitempty"`
+}
+
+type Job struct {
+	ID          int        `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title"`
+	Description string     `json:"description" db:"description"`
+	Company     string     `json:"company" db:"company"`
+	Location    string     `json:"location" db:"location"`
+	Salary      string     `json:"salary" db:"salary"`
+	Type        string     `json:"type" db:"type"`           // full-time, part-time, contract
+	WorkMode    string     `json:"work_mode" db:"work_mode"` // onsite, hybrid, remote
+	Seniority   string     `json:"seniority" db:"seniority"` // junior, mid, senior, lead
+	UserID      int        `json:"user_id" db:"user_id"`
+	ClosesAt    *time.Time `json:"closes_at" db:"closes_at"`
+	DeletedAt   *time.Time `json:"deleted_at" db:"deleted_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type Application struct {
+	ID               int        `json:"id" db:"id"`
+	JobID            int        `json:"job_id" db:"job_id"`
+	UserID           int        `json:"user_id" db:"user_id"`
+	Status           string     `json:"status" db:"status"` // pending, accepted, rejected, withdrawn, hired
+	CoverLetter      string     `json:"cover_letter" db:"cover_letter"`
+	WithdrawnAt      *time.Time `json:"withdrawn_at" db:"withdrawn_at"`
+	WithdrawalReason string     `json:"withdrawal_reason" db:"withdrawal_reason"`
+	JobClosedAt      *time.Time `json:"job_closed_at" db:"job_closed_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ApplicationStatusChange registra cada mudança de status para o histórico
+type ApplicationStatusChange struct {
+	ID            int       `json:"id" db:"id"`
+	ApplicationID int       `json:"application_id" db:"application_id"`
+	FromStatus    string    `json:"from_status" db:"from_status"`
+	ToStatus      string    `json:"to_status" db:"to_status"`
+	Reason        string    `json:"reason" db:"reason"`
+	ChangedBy     int       `json:"changed_by" db:"changed_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// JobTeamMember é um usuário convidado pelo dono da vaga para a equipe de
+// contratação. O dono aparece na listagem com o papel "owner".
+type JobTeamMember struct {
 	JobID     int       `json:"job_id" db:"job_id"`
 	UserID    int       `json:"user_id" db:"user_id"`
-	Status    string    `json:"status" db:"status"` // pending, accepted, rejected
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	Role      string    `json:"role" db:"role"` // owner, recruiter, hiring_manager, interviewer
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ApplicationNote é uma nota interna da equipe de contratação, em Markdown
+type ApplicationNote struct {
+	ID            int           `json:"id" db:"id"`
+	ApplicationID int           `json:"application_id" db:"application_id"`
+	AuthorID      int           `json:"author_id" db:"author_id"`
+	AuthorName    string        `json:"author_name" db:"author_name"`
+	Body          string        `json:"body" db:"body"`
+	BodyHTML      string        `json:"body_html" db:"-"`
+	Mentions      []NoteMention `json:"mentions" db:"-"`
+	EditedAt      *time.Time    `json:"edited_at" db:"edited_at"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+}
+
+// NoteMention é um membro da equipe mencionado com @email em uma nota
+type NoteMention struct {
+	UserID int    `json:"user_id" db:"user_id"`
+	Name   string `json:"name" db:"name"`
+	Email  string `json:"email" db:"email"`
+}
+
+// ScorecardCriterion é um critério do modelo de avaliação da vaga, avaliado
+// de 1 até ScaleMax
+type ScorecardCriterion struct {
+	ID          int    `json:"id" db:"id"`
+	JobID       int    `json:"job_id" db:"job_id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	ScaleMax    int    `json:"scale_max" db:"scale_max"`
+	Position    int    `json:"position" db:"position"`
+}
+
+// Scorecard é a avaliação de um entrevistador sobre uma candidatura
+type Scorecard struct {
+	ID              int               `json:"id" db:"id"`
+	ApplicationID   int               `json:"application_id" db:"application_id"`
+	InterviewerID   int               `json:"interviewer_id" db:"interviewer_id"`
+	InterviewerName string            `json:"interviewer_name" db:"interviewer_name"`
+	Recommendation  string            `json:"recommendation" db:"recommendation"` // strong_yes, yes, no, strong_no
+	Comments        string            `json:"comments" db:"comments"`
+	Status          string            `json:"status" db:"status"` // draft, submitted
+	Ratings         []ScorecardRating `json:"ratings" db:"-"`
+	SubmittedAt     *time.Time        `json:"submitted_at" db:"submitted_at"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ScorecardRating guarda a nota de um critério, com cópia do nome e da escala
+// para que mudanças no modelo não alterem avaliações já enviadas
+type ScorecardRating struct {
+	CriterionID int    `json:"criterion_id" db:"criterion_id"`
+	Criterion   string `json:"criterion" db:"criterion"`
+	Rating      int    `json:"rating" db:"rating"`
+	ScaleMax    int    `json:"scale_max" db:"scale_max"`
+	Comment     string `json:"comment" db:"comment"`
+}
+
+// ScorecardSummary agrega os scorecards enviados de uma candidatura. A nota
+// geral vai de 0 a 100, normalizando as escalas de cada critério.
+type ScorecardSummary struct {
+	SubmittedCount  int                `json:"submitted_count"`
+	OverallScore    *float64           `json:"overall_score"`
+	Recommendation  string             `json:"recommendation"` // strong_yes, yes, mixed, no, strong_no
+	Recommendations map[string]int     `json:"recommendations"`
+	Criteria        []CriterionAverage `json:"criteria"`
+}
+
+type CriterionAverage struct {
+	CriterionID int     `json:"criterion_id"`
+	Criterion   string  `json:"criterion"`
+	Average     float64 `json:"average"`
+	ScaleMax    int     `json:"scale_max"`
+	Count       int     `json:"count"`
+}
+
+// Interview é uma entrevista de uma candidatura. Os horários são gravados em
+// UTC; TimeZone guarda o fuso em que a entrevista foi marcada.
+type Interview struct {
+	ID            int                    `json:"id" db:"id"`
+	ApplicationID int                    `json:"application_id" db:"application_id"`
+	Type          string                 `json:"type" db:"type"` // phone, video, onsite, technical
+	StartsAt      time.Time              `json:"starts_at" db:"starts_at"`
+	EndsAt        time.Time              `json:"ends_at" db:"ends_at"`
+	TimeZone      string                 `json:"time_zone" db:"time_zone"`
+	Location      string                 `json:"location" db:"location"`
+	VideoLink     string                 `json:"video_link" db:"video_link"`
+	Status        string                 `json:"status" db:"status"` // scheduled, cancelled
+	Sequence      int                    `json:"sequence" db:"sequence"`
+	UID           string                 `json:"-" db:"uid"`
+	Interviewers  []InterviewParticipant `json:"interviewers" db:"-"`
+	CreatedBy     int                    `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+type InterviewParticipant struct {
+	UserID int    `json:"user_id" db:"user_id"`
+	Name   string `json:"name" db:"name"`
+	Email  string `json:"email" db:"email"`
+}
+
+// InterviewConflict aponta outra entrevista do entrevistador no mesmo horário
+type InterviewConflict struct {
+	InterviewerID int       `json:"interviewer_id"`
+	Interviewer   string    `json:"interviewer"`
+	InterviewID   int       `json:"interview_id"`
+	StartsAt      time.Time `json:"starts_at"`
+	EndsAt        time.Time `json:"ends_at"`
+}
+
+// AvailabilityWindow é um intervalo semanal de disponibilidade do entrevistador.
+// Weekday segue time.Weekday (0 = domingo) e os horários estão em TimeZone.
+type AvailabilityWindow struct {
+	ID        int    `json:"id" db:"id"`
+	UserID    int    `json:"user_id" db:"user_id"`
+	Weekday   int    `json:"weekday" db:"weekday"`
+	StartTime string `json:"start_time" db:"start_time"` // HH:MM
+	EndTime   string `json:"end_time" db:"end_time"`     // HH:MM
+	TimeZone  string `json:"time_zone" db:"time_zone"`
+}
+
+// AvailabilityBlackout bloqueia dias inteiros (de StartsOn a EndsOn, inclusive)
+type AvailabilityBlackout struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	StartsOn  string    `json:"starts_on" db:"starts_on"`
+	EndsOn    string    `json:"ends_on" db:"ends_on"`
+	StartsAt  time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time `json:"ends_at" db:"ends_at"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type AvailableSlot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Local    string    `json:"local"` // início no fuso solicitado
+}
+
+// SchedulingLink permite que o candidato escolha o horário da entrevista
+type SchedulingLink struct {
+	ID              int                    `json:"id" db:"id"`
+	ApplicationID   int                    `json:"application_id" db:"application_id"`
+	Type            string                 `json:"type" db:"type"`
+	DurationMinutes int                    `json:"duration_minutes" db:"duration_minutes"`
+	TimeZone        string                 `json:"time_zone" db:"time_zone"`
+	Location        string                 `json:"location" db:"location"`
+	VideoLink       string                 `json:"video_link" db:"video_link"`
+	WindowStart     time.Time              `json:"window_start" db:"window_start"`
+	WindowEnd       time.Time              `json:"window_end" db:"window_end"`
+	ExpiresAt       time.Time              `json:"expires_at" db:"expires_at"`
+	InterviewID     *int                   `json:"interview_id" db:"interview_id"`
+	RevokedAt       *time.Time             `json:"revoked_at" db:"revoked_at"`
+	Interviewers    []InterviewParticipant `json:"interviewers" db:"-"`
+	CreatedBy       int                    `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+}
+
+// Offer é a proposta feita ao candidato. Antes de ser enviada precisa passar
+// pela cadeia de aprovação configurada na vaga.
+type Offer struct {
+	ID            int             `json:"id" db:"id"`
+	ApplicationID int             `json:"application_id" db:"application_id"`
+	Status        string          `json:"status" db:"status"` // draft, pending_approval, approved, sent, accepted, declined, withdrawn, expired
+	Salary        float64         `json:"salary" db:"salary"`
+	Currency      string          `json:"currency" db:"currency"`
+	PayPeriod     string          `json:"pay_period" db:"pay_period"` // year, month, hour
+	Bonus         string          `json:"bonus" db:"bonus"`
+	StartDate     string          `json:"start_date" db:"start_date"`
+	ExpiresAt     time.Time       `json:"expires_at" db:"expires_at"`
+	Notes         string          `json:"notes,omitempty" db:"notes"`
+	TemplateID    *int            `json:"template_id,omitempty" db:"template_id"`
+	DeclineReason string          `json:"decline_reason" db:"decline_reason"`
+	Approvals     []OfferApproval `json:"approvals,omitempty" db:"-"`
+	CreatedBy     int             `json:"created_by" db:"created_by"`
+	SentAt        *time.Time      `json:"sent_at" db:"sent_at"`
+	RespondedAt   *time.Time      `json:"responded_at" db:"responded_at"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+type OfferApproval struct {
+	UserID    int        `json:"user_id" db:"user_id"`
+	Name      string     `json:"name" db:"name"`
+	Position  int        `json:"position" db:"position"`
+	Status    string     `json:"status" db:"status"` // pending, approved, rejected
+	Comment   string     `json:"comment" db:"comment"`
+	DecidedAt *time.Time `json:"decided_at" db:"decided_at"`
+}
+
+// OfferTemplate é um modelo de carta-proposta em Markdown com campos {{campo}}
+type OfferTemplate struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Body      string    `json:"body" db:"body"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// RejectionReason é um item do catálogo de motivos de reprovação
+type RejectionReason struct {
+	ID        int       `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"`
+	Label     string    `json:"label" db:"label"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// MessageTemplate é um modelo de mensagem ao candidato com campos {{campo}}
+type MessageTemplate struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Subject   string    `json:"subject" db:"subject"`
+	Body      string    `json:"body" db:"body"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CandidateMessage é uma mensagem enviada (ou agendada) ao candidato
+type CandidateMessage struct {
+	ID            int        `json:"id" db:"id"`
+	ApplicationID int        `json:"application_id" db:"application_id"`
+	Kind          string     `json:"kind" db:"kind"` // rejection, message
+	Subject       string     `json:"subject" db:"subject"`
+	Body          string     `json:"body" db:"body"`
+	SendAt        time.Time  `json:"send_at" db:"send_at"`
+	SentAt        *time.Time `json:"sent_at" db:"sent_at"`
+	CancelledAt   *time.Time `json:"cancelled_at" db:"cancelled_at"`
+	CreatedBy     int        `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ThreadMessage é uma mensagem da conversa entre o candidato e a equipe de
+// contratação, presa à candidatura
+type ThreadMessage struct {
+	ID            int                 `json:"id" db:"id"`
+	ApplicationID int                 `json:"application_id" db:"application_id"`
+	SenderID      int                 `json:"sender_id" db:"sender_id"`
+	SenderName    string              `json:"sender_name" db:"sender_name"`
+	Body          string              `json:"body" db:"body"`
+	Attachments   []ThreadAttachment  `json:"attachments"`
+	Receipts      []ThreadReadReceipt `json:"receipts"`
+	CreatedAt     time.Time           `json:"created_at" db:"created_at"`
+}
+
+type ThreadAttachment struct {
+	ID          int       `json:"id" db:"id"`
+	MessageID   int       `json:"message_id" db:"message_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// ThreadReadReceipt indica se cada destinatário já leu a mensagem
+type ThreadReadReceipt struct {
+	UserID int        `json:"user_id" db:"user_id"`
+	Name   string     `json:"name" db:"name"`
+	ReadAt *time.Time `json:"read_at" db:"read_at"`
+}
+
+// BulkItemResult é o resultado de uma ação em lote para cada candidatura
+type BulkItemResult struct {
+	ApplicationID int    `json:"application_id"`
+	Success       bool   `json:"success"`
+	Error         string `json:"error,omitempty"`
+}
+
+type Notification struct {
+	ID            int        `json:"id" db:"id"`
+	UserID        int        `json:"user_id" db:"user_id"`
+	Type          string     `json:"type" db:"type"`
+	Message       string     `json:"message" db:"message"`
+	ApplicationID *int       `json:"application_id" db:"application_id"`
+	ReadAt        *time.Time `json:"read_at" db:"read_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NotificationPreference define por quais canais o usuário recebe cada evento
+type NotificationPreference struct {
+	Event   string `json:"event" db:"event"`
+	Label   string `json:"label"`
+	InApp   bool   `json:"in_app" db:"in_app"`
+	Email   bool   `json:"email" db:"email"`
+	Webhook bool   `json:"webhook" db:"webhook"`
+}
+
+// NotificationSettings guarda as opções de entrega comuns a todos os eventos
+type NotificationSettings struct {
+	EmailDigest   string     `json:"email_digest" db:"email_digest"` // off, hourly, daily
+	WebhookURL    string     `json:"webhook_url" db:"webhook_url"`
+	WebhookSecret string     `json:"-" db:"webhook_secret"`
+	LastDigestAt  *time.Time `json:"last_digest_at" db:"last_digest_at"`
+}
+
+// WebhookEndpoint recebe eventos das vagas do dono (ou de todas, se all_jobs)
+type WebhookEndpoint struct {
+	ID                  int        `json:"id" db:"id"`
+	UserID              int        `json:"user_id" db:"user_id"`
+	URL                 string     `json:"url" db:"url"`
+	Secret              string     `json:"-" db:"secret"`
+	Events              []string   `json:"events" db:"events"`
+	AllJobs             bool       `json:"all_jobs" db:"all_jobs"`
+	Active              bool       `json:"active" db:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at" db:"disabled_at"`
+	DisabledReason      string     `json:"disabled_reason" db:"disabled_reason"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int        `json:"id" db:"id"`
+	EndpointID     int        `json:"endpoint_id" db:"endpoint_id"`
+	EventID        string     `json:"event_id" db:"event_id"`
+	EventType      string     `json:"event_type" db:"event_type"`
+	Payload        string     `json:"payload" db:"payload"`
+	Status         string     `json:"status" db:"status"` // pending, succeeded, failed
+	Attempts       int        `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code" db:"last_status_code"`
+	LastError      string     `json:"last_error" db:"last_error"`
+	ReplayOf       *int       `json:"replay_of" db:"replay_of"`
+	DeliveredAt    *time.Time `json:"delivered_at" db:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// JobAlert é uma busca salva; as vagas novas que a atendem são enviadas por
+// email no resumo diário ou semanal
+type JobAlert struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Keywords   string     `json:"keywords" db:"keywords"`
+	Location   string     `json:"location" db:"location"`
+	Type       string     `json:"type" db:"type"`
+	MinSalary  int        `json:"min_salary" db:"min_salary"`
+	Frequency  string     `json:"frequency" db:"frequency"` // daily, weekly
+	Active     bool       `json:"active" db:"active"`
+	LastRunAt  time.Time  `json:"last_run_at" db:"last_run_at"`
+	LastSentAt *time.Time `json:"last_sent_at" db:"last_sent_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// BackgroundJob é uma tarefa da fila executada em segundo plano
+type BackgroundJob struct {
+	ID             int        `json:"id" db:"id"`
+	Kind           string     `json:"kind" db:"kind"`
+	Payload        string     `json:"-" db:"payload"`     // pode conter tokens e dados pessoais; ver backgroundJobPayloadView
+	Status         string     `json:"status" db:"status"` // pending, running, succeeded, dead
+	Attempts       int        `json:"attempts" db:"attempts"`
+	MaxAttempts    int        `json:"max_attempts" db:"max_attempts"`
+	RunAt          time.Time  `json:"run_at" db:"run_at"`
+	LeaseOwner     string     `json:"lease_owner" db:"lease_owner"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at" db:"lease_expires_at"`
+	LastError      string     `json:"last_error" db:"last_error"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	FinishedAt     *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+type ScreeningQuestion struct {
+	ID             int       `json:"id" db:"id"`
+	JobID          int       `json:"job_id" db:"job_id"`
+	Question       string    `json:"question" db:"question"`
+	Type           string    `json:"type" db:"type"` // text, yes_no, single_choice, multi_choice, number
+	Options        []string  `json:"options" db:"options"`
+	Required       bool      `json:"required" db:"required"`
+	KnockoutValues []string  `json:"knockout_values,omitempty" db:"knockout_values"`
+	MinValue       *float64  `json:"min_value,omitempty" db:"min_value"`
+	MaxValue       *float64  `json:"max_value,omitempty" db:"max_value"`
+	Position       int       `json:"position" db:"position"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type ScreeningAnswer struct {
+	QuestionID int         `json:"question_id" db:"question_id"`
+	Question   string      `json:"question,omitempty" db:"question"`
+	Value      interface{} `json:"value" db:"value"`
+	Knockout   bool        `json:"knockout,omitempty" db:"knockout"` // visível apenas à equipe de contratação
+}
+
+type Resume struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	Checksum    string    `json:"checksum" db:"checksum"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// ApplicationResume é a cópia do currículo feita no momento da candidatura
+type ApplicationResume struct {
+	ID            int       `json:"id" db:"id"`
+	ApplicationID int       `json:"application_id" db:"application_id"`
+	ResumeID      int       `json:"resume_id" db:"resume_id"`
+	Filename      string    `json:"filename" db:"filename"`
+	ContentType   string    `json:"content_type" db:"content_type"`
+	Size          int64     `json:"size" db:"size"`
+	StorageKey    string    `json:"-" db:"storage_key"`
+	Checksum      string    `json:"checksum" db:"checksum"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
@@ -42,19 +575,315 @@ type LoginRequest struct {
 
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 	Name     string `json:"name" binding:"required"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type ProfileRequest struct {
+	Name     string `json:"name" binding:"required,max=120"`
+	Headline string `json:"headline" binding:"max=160"`
+	Summary  string `json:"summary" binding:"max=5000"`
+	Location string `json:"location" binding:"max=120"`
+	Phone    string `json:"phone" binding:"max=30"`
+}
+
+type JobPreferencesRequest struct {
+	DesiredSalary int    `json:"desired_salary" binding:"min=0"`
+	Seniority     string `json:"seniority" binding:"omitempty,oneof=junior mid senior lead"`
+	WorkMode      string `json:"work_mode" binding:"omitempty,oneof=onsite hybrid remote"`
+	OpenToWork    bool   `json:"open_to_work"`
+}
+
+type ProfileLinkRequest struct {
+	Label string `json:"label" binding:"required,max=60"`
+	URL   string `json:"url" binding:"required,url,max=500"`
+}
+
+type ExperienceRequest struct {
+	Title       string `json:"title" binding:"required,max=120"`
+	Company     string `json:"company" binding:"required,max=120"`
+	Location    string `json:"location" binding:"max=120"`
+	StartDate   string `json:"start_date" binding:"required"`
+	EndDate     string `json:"end_date"`
+	Current     bool   `json:"current"`
+	Description string `json:"description" binding:"max=5000"`
+}
+
+type EducationRequest struct {
+	Institution  string `json:"institution" binding:"required,max=160"`
+	Degree       string `json:"degree" binding:"required,max=120"`
+	FieldOfStudy string `json:"field_of_study" binding:"max=120"`
+	StartDate    string `json:"start_date" binding:"required"`
+	EndDate      string `json:"end_date"`
+	Description  string `json:"description" binding:"max=5000"`
+}
+
+type SkillsRequest struct {
+	Skills []string `json:"skills" binding:"max=100,dive,required,max=60"`
+}
+
+// ResumeImportRequest traz os campos sugeridos pela importação do currículo,
+// revisados pelo candidato antes de serem gravados no perfil
+type ResumeImportRequest struct {
+	Name        string               `json:"name" binding:"max=120"`
+	Headline    string               `json:"headline" binding:"max=160"`
+	Summary     string               `json:"summary" binding:"max=5000"`
+	Location    string               `json:"location" binding:"max=120"`
+	Phone       string               `json:"phone" binding:"max=30"`
+	Links       []ProfileLinkRequest `json:"links" binding:"max=20,dive"`
+	Skills      []string             `json:"skills" binding:"max=100,dive,required,max=60"`
+	Experiences []ExperienceRequest  `json:"experiences" binding:"max=50,dive"`
+	Educations  []EducationRequest   `json:"educations" binding:"max=50,dive"`
+}
+
 type JobRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
-	Company     string `json:"company" binding:"required"`
-	Location    string `json:"location" binding:"required"`
-	Salary      string `json:"salary"`
-	Type        string `json:"type" binding:"required"`
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description" binding:"required"`
+	Company     string     `json:"company" binding:"required"`
+	Location    string     `json:"location" binding:"required"`
+	Salary      string     `json:"salary"`
+	Type        string     `json:"type" binding:"required"`
+	ClosesAt    *time.Time `json:"closes_at"` // encerramento das inscrições (opcional)
+	WorkMode    string     `json:"work_mode" binding:"omitempty,oneof=onsite hybrid remote"`
+	Seniority   string     `json:"seniority" binding:"omitempty,oneof=junior mid senior lead"`
+	// Habilidades usadas no cálculo de compatibilidade com os candidatos
+	RequiredSkills   []JobSkillRequest `json:"required_skills" binding:"max=30,dive"`
+	NiceToHaveSkills []JobSkillRequest `json:"nice_to_have_skills" binding:"max=30,dive"`
+}
+
+// JobSkillRequest aceita o objeto completo ou apenas o nome da habilidade
+type JobSkillRequest struct {
+	Name  string `json:"name" binding:"required,max=60"`
+	Level string `json:"level" binding:"omitempty,oneof=basic intermediate advanced"`
 }
 
 type ApplicationRequest struct {
-	JobID int `json:"job_id" binding:"required"`
+	JobID       int               `json:"job_id" binding:"required"`
+	ResumeID    *int              `json:"resume_id"`
+	CoverLetter string            `json:"cover_letter" binding:"max=10000"`
+	Answers     []ScreeningAnswer `json:"answers" binding:"max=100"`
+}
+
+type WithdrawRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// ScreeningQuestionRequest define uma pergunta de triagem. Respostas listadas em
+// knockout_values (ou números fora de min_value/max_value) eliminam o candidato.
+type ScreeningQuestionRequest struct {
+	Question       string   `json:"question" binding:"required,max=500"`
+	Type           string   `json:"type" binding:"required,oneof=text yes_no single_choice multi_choice number"`
+	Options        []string `json:"options" binding:"max=50,dive,required,max=200"`
+	Required       bool     `json:"required"`
+	KnockoutValues []string `json:"knockout_values" binding:"max=50"`
+	MinValue       *float64 `json:"min_value"`
+	MaxValue       *float64 `json:"max_value"`
+	Position       int      `json:"position"`
+}
+
+type TeamMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=recruiter hiring_manager interviewer"`
+}
+
+type NoteRequest struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+// ScorecardTemplateRequest substitui os critérios da vaga. Critérios com id
+// são atualizados, os sem id são criados e os ausentes são removidos.
+type ScorecardTemplateRequest struct {
+	Criteria []ScorecardCriterionRequest `json:"criteria" binding:"max=30,dive"`
+}
+
+type ScorecardCriterionRequest struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name" binding:"required,max=200"`
+	Description string `json:"description" binding:"max=1000"`
+	ScaleMax    int    `json:"scale_max" binding:"omitempty,min=2,max=10"`
+}
+
+type ScorecardRequest struct {
+	Recommendation string                   `json:"recommendation" binding:"omitempty,oneof=strong_yes yes no strong_no"`
+	Comments       string                   `json:"comments" binding:"max=10000"`
+	Ratings        []ScorecardRatingRequest `json:"ratings" binding:"max=30,dive"`
+}
+
+type ScorecardRatingRequest struct {
+	CriterionID int    `json:"criterion_id" binding:"required"`
+	Rating      int    `json:"rating" binding:"required,min=1"`
+	Comment     string `json:"comment" binding:"max=2000"`
+}
+
+// InterviewRequest aceita horários com deslocamento (RFC 3339) ou no horário
+// local do fuso informado ("2006-01-02T15:04")
+type InterviewRequest struct {
+	Type           string `json:"type" binding:"required,oneof=phone video onsite technical"`
+	StartsAt       string `json:"starts_at" binding:"required"`
+	EndsAt         string `json:"ends_at" binding:"required"`
+	TimeZone       string `json:"time_zone" binding:"required"`
+	Location       string `json:"location" binding:"max=500"`
+	VideoLink      string `json:"video_link" binding:"omitempty,url,max=500"`
+	InterviewerIDs []int  `json:"interviewer_ids" binding:"required,min=1,max=20"`
+}
+
+type AvailabilityRequest struct {
+	TimeZone string                      `json:"time_zone" binding:"required"`
+	Windows  []AvailabilityWindowRequest `json:"windows" binding:"max=50,dive"`
+}
+
+type AvailabilityWindowRequest struct {
+	Weekday   int    `json:"weekday" binding:"min=0,max=6"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+}
+
+type BlackoutRequest struct {
+	StartsOn string `json:"starts_on" binding:"required"`
+	EndsOn   string `json:"ends_on" binding:"required"`
+	Reason   string `json:"reason" binding:"max=200"`
+}
+
+type SchedulingLinkRequest struct {
+	Type            string `json:"type" binding:"required,oneof=phone video onsite technical"`
+	DurationMinutes int    `json:"duration_minutes" binding:"required,min=15,max=480"`
+	TimeZone        string `json:"time_zone" binding:"required"`
+	Location        string `json:"location" binding:"max=500"`
+	VideoLink       string `json:"video_link" binding:"omitempty,url,max=500"`
+	InterviewerIDs  []int  `json:"interviewer_ids" binding:"required,min=1,max=20"`
+	From            string `json:"from"` // data (YYYY-MM-DD) no fuso informado; padrão hoje
+	To              string `json:"to"`   // data final, inclusive; padrão 14 dias depois
+	ExpiresInHours  int    `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+type BookSlotRequest struct {
+	StartsAt string `json:"starts_at" binding:"required"`
+}
+
+type OfferRequest struct {
+	Salary     float64 `json:"salary" binding:"required,gt=0"`
+	Currency   string  `json:"currency" binding:"required,len=3"`
+	PayPeriod  string  `json:"pay_period" binding:"required,oneof=year month hour"`
+	Bonus      string  `json:"bonus" binding:"max=500"`
+	StartDate  string  `json:"start_date" binding:"required"`
+	ExpiresAt  string  `json:"expires_at" binding:"required"`
+	Notes      string  `json:"notes" binding:"max=5000"`
+	TemplateID *int    `json:"template_id"`
+}
+
+type OfferDecisionRequest struct {
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+type OfferApproversRequest struct {
+	UserIDs []int `json:"user_ids" binding:"max=10"`
+}
+
+type OfferTemplateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Body string `json:"body" binding:"required,max=20000"`
+}
+
+type RejectionReasonRequest struct {
+	Code   string `json:"code" binding:"required,max=50"`
+	Label  string `json:"label" binding:"required,max=200"`
+	Active *bool  `json:"active"`
+}
+
+type RejectRequest struct {
+	ReasonID        int    `json:"reason_id" binding:"required"`
+	Note            string `json:"note" binding:"max=2000"`
+	NotifyCandidate *bool  `json:"notify_candidate"`
+	TemplateID      *int   `json:"template_id"`
+	DelayMinutes    *int   `json:"delay_minutes" binding:"omitempty,min=0,max=20160"`
+}
+
+type MessageTemplateRequest struct {
+	Name    string `json:"name" binding:"required,max=100"`
+	Subject string `json:"subject" binding:"required,max=200"`
+	Body    string `json:"body" binding:"required,max=20000"`
+}
+
+type NotificationPreferenceRequest struct {
+	Event   string `json:"event" binding:"required"`
+	InApp   *bool  `json:"in_app"`
+	Email   *bool  `json:"email"`
+	Webhook *bool  `json:"webhook"`
+}
+
+// NotificationPreferencesRequest altera apenas os campos enviados. Um
+// webhook_url vazio remove o webhook.
+type NotificationPreferencesRequest struct {
+	EmailDigest         *string                         `json:"email_digest" binding:"omitempty,oneof=off hourly daily"`
+	WebhookURL          *string                         `json:"webhook_url" binding:"omitempty,url,max=500"`
+	RotateWebhookSecret bool                            `json:"rotate_webhook_secret"`
+	Events              []NotificationPreferenceRequest `json:"events" binding:"dive"`
+}
+
+type JobAlertRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Keywords  string `json:"keywords" binding:"max=200"`
+	Location  string `json:"location" binding:"max=100"`
+	Type      string `json:"type" binding:"max=50"`
+	MinSalary int    `json:"min_salary" binding:"min=0"`
+	Frequency string `json:"frequency" binding:"required,oneof=daily weekly"`
+	Active    *bool  `json:"active"`
+}
+
+type WebhookEndpointRequest struct {
+	URL     string   `json:"url" binding:"required,url,max=500"`
+	Events  []string `json:"events" binding:"required,min=1"`
+	AllJobs bool     `json:"all_jobs"`
+	Active  *bool    `json:"active"`
+}
+
+// BulkApplicationRequest aplica uma ação a várias candidaturas. Os campos
+// usados dependem da ação: status (move), reason_id e afins (reject),
+// add_tags/remove_tags (tag) e template_id ou subject/body (message).
+type BulkApplicationRequest struct {
+	Action          string   `json:"action" binding:"required,oneof=move reject tag message"`
+	ApplicationIDs  []int    `json:"application_ids" binding:"required,min=1,max=500"`
+	Mode            string   `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Status          string   `json:"status" binding:"omitempty,oneof=pending accepted"`
+	ReasonID        int      `json:"reason_id"`
+	Note            string   `json:"note" binding:"max=2000"`
+	NotifyCandidate *bool    `json:"notify_candidate"`
+	TemplateID      *int     `json:"template_id"`
+	DelayMinutes    *int     `json:"delay_minutes" binding:"omitempty,min=0,max=20160"`
+	AddTags         []string `json:"add_tags" binding:"max=20,dive,max=50"`
+	RemoveTags      []string `json:"remove_tags" binding:"max=20,dive,max=50"`
+	Subject         string   `json:"subject" binding:"max=200"`
+	Body            string   `json:"body" binding:"max=20000"`
+}
+
+type APIKey struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
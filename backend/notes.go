@@ -0,0 +1,333 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveMentions encontra as menções @email do texto que correspondem a
+// membros da equipe da vaga. Menções a outros usuários são ignoradas.
+func resolveMentions(body string, team []JobTeamMember, authorID int) []NoteMention {
+	mentions := []NoteMention{}
+	seen := map[int]bool{authorID: true}
+	for _, m := range markdownMention.FindAllStringSubmatch(body, -1) {
+		for _, member := range team {
+			if strings.EqualFold(member.Email, m[2]) && !seen[member.UserID] {
+				seen[member.UserID] = true
+				mentions = append(mentions, NoteMention{UserID: member.UserID, Name: member.Name, Email: member.Email})
+			}
+		}
+	}
+	return mentions
+}
+
+// listApplicationNotes devolve as notas da candidatura em ordem cronológica
+func listApplicationNotes(appID int) ([]ApplicationNote, error) {
+	rows, err := db.Query(`
+		SELECT n.id, n.application_id, n.author_id, u.name, n.body, n.edited_at, n.created_at
+		FROM application_notes n
+		JOIN users u ON n.author_id = u.id
+		WHERE n.application_id = ?
+		ORDER BY n.created_at, n.id`, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []ApplicationNote{}
+	index := map[int]int{}
+	for rows.Next() {
+		var note ApplicationNote
+		var editedAt sql.NullTime
+		if err := rows.Scan(&note.ID, &note.ApplicationID, &note.AuthorID, &note.AuthorName,
+			&note.Body, &editedAt, &note.CreatedAt); err != nil {
+			return nil, err
+		}
+		if editedAt.Valid {
+			note.EditedAt = &editedAt.Time
+		}
+		note.BodyHTML = renderMarkdown(note.Body)
+		note.Mentions = []NoteMention{}
+		index[note.ID] = len(notes)
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mentionRows, err := db.Query(`
+		SELECT nm.note_id, u.id, u.name, u.email
+		FROM note_mentions nm
+		JOIN application_notes n ON nm.note_id = n.id
+		JOIN users u ON nm.user_id = u.id
+		WHERE n.application_id = ?`, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer mentionRows.Close()
+
+	for mentionRows.Next() {
+		var noteID int
+		var mention NoteMention
+		if err := mentionRows.Scan(&noteID, &mention.UserID, &mention.Name, &mention.Email); err != nil {
+			return nil, err
+		}
+		if i, ok := index[noteID]; ok {
+			notes[i].Mentions = append(notes[i].Mentions, mention)
+		}
+	}
+	return notes, mentionRows.Err()
+}
+
+// saveMentions grava as menções da nota e notifica quem ainda não havia sido
+// mencionado nela
+func saveMentions(tx *sql.Tx, ctx *hiringContext, noteID int, authorName string, mentions []NoteMention, previous map[int]bool) error {
+	if _, err := tx.Exec("DELETE FROM note_mentions WHERE note_id = ?", noteID); err != nil {
+		return err
+	}
+
+	for _, mention := range mentions {
+		if _, err := tx.Exec("INSERT INTO note_mentions (note_id, user_id) VALUES (?, ?)", noteID, mention.UserID); err != nil {
+			return err
+		}
+		if previous[mention.UserID] {
+			continue
+		}
+
+		message := fmt.Sprintf("%s mencionou você em uma nota sobre %s (%s)", authorName, ctx.CandidateName, ctx.JobTitle)
+		if err := createNotification(tx, mention.UserID, "note_mention", message, ctx.ApplicationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getApplicationNotesHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, appID); !ok {
+		return
+	}
+
+	notes, err := listApplicationNotes(appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar notas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notes": notes})
+}
+
+func createApplicationNoteHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req NoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A nota não pode ficar vazia"})
+		return
+	}
+
+	ctx, ok := requireHiringAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	team, err := listHiringTeam(ctx.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar equipe"})
+		return
+	}
+
+	var authorName string
+	db.QueryRow("SELECT name FROM users WHERE id = ?", userID).Scan(&authorName)
+
+	mentions := resolveMentions(body, team, userID)
+	now := time.Now()
+
+	var noteID int64
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO application_notes (application_id, author_id, body, created_at)
+			VALUES (?, ?, ?, ?)`, appID, userID, body, now)
+		if err != nil {
+			return err
+		}
+		noteID, _ = result.LastInsertId()
+
+		return saveMentions(tx, ctx, int(noteID), authorName, mentions, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar nota"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Nota criada com sucesso",
+		"note": ApplicationNote{
+			ID:            int(noteID),
+			ApplicationID: appID,
+			AuthorID:      userID,
+			AuthorName:    authorName,
+			Body:          body,
+			BodyHTML:      renderMarkdown(body),
+			Mentions:      mentions,
+			CreatedAt:     now,
+		},
+	})
+}
+
+// updateApplicationNoteHandler permite que o autor edite a nota. Apenas quem
+// passou a ser mencionado na nova versão é notificado.
+func updateApplicationNoteHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	noteID, err := strconv.Atoi(c.Param("noteId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req NoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A nota não pode ficar vazia"})
+		return
+	}
+
+	ctx, ok := requireHiringAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var authorID int
+	err = db.QueryRow("SELECT author_id FROM application_notes WHERE id = ? AND application_id = ?", noteID, appID).Scan(&authorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nota não encontrada"})
+		return
+	}
+
+	if authorID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o autor pode editar a nota"})
+		return
+	}
+
+	team, err := listHiringTeam(ctx.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar equipe"})
+		return
+	}
+
+	var authorName string
+	db.QueryRow("SELECT name FROM users WHERE id = ?", userID).Scan(&authorName)
+
+	mentions := resolveMentions(body, team, userID)
+
+	err = withTx(func(tx *sql.Tx) error {
+		previous := map[int]bool{}
+		rows, err := tx.Query("SELECT user_id FROM note_mentions WHERE note_id = ?", noteID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err == nil {
+				previous[id] = true
+			}
+		}
+		rows.Close()
+
+		_, err = tx.Exec("UPDATE application_notes SET body = ?, edited_at = ? WHERE id = ?", body, time.Now(), noteID)
+		if err != nil {
+			return err
+		}
+
+		return saveMentions(tx, ctx, noteID, authorName, mentions, previous)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar nota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Nota atualizada com sucesso"})
+}
+
+// deleteApplicationNoteHandler permite excluir a nota ao autor e ao dono da vaga
+func deleteApplicationNoteHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	noteID, err := strconv.Atoi(c.Param("noteId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ctx, ok := requireHiringAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var authorID int
+	err = db.QueryRow("SELECT author_id FROM application_notes WHERE id = ? AND application_id = ?", noteID, appID).Scan(&authorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nota não encontrada"})
+		return
+	}
+
+	if authorID != userID && ctx.JobOwnerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para excluir esta nota"})
+		return
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM note_mentions WHERE note_id = ?", noteID); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM application_notes WHERE id = ?", noteID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir nota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Nota excluída com sucesso"})
+}
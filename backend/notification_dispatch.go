@@ -0,0 +1,250 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Intervalo entre os resumos de cada modo
+var notificationDigestPeriods = map[string]time.Duration{
+	"hourly": time.Hour,
+	"daily":  24 * time.Hour,
+}
+
+// Evita que duas rodadas de entrega rodem ao mesmo tempo
+var notificationDispatchMu sync.Mutex
+
+type notificationDelivery struct {
+	ID            int
+	UserID        int
+	Event         string
+	Channel       string
+	Message       string
+	ApplicationID *int
+	Attempts      int
+	CreatedAt     time.Time
+}
+
+func listPendingDeliveries(channel string) ([]notificationDelivery, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id, event, channel, message, application_id, attempts, created_at
+		FROM notification_deliveries
+		WHERE channel = ? AND sent_at IS NULL AND failed_at IS NULL
+		  AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+		ORDER BY user_id, created_at, id`, channel, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []notificationDelivery{}
+	for rows.Next() {
+		var d notificationDelivery
+		var appID sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Event, &d.Channel, &d.Message, &appID, &d.Attempts, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		if appID.Valid {
+			id := int(appID.Int64)
+			d.ApplicationID = &id
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// markDeliveriesSent marca as entregas como enviadas e devolve as que ainda
+// estavam pendentes, para que nada seja enviado duas vezes
+func markDeliveriesSent(tx *sql.Tx, deliveries []notificationDelivery) ([]notificationDelivery, error) {
+	marked := []notificationDelivery{}
+	for _, d := range deliveries {
+		result, err := tx.Exec(`
+			UPDATE notification_deliveries SET sent_at = ?
+			WHERE id = ? AND sent_at IS NULL AND failed_at IS NULL`, time.Now().UTC(), d.ID)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			marked = append(marked, d)
+		}
+	}
+	return marked, nil
+}
+
+// dispatchNotifications envia os emails e webhooks pendentes
+func dispatchNotifications() error {
+	notificationDispatchMu.Lock()
+	defer notificationDispatchMu.Unlock()
+
+	return errors.Join(dispatchNotificationEmails(), dispatchNotificationWebhooks())
+}
+
+// dispatchNotificationEmails envia um email por notificação ou, para quem
+// escolheu o modo resumo, um único email por período com as pendentes
+func dispatchNotificationEmails() error {
+	deliveries, err := listPendingDeliveries("email")
+	if err != nil {
+		return err
+	}
+
+	byUser := map[int][]notificationDelivery{}
+	var users []int
+	for _, d := range deliveries {
+		if _, ok := byUser[d.UserID]; !ok {
+			users = append(users, d.UserID)
+		}
+		byUser[d.UserID] = append(byUser[d.UserID], d)
+	}
+
+	for _, userID := range users {
+		pending := byUser[userID]
+
+		var email string
+		if err := db.QueryRow("SELECT email FROM users WHERE id = ?", userID).Scan(&email); err != nil {
+			continue
+		}
+		settings, err := loadNotificationSettings(userID)
+		if err != nil {
+			continue
+		}
+
+		period, digest := notificationDigestPeriods[settings.EmailDigest]
+		if digest {
+			// O período conta a partir do último resumo ou, se for mais recente,
+			// da notificação mais antiga ainda não enviada
+			start := pending[0].CreatedAt
+			if settings.LastDigestAt != nil && settings.LastDigestAt.After(start) {
+				start = *settings.LastDigestAt
+			}
+			if time.Since(start) < period {
+				continue
+			}
+		}
+
+		// As entregas são marcadas como enviadas na mesma transação em que os
+		// emails entram na fila
+		err = withTx(func(tx *sql.Tx) error {
+			sent, err := markDeliveriesSent(tx, pending)
+			if err != nil || len(sent) == 0 {
+				return err
+			}
+
+			if !digest {
+				for _, d := range sent {
+					err := enqueueEmail(tx, Email{
+						To:      []string{email},
+						Subject: defaultNotificationPreference(d.Event).Label,
+						Body:    d.Message,
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			lines := []string{}
+			for _, d := range sent {
+				lines = append(lines, fmt.Sprintf("- %s: %s (%s)",
+					defaultNotificationPreference(d.Event).Label, d.Message, d.CreatedAt.Local().Format("02/01 15:04")))
+			}
+			err = enqueueEmail(tx, Email{
+				To:      []string{email},
+				Subject: fmt.Sprintf("Resumo de notificações (%d)", len(sent)),
+				Body:    strings.Join(lines, "\n"),
+			})
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("UPDATE notification_settings SET last_digest_at = ? WHERE user_id = ?", time.Now().UTC(), userID)
+			return err
+		})
+		if err != nil {
+			log.Printf("Erro ao registrar envio de notificações: %v", err)
+		}
+	}
+	return nil
+}
+
+// signPayload assina o corpo da requisição com HMAC-SHA256
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// dispatchNotificationWebhooks envia as notificações ao webhook de cada
+// usuário. Falhas são tentadas de novo com a mesma espera exponencial dos
+// webhooks de integração, até webhookMaxAttempts.
+func dispatchNotificationWebhooks() error {
+	deliveries, err := listPendingDeliveries("webhook")
+	if err != nil {
+		return err
+	}
+
+	for _, d := range deliveries {
+		settings, err := loadNotificationSettings(d.UserID)
+		if err != nil {
+			continue
+		}
+
+		deliveryErr := ""
+		if settings.WebhookURL == "" {
+			deliveryErr = "webhook removido"
+		} else {
+			deliveryErr = postNotificationWebhook(settings, d)
+		}
+
+		now := time.Now().UTC()
+		attempts := d.Attempts + 1
+		if deliveryErr == "" {
+			db.Exec("UPDATE notification_deliveries SET sent_at = ?, attempts = ?, next_attempt_at = NULL WHERE id = ?", now, attempts, d.ID)
+			continue
+		}
+
+		var failedAt, nextAttempt interface{}
+		if settings.WebhookURL == "" || attempts >= webhookMaxAttempts {
+			failedAt = now
+		} else {
+			nextAttempt = now.Add(webhookBackoff(attempts))
+		}
+		db.Exec("UPDATE notification_deliveries SET attempts = ?, last_error = ?, failed_at = ?, next_attempt_at = ? WHERE id = ?",
+			attempts, deliveryErr, failedAt, nextAttempt, d.ID)
+	}
+	return nil
+}
+
+// postNotificationWebhook envia uma notificação com os mesmos cabeçalhos e
+// assinatura dos webhooks de integração e devolve a descrição do erro, ou ""
+// se o destino respondeu com sucesso
+func postNotificationWebhook(settings *NotificationSettings, d notificationDelivery) string {
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":             d.ID,
+		"event":          d.Event,
+		"message":        d.Message,
+		"application_id": d.ApplicationID,
+		"created_at":     d.CreatedAt,
+	})
+
+	_, deliveryErr := postWebhook(settings.WebhookURL, settings.WebhookSecret, &WebhookDelivery{
+		EventID:   fmt.Sprintf("ntf_%d", d.ID),
+		EventType: d.Event,
+		Payload:   string(body),
+	})
+	return deliveryErr
+}
+
+// runNotificationDispatch é a tarefa periódica da fila que envia as
+// notificações pendentes
+func runNotificationDispatch(payload []byte) error {
+	return dispatchNotifications()
+}
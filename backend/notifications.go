@@ -0,0 +1,423 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Eventos que geram notificações, com os canais ativos por padrão. Os emails
+// ficam desligados nos eventos que já enviam um email próprio, como convites
+// de entrevista e cartas-proposta.
+var notificationEvents = []NotificationPreference{
+	{Event: "application_received", Label: "Nova candidatura recebida", InApp: true, Email: true},
+	{Event: "status_changed", Label: "Mudança de status da candidatura", InApp: true, Email: true},
+	{Event: "interview_scheduled", Label: "Entrevista agendada", InApp: true},
+	{Event: "interview_updated", Label: "Entrevista alterada", InApp: true},
+	{Event: "interview_cancelled", Label: "Entrevista cancelada", InApp: true},
+	{Event: "interview_booked", Label: "Entrevista marcada pelo candidato", InApp: true},
+	{Event: "thread_message", Label: "Nova mensagem na conversa", InApp: true},
+	{Event: "candidate_message", Label: "Mensagem da empresa", InApp: true},
+	{Event: "note_mention", Label: "Menção em nota", InApp: true, Email: true},
+	{Event: "scorecard_submitted", Label: "Avaliação enviada", InApp: true},
+	{Event: "offer_approval_requested", Label: "Proposta aguardando aprovação", InApp: true, Email: true},
+	{Event: "offer_approved", Label: "Proposta aprovada", InApp: true, Email: true},
+	{Event: "offer_rejected", Label: "Proposta recusada na aprovação", InApp: true, Email: true},
+	{Event: "offer_received", Label: "Proposta recebida", InApp: true},
+	{Event: "offer_withdrawn", Label: "Proposta retirada", InApp: true, Email: true},
+	{Event: "offer_accepted", Label: "Proposta aceita", InApp: true, Email: true},
+	{Event: "offer_declined", Label: "Proposta recusada pelo candidato", InApp: true, Email: true},
+	{Event: "webhook_disabled", Label: "Webhook desativado por falhas", InApp: true, Email: true},
+	{Event: "saved_job_closing", Label: "Vaga salva perto do encerramento", InApp: true, Email: true},
+}
+
+var applicationStatusLabels = map[string]string{
+	"pending":   "Em análise",
+	"accepted":  "Aprovada",
+	"rejected":  "Reprovada",
+	"withdrawn": "Retirada",
+	"hired":     "Contratado",
+}
+
+// defaultNotificationPreference devolve os canais padrão do evento. Eventos
+// fora do catálogo aparecem apenas no app.
+func defaultNotificationPreference(event string) NotificationPreference {
+	for _, pref := range notificationEvents {
+		if pref.Event == event {
+			return pref
+		}
+	}
+	return NotificationPreference{Event: event, Label: event, InApp: true}
+}
+
+func isNotificationEvent(event string) bool {
+	for _, pref := range notificationEvents {
+		if pref.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// loadNotificationPreference combina o padrão do evento com a escolha do
+// usuário. O webhook só vale se o usuário tiver cadastrado uma URL.
+func loadNotificationPreference(q dbtx, userID int, event string) (NotificationPreference, error) {
+	pref := defaultNotificationPreference(event)
+
+	var inApp, email, webhook sql.NullBool
+	var webhookURL sql.NullString
+	err := q.QueryRow(`
+		SELECT p.in_app, p.email, p.webhook, s.webhook_url
+		FROM users u
+		LEFT JOIN notification_preferences p ON p.user_id = u.id AND p.event = ?
+		LEFT JOIN notification_settings s ON s.user_id = u.id
+		WHERE u.id = ?`, event, userID).Scan(&inApp, &email, &webhook, &webhookURL)
+	if err != nil {
+		return pref, err
+	}
+
+	if inApp.Valid {
+		pref.InApp, pref.Email, pref.Webhook = inApp.Bool, email.Bool, webhook.Bool
+	}
+	if webhookURL.String == "" {
+		pref.Webhook = false
+	}
+	return pref, nil
+}
+
+// createNotification grava uma notificação para o usuário nos canais que ele
+// escolheu para o evento. Recebe dbtx para poder ser gravada na mesma
+// transação do evento que a gerou; emails e webhooks ficam registrados em
+// notification_deliveries e são enviados depois, por dispatchNotifications.
+func createNotification(q dbtx, userID int, kind, message string, applicationID int) error {
+	pref, err := loadNotificationPreference(q, userID, kind)
+	if err != nil {
+		return err
+	}
+
+	var appID interface{}
+	if applicationID != 0 {
+		appID = applicationID
+	}
+
+	now := time.Now()
+	if pref.InApp {
+		result, err := q.Exec(`
+			INSERT INTO notifications (user_id, type, message, application_id, created_at)
+			VALUES (?, ?, ?, ?, ?)`, userID, kind, message, appID, now)
+		if err != nil {
+			return err
+		}
+		id, _ := result.LastInsertId()
+		publishEvent(q, "notification.created", gin.H{
+			"id":             id,
+			"type":           kind,
+			"message":        message,
+			"application_id": appID,
+			"created_at":     now,
+		}, userID)
+	}
+
+	channels := []string{}
+	if pref.Email {
+		channels = append(channels, "email")
+	}
+	if pref.Webhook {
+		channels = append(channels, "webhook")
+	}
+	for _, channel := range channels {
+		_, err := q.Exec(`
+			INSERT INTO notification_deliveries (user_id, event, channel, message, application_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)`, userID, kind, channel, message, appID, now.UTC())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyHiringTeam notifica o dono da vaga e os membros da equipe de contratação
+func notifyHiringTeam(q dbtx, jobID int, kind, message string, applicationID int) error {
+	ids, err := hiringTeamIDs(q, jobID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := createNotification(q, id, kind, message, applicationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getNotificationsHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	query := `
+		SELECT id, user_id, type, message, application_id, read_at, created_at
+		FROM notifications
+		WHERE user_id = ?`
+	if c.Query("unread") == "true" {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT 100"
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar notificações"})
+		return
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		var appID sql.NullInt64
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Message, &appID, &readAt, &n.CreatedAt); err != nil {
+			continue
+		}
+		if appID.Valid {
+			id := int(appID.Int64)
+			n.ApplicationID = &id
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+
+	var unread int
+	db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL", userID).Scan(&unread)
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unread_count":  unread,
+	})
+}
+
+func markNotificationReadHandler(c *gin.Context) {
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	result, err := db.Exec(`
+		UPDATE notifications SET read_at = COALESCE(read_at, ?)
+		WHERE id = ? AND user_id = ?`, time.Now(), notificationID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar notificação"})
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notificação não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notificação marcada como lida"})
+}
+
+func markAllNotificationsReadHandler(c *gin.Context) {
+	result, err := db.Exec("UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL",
+		time.Now(), c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar notificações"})
+		return
+	}
+	marked, _ := result.RowsAffected()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notificações marcadas como lidas", "marked": marked})
+}
+
+func loadNotificationSettings(userID int) (*NotificationSettings, error) {
+	settings := &NotificationSettings{EmailDigest: "off"}
+	var lastDigestAt sql.NullTime
+	err := db.QueryRow(`
+		SELECT email_digest, webhook_url, webhook_secret, last_digest_at
+		FROM notification_settings WHERE user_id = ?`, userID).Scan(
+		&settings.EmailDigest, &settings.WebhookURL, &settings.WebhookSecret, &lastDigestAt)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lastDigestAt.Valid {
+		settings.LastDigestAt = &lastDigestAt.Time
+	}
+	return settings, nil
+}
+
+// listNotificationPreferences devolve todos os eventos do catálogo com a
+// escolha do usuário ou o padrão
+func listNotificationPreferences(userID int) ([]NotificationPreference, error) {
+	rows, err := db.Query("SELECT event, in_app, email, webhook FROM notification_preferences WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	saved := map[string]NotificationPreference{}
+	for rows.Next() {
+		var pref NotificationPreference
+		if err := rows.Scan(&pref.Event, &pref.InApp, &pref.Email, &pref.Webhook); err != nil {
+			return nil, err
+		}
+		saved[pref.Event] = pref
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	prefs := []NotificationPreference{}
+	for _, pref := range notificationEvents {
+		if s, ok := saved[pref.Event]; ok {
+			pref.InApp, pref.Email, pref.Webhook = s.InApp, s.Email, s.Webhook
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
+
+func getNotificationPreferencesHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	settings, err := loadNotificationSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar preferências"})
+		return
+	}
+
+	prefs, err := listNotificationPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar preferências"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings, "events": prefs})
+}
+
+// updateNotificationPreferencesHandler altera o modo de resumo, o webhook e os
+// canais dos eventos enviados. O segredo do webhook é gerado ao cadastrar a
+// URL (ou com rotate_webhook_secret) e mostrado apenas nessa resposta.
+func updateNotificationPreferencesHandler(c *gin.Context) {
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, event := range req.Events {
+		if !isNotificationEvent(event.Event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Evento desconhecido: " + event.Event})
+			return
+		}
+	}
+
+	if req.WebhookURL != nil && *req.WebhookURL != "" {
+		if err := checkWebhookURL(*req.WebhookURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID := c.GetInt("user_id")
+	settings, err := loadNotificationSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar preferências"})
+		return
+	}
+
+	if req.EmailDigest != nil {
+		settings.EmailDigest = *req.EmailDigest
+	}
+
+	newSecret := ""
+	if req.WebhookURL != nil {
+		if *req.WebhookURL == "" {
+			settings.WebhookSecret = ""
+		} else if *req.WebhookURL != settings.WebhookURL || settings.WebhookSecret == "" {
+			req.RotateWebhookSecret = true
+		}
+		settings.WebhookURL = *req.WebhookURL
+	}
+	if req.RotateWebhookSecret && settings.WebhookURL != "" {
+		newSecret, err = randomToken(32)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar preferências"})
+			return
+		}
+		settings.WebhookSecret = newSecret
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO notification_settings (user_id, email_digest, webhook_url, webhook_secret, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET
+				email_digest = excluded.email_digest, webhook_url = excluded.webhook_url,
+				webhook_secret = excluded.webhook_secret, updated_at = excluded.updated_at`,
+			userID, settings.EmailDigest, settings.WebhookURL, settings.WebhookSecret, time.Now())
+		if err != nil {
+			return err
+		}
+
+		for _, event := range req.Events {
+			// Sem escolha gravada, parte do padrão do evento
+			pref := defaultNotificationPreference(event.Event)
+			err := tx.QueryRow("SELECT in_app, email, webhook FROM notification_preferences WHERE user_id = ? AND event = ?",
+				userID, event.Event).Scan(&pref.InApp, &pref.Email, &pref.Webhook)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+
+			if event.InApp != nil {
+				pref.InApp = *event.InApp
+			}
+			if event.Email != nil {
+				pref.Email = *event.Email
+			}
+			if event.Webhook != nil {
+				pref.Webhook = *event.Webhook
+			}
+			_, err = tx.Exec(`
+				INSERT INTO notification_preferences (user_id, event, in_app, email, webhook)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (user_id, event) DO UPDATE SET
+					in_app = excluded.in_app, email = excluded.email, webhook = excluded.webhook`,
+				userID, event.Event, pref.InApp, pref.Email, pref.Webhook)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar preferências"})
+		return
+	}
+
+	prefs, err := listNotificationPreferences(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar preferências"})
+		return
+	}
+
+	response := gin.H{
+		"message":  "Preferências atualizadas com sucesso",
+		"settings": settings,
+		"events":   prefs,
+	}
+	if newSecret != "" {
+		response["webhook_secret"] = newSecret
+	}
+	c.JSON(http.StatusOK, response)
+}
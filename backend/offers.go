@@ -0,0 +1,1088 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultOfferTemplate é usado quando a proposta não indica um modelo
+const defaultOfferTemplate = `# Proposta de emprego
+
+Olá, {{candidate_name}}.
+
+Temos o prazer de oferecer a você a posição de **{{job_title}}** na {{company}}.
+
+- **Remuneração:** {{salary}} por {{pay_period}}
+- **Bônus:** {{bonus}}
+- **Data de início:** {{start_date}}
+
+Esta proposta é válida até {{expires_at}}. Para aceitá-la ou recusá-la, acesse
+sua candidatura no sistema.
+
+Atenciosamente,
+{{sender_name}}`
+
+var offerPayPeriodLabels = map[string]string{
+	"year":  "ano",
+	"month": "mês",
+	"hour":  "hora",
+}
+
+var (
+	errOfferChanged    = errors.New("A proposta foi alterada por outra requisição")
+	errOfferExpired    = errors.New("Esta proposta expirou")
+	errNotNextApprover = errors.New("Você não é o próximo aprovador desta proposta")
+)
+
+// Status em que o candidato pode ver a proposta
+var candidateVisibleOfferStatus = map[string]bool{
+	"sent":     true,
+	"accepted": true,
+	"declined": true,
+	"expired":  true,
+}
+
+// expireOffers marca como expiradas as propostas enviadas cujo prazo passou
+func expireOffers(q dbtx) error {
+	now := time.Now().UTC()
+	_, err := q.Exec("UPDATE offers SET status = 'expired', updated_at = ? WHERE status = 'sent' AND expires_at < ?", now, now)
+	return err
+}
+
+func loadOffer(q dbtx, offerID int) (*Offer, error) {
+	var o Offer
+	var templateID sql.NullInt64
+	var sentAt, respondedAt sql.NullTime
+	err := q.QueryRow(`
+		SELECT id, application_id, status, salary, currency, pay_period, bonus, start_date, expires_at,
+		       notes, template_id, decline_reason, created_by, sent_at, responded_at, created_at, updated_at
+		FROM offers WHERE id = ?`, offerID).Scan(&o.ID, &o.ApplicationID, &o.Status, &o.Salary, &o.Currency,
+		&o.PayPeriod, &o.Bonus, &o.StartDate, &o.ExpiresAt, &o.Notes, &templateID, &o.DeclineReason,
+		&o.CreatedBy, &sentAt, &respondedAt, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if templateID.Valid {
+		id := int(templateID.Int64)
+		o.TemplateID = &id
+	}
+	if sentAt.Valid {
+		o.SentAt = &sentAt.Time
+	}
+	if respondedAt.Valid {
+		o.RespondedAt = &respondedAt.Time
+	}
+
+	o.Approvals, err = listOfferApprovals(q, offerID)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func listOfferApprovals(q dbtx, offerID int) ([]OfferApproval, error) {
+	rows, err := q.Query(`
+		SELECT oa.user_id, u.name, oa.position, oa.status, oa.comment, oa.decided_at
+		FROM offer_approvals oa
+		JOIN users u ON oa.user_id = u.id
+		WHERE oa.offer_id = ?
+		ORDER BY oa.position`, offerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := []OfferApproval{}
+	for rows.Next() {
+		var a OfferApproval
+		var decidedAt sql.NullTime
+		if err := rows.Scan(&a.UserID, &a.Name, &a.Position, &a.Status, &a.Comment, &decidedAt); err != nil {
+			return nil, err
+		}
+		if decidedAt.Valid {
+			a.DecidedAt = &decidedAt.Time
+		}
+		approvals = append(approvals, a)
+	}
+	return approvals, rows.Err()
+}
+
+// nextApprover devolve a primeira aprovação pendente da cadeia
+func (o *Offer) nextApprover() *OfferApproval {
+	for i := range o.Approvals {
+		if o.Approvals[i].Status == "pending" {
+			return &o.Approvals[i]
+		}
+	}
+	return nil
+}
+
+// forCandidate remove da proposta os dados internos da equipe
+func (o *Offer) forCandidate() *Offer {
+	visible := *o
+	visible.Notes = ""
+	visible.TemplateID = nil
+	visible.Approvals = nil
+	return &visible
+}
+
+// requireOfferAccess carrega a proposta e confirma que o usuário é o
+// candidato (apenas propostas já enviadas) ou faz parte da equipe de contratação
+func requireOfferAccess(c *gin.Context) (*Offer, *interviewContext, bool, bool) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return nil, nil, false, false
+	}
+
+	expireOffers(db)
+
+	offer, err := loadOffer(db, offerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposta não encontrada"})
+		return nil, nil, false, false
+	}
+
+	ctx, err := loadInterviewContext(offer.ApplicationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposta não encontrada"})
+		return nil, nil, false, false
+	}
+
+	userID := c.GetInt("user_id")
+	if isHiringSide(ctx.JobID, userID) {
+		return offer, ctx, true, true
+	}
+	if ctx.Candidate.UserID == userID && candidateVisibleOfferStatus[offer.Status] {
+		return offer, ctx, false, true
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Proposta não encontrada"})
+	return nil, nil, false, false
+}
+
+// requireOfferHiringAccess é requireOfferAccess restrito à equipe de contratação
+func requireOfferHiringAccess(c *gin.Context) (*Offer, *interviewContext, bool) {
+	offer, ctx, hiring, ok := requireOfferAccess(c)
+	if !ok {
+		return nil, nil, false
+	}
+	if !hiring {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas a equipe de contratação pode alterar propostas"})
+		return nil, nil, false
+	}
+	return offer, ctx, true
+}
+
+// parseOfferRequest valida as datas e o modelo da proposta. expires_at aceita
+// RFC 3339 ou uma data (a proposta vale até o fim desse dia, em UTC).
+func parseOfferRequest(req *OfferRequest, userID int) (expiresAt time.Time, err error) {
+	if _, err := time.Parse("2006-01-02", req.StartDate); err != nil {
+		return expiresAt, errors.New("Data de início inválida (use AAAA-MM-DD)")
+	}
+
+	if expiresAt, err = time.Parse(time.RFC3339, req.ExpiresAt); err != nil {
+		day, err := time.Parse("2006-01-02", req.ExpiresAt)
+		if err != nil {
+			return expiresAt, errors.New("Validade inválida (use AAAA-MM-DD ou RFC 3339)")
+		}
+		expiresAt = day.Add(24*time.Hour - time.Second)
+	}
+	expiresAt = expiresAt.UTC().Truncate(time.Second)
+	if !expiresAt.After(time.Now()) {
+		return expiresAt, errors.New("A validade da proposta deve ser uma data futura")
+	}
+
+	req.Currency = strings.ToUpper(req.Currency)
+
+	if req.TemplateID != nil {
+		var count int
+		db.QueryRow("SELECT COUNT(*) FROM offer_templates WHERE id = ? AND user_id = ?", *req.TemplateID, userID).Scan(&count)
+		if count == 0 {
+			return expiresAt, errors.New("Modelo de carta não encontrado")
+		}
+	}
+	return expiresAt, nil
+}
+
+// formatAmount formata o valor no padrão brasileiro (1.234,56)
+func formatAmount(value float64) string {
+	cents := int64(math.Round(value * 100))
+	integer := strconv.FormatInt(cents/100, 10)
+
+	var sb strings.Builder
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(r)
+	}
+	return fmt.Sprintf("%s,%02d", sb.String(), cents%100)
+}
+
+// renderOfferLetter preenche o modelo da proposta e devolve a carta em Markdown
+func renderOfferLetter(offer *Offer, ctx *interviewContext, senderName string) string {
+	body := defaultOfferTemplate
+	if offer.TemplateID != nil {
+		db.QueryRow("SELECT body FROM offer_templates WHERE id = ?", *offer.TemplateID).Scan(&body)
+	}
+
+	bonus := offer.Bonus
+	if bonus == "" {
+		bonus = "não se aplica"
+	}
+	startDate := offer.StartDate
+	if t, err := time.Parse("2006-01-02", offer.StartDate); err == nil {
+		startDate = t.Format("02/01/2006")
+	}
+
+	return fillPlaceholders(body, map[string]string{
+		"candidate_name": ctx.Candidate.Name,
+		"job_title":      ctx.JobTitle,
+		"company":        ctx.Company,
+		"salary":         offer.Currency + " " + formatAmount(offer.Salary),
+		"pay_period":     offerPayPeriodLabels[offer.PayPeriod],
+		"bonus":          bonus,
+		"start_date":     startDate,
+		"expires_at":     offer.ExpiresAt.Format("02/01/2006 15:04") + " (UTC)",
+		"sender_name":    senderName,
+	})
+}
+
+// offerLetterHTML monta o documento HTML da carta a partir do Markdown
+func offerLetterHTML(letter string, ctx *interviewContext) []byte {
+	return []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>Proposta - %s</title>
+</head>
+<body>
+%s
+</body>
+</html>
+`, html.EscapeString(ctx.JobTitle), renderMarkdown(letter)))
+}
+
+func getJobOfferApproversHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if !requireHiringTeam(c, jobID) {
+		return
+	}
+
+	approvers, err := listJobOfferApprovers(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar aprovadores"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"approvers": approvers})
+}
+
+func listJobOfferApprovers(jobID int) ([]InterviewParticipant, error) {
+	rows, err := db.Query(`
+		SELECT u.id, u.name, u.email
+		FROM job_offer_approvers ja
+		JOIN users u ON ja.user_id = u.id
+		WHERE ja.job_id = ?
+		ORDER BY ja.position`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvers := []InterviewParticipant{}
+	for rows.Next() {
+		var p InterviewParticipant
+		if err := rows.Scan(&p.UserID, &p.Name, &p.Email); err != nil {
+			return nil, err
+		}
+		approvers = append(approvers, p)
+	}
+	return approvers, rows.Err()
+}
+
+// updateJobOfferApproversHandler define, em ordem, quem aprova as propostas
+// da vaga. Propostas já submetidas mantêm a cadeia da época da submissão.
+func updateJobOfferApproversHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req OfferApproversRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireJobOwner(c, jobID) {
+		return
+	}
+
+	seen := map[int]bool{}
+	for _, id := range req.UserIDs {
+		if seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("O usuário %d aparece mais de uma vez na cadeia", id)})
+			return
+		}
+		seen[id] = true
+		if !isHiringSide(jobID, id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("O usuário %d não faz parte da equipe de contratação da vaga", id)})
+			return
+		}
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM job_offer_approvers WHERE job_id = ?", jobID); err != nil {
+			return err
+		}
+		for i, id := range req.UserIDs {
+			if _, err := tx.Exec("INSERT INTO job_offer_approvers (job_id, user_id, position) VALUES (?, ?, ?)", jobID, id, i+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar aprovadores"})
+		return
+	}
+
+	approvers, _ := listJobOfferApprovers(jobID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Cadeia de aprovação atualizada com sucesso",
+		"approvers": approvers,
+	})
+}
+
+func getOfferTemplatesHandler(c *gin.Context) {
+	rows, err := db.Query(`
+		SELECT id, user_id, name, body, created_at, updated_at
+		FROM offer_templates WHERE user_id = ?
+		ORDER BY name`, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar modelos"})
+		return
+	}
+	defer rows.Close()
+
+	templates := []OfferTemplate{}
+	for rows.Next() {
+		var t OfferTemplate
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.Body, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler modelos"})
+			return
+		}
+		templates = append(templates, t)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates":    templates,
+		"default_body": defaultOfferTemplate,
+		"placeholders": []string{"candidate_name", "job_title", "company", "salary", "pay_period", "bonus", "start_date", "expires_at", "sender_name"},
+	})
+}
+
+func createOfferTemplateHandler(c *gin.Context) {
+	var req OfferTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	t := OfferTemplate{UserID: c.GetInt("user_id"), Name: strings.TrimSpace(req.Name), Body: req.Body, CreatedAt: now, UpdatedAt: now}
+
+	result, err := db.Exec(`
+		INSERT INTO offer_templates (user_id, name, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`, t.UserID, t.Name, t.Body, now, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar modelo"})
+		return
+	}
+	id, _ := result.LastInsertId()
+	t.ID = int(id)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Modelo criado com sucesso",
+		"template": t,
+	})
+}
+
+func updateOfferTemplateHandler(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req OfferTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE offer_templates SET name = ?, body = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?`, strings.TrimSpace(req.Name), req.Body, time.Now(), templateID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar modelo"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Modelo não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Modelo atualizado com sucesso"})
+}
+
+// deleteOfferTemplateHandler exclui o modelo. Cartas já enviadas ficam
+// gravadas na proposta e não são afetadas.
+func deleteOfferTemplateHandler(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM offer_templates WHERE id = ? AND user_id = ?", templateID, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir modelo"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Modelo não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Modelo excluído com sucesso"})
+}
+
+// getApplicationOffersHandler lista as propostas da candidatura. O candidato
+// só vê as que já foram enviadas, sem as notas internas e as aprovações.
+func getApplicationOffersHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	ctx, err := loadInterviewContext(appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+	hiring := isHiringSide(ctx.JobID, userID)
+	if !hiring && ctx.Candidate.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	expireOffers(db)
+
+	rows, err := db.Query("SELECT id FROM offers WHERE application_id = ? ORDER BY created_at DESC, id DESC", appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar propostas"})
+		return
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	offers := []*Offer{}
+	for _, id := range ids {
+		offer, err := loadOffer(db, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar propostas"})
+			return
+		}
+		if hiring {
+			offers = append(offers, offer)
+		} else if candidateVisibleOfferStatus[offer.Status] {
+			offers = append(offers, offer.forCandidate())
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offers": offers})
+}
+
+func getOfferHandler(c *gin.Context) {
+	offer, _, hiring, ok := requireOfferAccess(c)
+	if !ok {
+		return
+	}
+
+	if !hiring {
+		offer = offer.forCandidate()
+	}
+	c.JSON(http.StatusOK, gin.H{"offer": offer})
+}
+
+func createOfferHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req OfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, appID); !ok {
+		return
+	}
+
+	ctx, err := loadInterviewContext(appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	if ctx.Status == "withdrawn" || ctx.Status == "rejected" || ctx.Status == "hired" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Não é possível fazer propostas para esta candidatura"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	expiresAt, err := parseOfferRequest(&req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	offer := &Offer{
+		ApplicationID: appID,
+		Status:        "draft",
+		Salary:        req.Salary,
+		Currency:      req.Currency,
+		PayPeriod:     req.PayPeriod,
+		Bonus:         req.Bonus,
+		StartDate:     req.StartDate,
+		ExpiresAt:     expiresAt,
+		Notes:         req.Notes,
+		TemplateID:    req.TemplateID,
+		Approvals:     []OfferApproval{},
+		CreatedBy:     userID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// Só pode haver uma proposta em andamento (ou aceita) por candidatura
+	err = withTx(func(tx *sql.Tx) error {
+		if err := expireOffers(tx); err != nil {
+			return err
+		}
+		var active int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM offers
+			WHERE application_id = ? AND status NOT IN ('declined', 'withdrawn', 'expired')`, appID).Scan(&active)
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return errOfferChanged
+		}
+
+		result, err := tx.Exec(`
+			INSERT INTO offers (application_id, status, salary, currency, pay_period, bonus, start_date, expires_at,
+			                    notes, template_id, created_by, created_at, updated_at)
+			VALUES (?, 'draft', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			appID, req.Salary, req.Currency, req.PayPeriod, req.Bonus, req.StartDate, expiresAt,
+			req.Notes, req.TemplateID, userID, now, now)
+		if err != nil {
+			return err
+		}
+		id, _ := result.LastInsertId()
+		offer.ID = int(id)
+		return nil
+	})
+	if errors.Is(err, errOfferChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Já existe uma proposta em andamento para esta candidatura"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar proposta"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Proposta criada com sucesso",
+		"offer":   offer,
+	})
+}
+
+// updateOfferHandler altera uma proposta em rascunho. Propostas recusadas por
+// um aprovador voltam a ser rascunho e podem ser corrigidas e submetidas de novo.
+func updateOfferHandler(c *gin.Context) {
+	var req OfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, _, ok := requireOfferHiringAccess(c)
+	if !ok {
+		return
+	}
+
+	if offer.Status != "draft" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Apenas propostas em rascunho podem ser alteradas"})
+		return
+	}
+
+	expiresAt, err := parseOfferRequest(&req, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE offers SET salary = ?, currency = ?, pay_period = ?, bonus = ?, start_date = ?, expires_at = ?,
+		                  notes = ?, template_id = ?, updated_at = ?
+		WHERE id = ? AND status = 'draft'`,
+		req.Salary, req.Currency, req.PayPeriod, req.Bonus, req.StartDate, expiresAt,
+		req.Notes, req.TemplateID, time.Now(), offer.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar proposta"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": errOfferChanged.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Proposta atualizada com sucesso"})
+}
+
+// submitOfferHandler envia o rascunho para a cadeia de aprovação da vaga. Sem
+// aprovadores configurados, a proposta fica aprovada imediatamente.
+func submitOfferHandler(c *gin.Context) {
+	offer, ctx, ok := requireOfferHiringAccess(c)
+	if !ok {
+		return
+	}
+
+	if offer.Status != "draft" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Apenas propostas em rascunho podem ser submetidas"})
+		return
+	}
+
+	approvers, err := listJobOfferApprovers(ctx.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar aprovadores"})
+		return
+	}
+
+	status := "pending_approval"
+	if len(approvers) == 0 {
+		status = "approved"
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec("UPDATE offers SET status = ?, updated_at = ? WHERE id = ? AND status = 'draft'", status, time.Now(), offer.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errOfferChanged
+		}
+
+		if _, err := tx.Exec("DELETE FROM offer_approvals WHERE offer_id = ?", offer.ID); err != nil {
+			return err
+		}
+		for i, p := range approvers {
+			_, err := tx.Exec("INSERT INTO offer_approvals (offer_id, user_id, position) VALUES (?, ?, ?)", offer.ID, p.UserID, i+1)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(approvers) == 0 {
+			return nil
+		}
+		message := fmt.Sprintf("Proposta para %s (%s) aguarda sua aprovação", ctx.Candidate.Name, ctx.JobTitle)
+		return createNotification(tx, approvers[0].UserID, "offer_approval_requested", message, ctx.ApplicationID)
+	})
+	if errors.Is(err, errOfferChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao submeter proposta"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Proposta submetida para aprovação",
+		"status":  status,
+	})
+}
+
+func approveOfferHandler(c *gin.Context) {
+	decideOffer(c, true)
+}
+
+func rejectOfferHandler(c *gin.Context) {
+	decideOffer(c, false)
+}
+
+// decideOffer registra a decisão do próximo aprovador da cadeia. Uma recusa
+// devolve a proposta para rascunho; a última aprovação a libera para envio.
+func decideOffer(c *gin.Context, approve bool) {
+	var req OfferDecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	offer, ctx, ok := requireOfferHiringAccess(c)
+	if !ok {
+		return
+	}
+
+	if offer.Status != "pending_approval" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta proposta não está aguardando aprovação"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	next := offer.nextApprover()
+	if next == nil || next.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": errNotNextApprover.Error()})
+		return
+	}
+
+	decision, status := "approved", "pending_approval"
+	if !approve {
+		decision, status = "rejected", "draft"
+	} else if next.Position == offer.Approvals[len(offer.Approvals)-1].Position {
+		status = "approved"
+	}
+
+	var approverName string
+	db.QueryRow("SELECT name FROM users WHERE id = ?", userID).Scan(&approverName)
+
+	err := withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE offer_approvals SET status = ?, comment = ?, decided_at = ?
+			WHERE offer_id = ? AND user_id = ? AND status = 'pending'`,
+			decision, strings.TrimSpace(req.Comment), time.Now(), offer.ID, userID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errOfferChanged
+		}
+
+		result, err = tx.Exec("UPDATE offers SET status = ?, updated_at = ? WHERE id = ? AND status = 'pending_approval'", status, time.Now(), offer.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errOfferChanged
+		}
+
+		switch status {
+		case "draft":
+			message := fmt.Sprintf("%s recusou a proposta para %s (%s)", approverName, ctx.Candidate.Name, ctx.JobTitle)
+			return createNotification(tx, offer.CreatedBy, "offer_rejected", message, ctx.ApplicationID)
+		case "approved":
+			message := fmt.Sprintf("A proposta para %s (%s) foi aprovada e pode ser enviada", ctx.Candidate.Name, ctx.JobTitle)
+			return createNotification(tx, offer.CreatedBy, "offer_approved", message, ctx.ApplicationID)
+		}
+
+		for _, a := range offer.Approvals {
+			if a.Position > next.Position {
+				message := fmt.Sprintf("Proposta para %s (%s) aguarda sua aprovação", ctx.Candidate.Name, ctx.JobTitle)
+				return createNotification(tx, a.UserID, "offer_approval_requested", message, ctx.ApplicationID)
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errOfferChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao registrar decisão"})
+		return
+	}
+
+	message := "Proposta aprovada"
+	if !approve {
+		message = "Proposta recusada e devolvida para rascunho"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"status":  status,
+	})
+}
+
+// sendOfferHandler gera a carta a partir do modelo e envia a proposta aprovada
+// ao candidato
+func sendOfferHandler(c *gin.Context) {
+	offer, ctx, ok := requireOfferHiringAccess(c)
+	if !ok {
+		return
+	}
+
+	if offer.Status != "approved" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Apenas propostas aprovadas podem ser enviadas"})
+		return
+	}
+
+	if !offer.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A validade da proposta já passou; crie uma nova proposta"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	var senderName string
+	db.QueryRow("SELECT name FROM users WHERE id = ?", userID).Scan(&senderName)
+
+	letter := renderOfferLetter(offer, ctx, senderName)
+	now := time.Now()
+
+	err := withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE offers SET status = 'sent', letter = ?, sent_at = ?, updated_at = ?
+			WHERE id = ? AND status = 'approved'`, letter, now, now, offer.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errOfferChanged
+		}
+
+		message := fmt.Sprintf("Você recebeu uma proposta para a vaga %s", ctx.JobTitle)
+		if err := createNotification(tx, ctx.Candidate.UserID, "offer_received", message, ctx.ApplicationID); err != nil {
+			return err
+		}
+
+		return enqueueEmail(tx, Email{
+			To:      []string{ctx.Candidate.Email},
+			Subject: fmt.Sprintf("Proposta - %s (%s)", ctx.JobTitle, ctx.Company),
+			Body: fmt.Sprintf("Olá, %s.\n\nSua proposta para a vaga %s está anexada. Ela é válida até %s (UTC); responda pela sua candidatura no sistema.",
+				ctx.Candidate.Name, ctx.JobTitle, offer.ExpiresAt.Format("02/01/2006 15:04")),
+			Attachments: []EmailAttachment{{
+				Filename:    "proposta.html",
+				ContentType: "text/html; charset=UTF-8",
+				Data:        offerLetterHTML(letter, ctx),
+			}},
+		})
+	})
+	if errors.Is(err, errOfferChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao enviar proposta"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Proposta enviada ao candidato"})
+}
+
+// withdrawOfferHandler cancela uma proposta que ainda não foi respondida
+func withdrawOfferHandler(c *gin.Context) {
+	offer, ctx, ok := requireOfferHiringAccess(c)
+	if !ok {
+		return
+	}
+
+	switch offer.Status {
+	case "draft", "pending_approval", "approved", "sent":
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta proposta não pode mais ser retirada"})
+		return
+	}
+
+	err := withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec("UPDATE offers SET status = 'withdrawn', updated_at = ? WHERE id = ? AND status = ?", time.Now(), offer.ID, offer.Status)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errOfferChanged
+		}
+
+		if offer.Status != "sent" {
+			return nil
+		}
+		message := fmt.Sprintf("A proposta para a vaga %s foi retirada", ctx.JobTitle)
+		return createNotification(tx, ctx.Candidate.UserID, "offer_withdrawn", message, ctx.ApplicationID)
+	})
+	if errors.Is(err, errOfferChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao retirar proposta"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Proposta retirada com sucesso"})
+}
+
+// respondToOffer aplica a resposta do candidato a uma proposta enviada e ainda válida
+func respondToOffer(c *gin.Context, accept bool) {
+	var req WithdrawRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	offer, ctx, _, ok := requireOfferAccess(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	if ctx.Candidate.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas o candidato pode responder à proposta"})
+		return
+	}
+
+	if offer.Status == "expired" {
+		c.JSON(http.StatusGone, gin.H{"error": errOfferExpired.Error()})
+		return
+	}
+	if offer.Status != "sent" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Esta proposta já foi respondida"})
+		return
+	}
+
+	status := "accepted"
+	if !accept {
+		status = "declined"
+	}
+	now := time.Now()
+
+	err := withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE offers SET status = ?, decline_reason = ?, responded_at = ?, updated_at = ?
+			WHERE id = ? AND status = 'sent' AND expires_at > ?`,
+			status, strings.TrimSpace(req.Reason), now, now, offer.ID, now.UTC())
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			if !offer.ExpiresAt.After(now) {
+				return errOfferExpired
+			}
+			return errOfferChanged
+		}
+
+		if accept {
+			var appStatus string
+			if err := tx.QueryRow("SELECT status FROM applications WHERE id = ?", ctx.ApplicationID).Scan(&appStatus); err != nil {
+				return err
+			}
+			if appStatus == "withdrawn" || appStatus == "hired" {
+				return errApplicationChanged
+			}
+			if _, err := tx.Exec("UPDATE applications SET status = 'hired', updated_at = ? WHERE id = ?", now, ctx.ApplicationID); err != nil {
+				return err
+			}
+			if err := recordStatusChange(tx, ctx.ApplicationID, appStatus, "hired", "Proposta aceita", userID); err != nil {
+				return err
+			}
+		}
+
+		message := fmt.Sprintf("%s aceitou a proposta para a vaga %s", ctx.Candidate.Name, ctx.JobTitle)
+		if !accept {
+			message = fmt.Sprintf("%s recusou a proposta para a vaga %s", ctx.Candidate.Name, ctx.JobTitle)
+		}
+		notify := []int{offer.CreatedBy}
+		if ctx.Organizer.UserID != offer.CreatedBy {
+			notify = append(notify, ctx.Organizer.UserID)
+		}
+		for _, id := range notify {
+			if err := createNotification(tx, id, "offer_"+status, message, ctx.ApplicationID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errOfferExpired) {
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errOfferChanged) || errors.Is(err, errApplicationChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao responder proposta"})
+		return
+	}
+
+	if accept {
+		c.JSON(http.StatusOK, gin.H{"message": "Proposta aceita. Parabéns!", "application_status": "hired"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Proposta recusada"})
+}
+
+func acceptOfferHandler(c *gin.Context) {
+	respondToOffer(c, true)
+}
+
+func declineOfferHandler(c *gin.Context) {
+	respondToOffer(c, false)
+}
+
+// getOfferLetterHandler devolve a carta da proposta em HTML (ou Markdown com
+// ?format=markdown). Antes do envio, a equipe recebe uma prévia gerada na hora.
+func getOfferLetterHandler(c *gin.Context) {
+	offer, ctx, _, ok := requireOfferAccess(c)
+	if !ok {
+		return
+	}
+
+	var letter string
+	db.QueryRow("SELECT letter FROM offers WHERE id = ?", offer.ID).Scan(&letter)
+	if letter == "" {
+		var name string
+		db.QueryRow("SELECT name FROM users WHERE id = ?", c.GetInt("user_id")).Scan(&name)
+		letter = renderOfferLetter(offer, ctx, name)
+	}
+
+	if c.Query("format") == "markdown" {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(letter))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", offerLetterHTML(letter, ctx))
+}
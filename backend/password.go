@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// PasswordPolicy define as regras aplicadas no cadastro, troca e redefinição de senha
+type PasswordPolicy struct {
+	MinLength      int
+	MaxLength      int // em bytes, limitado a bcryptMaxBytes
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSymbol  bool
+	RejectPersonal bool
+}
+
+// bcrypt não aceita senhas com mais de 72 bytes
+const bcryptMaxBytes = 72
+
+// Política carregada das variáveis de ambiente na inicialização
+var passwordPolicy = PasswordPolicy{
+	MinLength:      getEnvInt("PASSWORD_MIN_LENGTH", 8),
+	MaxLength:      min(getEnvInt("PASSWORD_MAX_LENGTH", bcryptMaxBytes), bcryptMaxBytes),
+	RequireUpper:   getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+	RequireLower:   getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+	RequireDigit:   getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+	RequireSymbol:  getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+	RejectPersonal: getEnvBool("PASSWORD_REJECT_PERSONAL", true),
+}
+
+// Senhas mais comuns, sempre rejeitadas mesmo sem arquivo de senhas vazadas
+var commonPasswords = []string{
+	"123456", "123456789", "12345678", "password", "qwerty123", "senha123",
+	"password1", "12345", "1234567890", "admin123", "Password1", "Senha123",
+	"abc12345", "iloveyou", "111111", "qwerty", "Qwerty123", "welcome1",
+	"Welcome1", "Mudar123", "P@ssw0rd", "Passw0rd",
+}
+
+// breachedPasswords guarda hashes SHA-1 agrupados pelos 5 primeiros caracteres,
+// no mesmo formato de k-anonimato usado pelo Have I Been Pwned.
+type breachedPasswords struct {
+	once    sync.Once
+	buckets map[string]map[string]bool
+}
+
+var breached = &breachedPasswords{}
+
+func sha1Hex(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func (b *breachedPasswords) add(hash string) {
+	hash = strings.ToUpper(strings.TrimSpace(hash))
+	if len(hash) != 40 {
+		return
+	}
+	prefix, suffix := hash[:5], hash[5:]
+	if b.buckets[prefix] == nil {
+		b.buckets[prefix] = make(map[string]bool)
+	}
+	b.buckets[prefix][suffix] = true
+}
+
+// load lê o arquivo indicado em BREACHED_PASSWORDS_FILE. Cada linha contém o
+// hash SHA-1 da senha, opcionalmente seguido de ":contagem".
+func (b *breachedPasswords) load() {
+	b.buckets = make(map[string]map[string]bool)
+	for _, password := range commonPasswords {
+		b.add(sha1Hex(password))
+	}
+
+	path := getEnv("BREACHED_PASSWORDS_FILE", "")
+	if path == "" {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Não foi possível abrir a lista de senhas vazadas: %v", err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		b.add(line)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("Erro ao ler a lista de senhas vazadas: %v", err)
+	}
+}
+
+func (b *breachedPasswords) contains(password string) bool {
+	b.once.Do(b.load)
+	hash := sha1Hex(password)
+	return b.buckets[hash[:5]][hash[5:]]
+}
+
+// validatePassword verifica a senha contra a política e a lista de senhas vazadas.
+// email e name são usados para impedir senhas com dados pessoais.
+func validatePassword(password, email, name string) error {
+	p := passwordPolicy
+
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("A senha deve ter pelo menos %d caracteres", p.MinLength)
+	}
+	// O limite é em bytes: acentos e emojis ocupam mais de um
+	if maxLength := min(p.MaxLength, bcryptMaxBytes); maxLength > 0 && len(password) > maxLength {
+		return fmt.Errorf("A senha deve ter no máximo %d bytes (caracteres acentuados contam como mais de um)", maxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return fmt.Errorf("A senha deve conter pelo menos uma letra maiúscula")
+	}
+	if p.RequireLower && !hasLower {
+		return fmt.Errorf("A senha deve conter pelo menos uma letra minúscula")
+	}
+	if p.RequireDigit && !hasDigit {
+		return fmt.Errorf("A senha deve conter pelo menos um número")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return fmt.Errorf("A senha deve conter pelo menos um símbolo")
+	}
+
+	if p.RejectPersonal && containsPersonalData(password, email, name) {
+		return fmt.Errorf("A senha não pode conter seu email ou nome")
+	}
+
+	if breached.contains(password) {
+		return fmt.Errorf("Esta senha apareceu em vazamentos de dados conhecidos, escolha outra")
+	}
+
+	return nil
+}
+
+func containsPersonalData(password, email, name string) bool {
+	lower := strings.ToLower(password)
+
+	parts := strings.Fields(strings.ToLower(name))
+	if local, _, found := strings.Cut(strings.ToLower(email), "@"); found {
+		parts = append(parts, local)
+	}
+
+	for _, part := range parts {
+		// Partes muito curtas geram falsos positivos
+		if len([]rune(part)) >= 3 && strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
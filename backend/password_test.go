@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	defaultPolicy := passwordPolicy
+	t.Cleanup(func() { passwordPolicy = defaultPolicy })
+
+	strict := defaultPolicy
+	strict.RequireSymbol = true
+
+	tooLong := "Ab1" + strings.Repeat("x", 70)
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		email    string
+		userName string
+		wantErr  string
+	}{
+		{name: "válida", policy: defaultPolicy, password: "Xk9#vtQ2plm"},
+		{name: "curta", policy: defaultPolicy, password: "Xk9#v", wantErr: "pelo menos 8"},
+		{name: "sem maiúscula", policy: defaultPolicy, password: "xk9#vtq2plm", wantErr: "maiúscula"},
+		{name: "sem minúscula", policy: defaultPolicy, password: "XK9#VTQ2PLM", wantErr: "minúscula"},
+		{name: "sem número", policy: defaultPolicy, password: "Xkz#vtQwplm", wantErr: "número"},
+		{name: "sem símbolo", policy: strict, password: "Xk9vtQ2plmz", wantErr: "símbolo"},
+		{name: "com símbolo", policy: strict, password: "Xk9 vtQ2plm"},
+		{name: "contém o email", policy: defaultPolicy, password: "Joana2024!x", email: "joana@x.com", wantErr: "email ou nome"},
+		{name: "contém o nome", policy: defaultPolicy, password: "Xk9silvaQ", userName: "Ana Silva", wantErr: "email ou nome"},
+		{name: "parte curta do nome é ignorada", policy: defaultPolicy, password: "Xk9#anQ2plm", userName: "An Li"},
+		{name: "senha comum", policy: defaultPolicy, password: "Password1", wantErr: "vazamentos"},
+		{name: "72 bytes", policy: defaultPolicy, password: tooLong[:72]},
+		{name: "73 bytes", policy: defaultPolicy, password: tooLong, wantErr: "no máximo 72 bytes"},
+		{name: "acentos contam em bytes", policy: defaultPolicy, password: "Ab1" + strings.Repeat("é", 35), wantErr: "no máximo 72 bytes"},
+		{name: "máximo configurado acima do bcrypt", policy: PasswordPolicy{MinLength: 1, MaxLength: 100}, password: tooLong, wantErr: "no máximo 72 bytes"},
+		{name: "máximo configurado menor", policy: PasswordPolicy{MinLength: 1, MaxLength: 10}, password: "Xk9#vtQ2plm", wantErr: "no máximo 10 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passwordPolicy = tt.policy
+			err := validatePassword(tt.password, tt.email, tt.userName)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want erro contendo %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBreachedPasswords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	lines := []string{
+		sha1Hex("Vazada#2024") + ":1532",
+		strings.ToLower(sha1Hex("outra-Vazada9")),
+		"linha inválida",
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BREACHED_PASSWORDS_FILE", path)
+
+	b := &breachedPasswords{}
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"Vazada#2024", true},
+		{"outra-Vazada9", true},
+		{"P@ssw0rd", true},
+		{"Xk9#vtQ2plm", false},
+		{"vazada#2024", false},
+	}
+
+	for _, tt := range tests {
+		if got := b.contains(tt.password); got != tt.want {
+			t.Errorf("contains(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
@@ -2,47 +2,92 @@ package main
 
 import (
 	"net/http"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func getProfileHandler(c *gin.Context) {
-	userID := c.GetInt("user_id")
+var phonePattern = regexp.MustCompile(`^\+?[0-9 ().-]{8,20}$`)
 
+// loadProfile monta o perfil completo do candidato com experiências,
+// formação, habilidades e links
+func loadProfile(userID int) (gin.H, error) {
 	var user User
 	err := db.QueryRow(`
-		SELECT id, email, name, created_at, updated_at FROM users WHERE id = ?`, userID).Scan(
-		&user.ID, &user.Email, &user.Name, &user.CreatedAt, &user.UpdatedAt)
-	
+		SELECT id, email, name, headline, summary, location, phone, created_at, updated_at
+		FROM users WHERE id = ?`, userID).Scan(
+		&user.ID, &user.Email, &user.Name, &user.Headline, &user.Summary, &user.Location,
+		&user.Phone, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	experiences, err := listExperiences(userID)
+	if err != nil {
+		return nil, err
+	}
+	educations, err := listEducations(userID)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := listUserSkills(userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := listProfileLinks(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"id":          user.ID,
+		"email":       user.Email,
+		"name":        user.Name,
+		"headline":    user.Headline,
+		"summary":     user.Summary,
+		"location":    user.Location,
+		"phone":       user.Phone,
+		"experiences": experiences,
+		"educations":  educations,
+		"skills":      skills,
+		"links":       links,
+		"created_at":  user.CreatedAt,
+		"updated_at":  user.UpdatedAt,
+	}, nil
+}
+
+func getProfileHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	profile, err := loadProfile(userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"user": gin.H{
-			"id":         user.ID,
-			"email":      user.Email,
-			"name":       user.Name,
-			"created_at": user.CreatedAt,
-			"updated_at": user.UpdatedAt,
-		},
-	})
+	c.JSON(http.StatusOK, gin.H{"user": profile})
 }
 
 func updateProfileHandler(c *gin.Context) {
 	userID := c.GetInt("user_id")
 
-	var req struct {
-		Name string `json:"name" binding:"required"`
-	}
+	var req ProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	_, err := db.Exec("UPDATE users SET name = ?, updated_at = ? WHERE id = ?", req.Name, time.Now(), userID)
+	if req.Phone != "" && !phonePattern.MatchString(req.Phone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Telefone inválido"})
+		return
+	}
+
+	_, err := db.Exec(`
+		UPDATE users SET name = ?, headline = ?, summary = ?, location = ?, phone = ?, updated_at = ?
+		WHERE id = ?`,
+		req.Name, req.Headline, req.Summary, req.Location, req.Phone, time.Now(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar perfil"})
 		return
@@ -50,3 +95,75 @@ func updateProfileHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Perfil atualizado com sucesso"})
 }
+
+// getCandidateProfileHandler permite que o dono de uma vaga veja o perfil
+// de quem se candidatou a ela
+func getCandidateProfileHandler(c *gin.Context) {
+	candidateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	if candidateID != userID {
+		var count int
+		err = db.QueryRow(`
+			SELECT COUNT(*) FROM applications a
+			JOIN jobs j ON a.job_id = j.id
+			WHERE a.user_id = ? AND `+hiringSideSQL, candidateID, userID, userID).Scan(&count)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar perfil"})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para ver este perfil"})
+			return
+		}
+	}
+
+	profile, err := loadProfile(candidateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": profile})
+}
+
+// Preferências usadas na recomendação de vagas. A pretensão salarial não
+// aparece no perfil visto pela equipe de contratação.
+
+func getJobPreferencesHandler(c *gin.Context) {
+	var prefs JobPreferences
+	err := db.QueryRow(`
+		SELECT desired_salary, seniority, work_mode, open_to_work
+		FROM users WHERE id = ?`, c.GetInt("user_id")).Scan(
+		&prefs.DesiredSalary, &prefs.Seniority, &prefs.WorkMode, &prefs.OpenToWork)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+func updateJobPreferencesHandler(c *gin.Context) {
+	var req JobPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, err := db.Exec(`
+		UPDATE users SET desired_salary = ?, seniority = ?, work_mode = ?, open_to_work = ?, updated_at = ?
+		WHERE id = ?`,
+		req.DesiredSalary, req.Seniority, req.WorkMode, req.OpenToWork, time.Now(), c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar preferências"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Preferências atualizadas com sucesso"})
+}
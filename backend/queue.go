@@ -0,0 +1,451 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// backgroundJobHandler executa uma tarefa da fila com o payload gravado ao
+// enfileirá-la. Um erro faz a tarefa ser tentada de novo mais tarde.
+type backgroundJobHandler func(payload []byte) error
+
+// Tarefas conhecidas pelos workers
+var backgroundJobHandlers = map[string]backgroundJobHandler{
+	"email.send":                   runEmailJob,
+	"jobs.purge":                   runJobPurge,
+	"queue.cleanup":                runQueueCleanup,
+	"saved_jobs.closing_reminders": runSavedJobReminders,
+	"job_alerts.run":               runJobAlerts,
+	"candidate_messages.send":      runCandidateMessages,
+	"threads.email_fallback":       runThreadEmailFallback,
+	"notifications.dispatch":       runNotificationDispatch,
+	"webhooks.deliver":             runWebhookDeliveries,
+}
+
+// scheduledTask enfileira a tarefa Kind a cada Interval. O próximo horário fica
+// no banco, então só uma instância do servidor enfileira cada execução.
+type scheduledTask struct {
+	Kind     string
+	Interval time.Duration
+}
+
+var scheduledTasks = []scheduledTask{
+	{Kind: "jobs.purge", Interval: time.Duration(getEnvInt("JOB_PURGE_INTERVAL_MINUTES", 60)) * time.Minute},
+	{Kind: "queue.cleanup", Interval: time.Hour},
+	{Kind: "saved_jobs.closing_reminders", Interval: time.Hour},
+	{Kind: "job_alerts.run", Interval: time.Hour},
+	{Kind: "candidate_messages.send", Interval: time.Duration(getEnvInt("MESSAGE_SENDER_INTERVAL_SECONDS", 60)) * time.Second},
+	{Kind: "threads.email_fallback", Interval: time.Duration(getEnvInt("MESSAGE_SENDER_INTERVAL_SECONDS", 60)) * time.Second},
+	{Kind: "notifications.dispatch", Interval: time.Duration(getEnvInt("NOTIFICATION_DISPATCH_INTERVAL_SECONDS", 30)) * time.Second},
+	{Kind: "webhooks.deliver", Interval: time.Duration(getEnvInt("WEBHOOK_WORKER_INTERVAL_SECONDS", 5)) * time.Second},
+}
+
+var (
+	queueMaxAttempts = getEnvInt("QUEUE_MAX_ATTEMPTS", 5)
+	// Espera antes da segunda tentativa; dobra a cada nova falha
+	queueRetryBase = time.Duration(getEnvInt("QUEUE_RETRY_BASE_SECONDS", 30)) * time.Second
+	// Tempo que um worker tem para concluir a tarefa antes que outro a assuma.
+	// Enquanto a tarefa roda, o lease é renovado.
+	queueLease        = time.Duration(getEnvInt("QUEUE_LEASE_SECONDS", 60)) * time.Second
+	queuePollInterval = time.Duration(getEnvInt("QUEUE_POLL_INTERVAL_SECONDS", 1)) * time.Second
+	// Tarefas concluídas ficam guardadas por este período para consulta
+	queueRetention = time.Duration(getEnvInt("QUEUE_RETENTION_DAYS", 7)) * 24 * time.Hour
+)
+
+const queueMaxBackoff = time.Hour
+
+var errUnknownBackgroundJob = errors.New("Tipo de tarefa desconhecido")
+
+// enqueueBackgroundJob grava a tarefa para execução imediata. Chamada com uma
+// transação, a tarefa só passa a existir se a transação for confirmada.
+func enqueueBackgroundJob(q dbtx, kind string, payload interface{}) error {
+	return enqueueBackgroundJobAt(q, kind, payload, time.Now().UTC())
+}
+
+// enqueueBackgroundJobAt grava a tarefa para ser executada a partir de runAt
+func enqueueBackgroundJobAt(q dbtx, kind string, payload interface{}, runAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	_, err = q.Exec(`
+		INSERT INTO background_jobs (kind, payload, max_attempts, run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, kind, string(data), queueMaxAttempts, runAt.UTC(), now, now)
+	return err
+}
+
+// enqueueEmail agenda o envio do email pela fila
+func enqueueEmail(q dbtx, msg Email) error {
+	return enqueueBackgroundJob(q, "email.send", msg)
+}
+
+func runEmailJob(payload []byte) error {
+	var msg Email
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return err
+	}
+	return mailer.Send(msg)
+}
+
+func runJobPurge(payload []byte) error {
+	purgeExpiredJobs()
+	return nil
+}
+
+// runQueueCleanup apaga as tarefas concluídas há mais de QUEUE_RETENTION_DAYS.
+// As mortas ficam até serem reenfileiradas ou apagadas manualmente.
+func runQueueCleanup(payload []byte) error {
+	_, err := db.Exec("DELETE FROM background_jobs WHERE status = 'succeeded' AND finished_at < ?",
+		time.Now().UTC().Add(-queueRetention))
+	return err
+}
+
+// queueBackoff devolve a espera antes da próxima tentativa, depois de attempts
+// tentativas que falharam
+func queueBackoff(attempts int) time.Duration {
+	backoff := queueRetryBase
+	for i := 1; i < attempts && backoff < queueMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > queueMaxBackoff {
+		backoff = queueMaxBackoff
+	}
+	return backoff
+}
+
+const backgroundJobColumns = `id, kind, payload, status, attempts, max_attempts, run_at, lease_owner,
+	lease_expires_at, last_error, created_at, updated_at, finished_at`
+
+func scanBackgroundJob(row interface{ Scan(...interface{}) error }) (*BackgroundJob, error) {
+	var job BackgroundJob
+	var leaseExpiresAt, finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.Kind, &job.Payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt,
+		&job.LeaseOwner, &leaseExpiresAt, &job.LastError, &job.CreatedAt, &job.UpdatedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if leaseExpiresAt.Valid {
+		job.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// claimBackgroundJob reserva a próxima tarefa disponível para o worker: uma
+// pendente cujo horário chegou ou uma cujo lease expirou (o worker anterior
+// caiu). Tarefas com lease expirado que já esgotaram as tentativas vão para as
+// mortas.
+func claimBackgroundJob(workerID string) (*BackgroundJob, error) {
+	token, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	owner := workerID + "/" + token
+
+	var job *BackgroundJob
+	err = withTx(func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		_, err := tx.Exec(`
+			UPDATE background_jobs SET status = 'dead', lease_owner = '', lease_expires_at = NULL,
+			       last_error = 'O worker parou durante a execução', finished_at = ?, updated_at = ?
+			WHERE status = 'running' AND lease_expires_at <= ? AND attempts >= max_attempts`, now, now, now)
+		if err != nil {
+			return err
+		}
+
+		var id int
+		err = tx.QueryRow(`
+			SELECT id FROM background_jobs
+			WHERE (status = 'pending' AND run_at <= ?) OR (status = 'running' AND lease_expires_at <= ?)
+			ORDER BY run_at, id
+			LIMIT 1`, now, now).Scan(&id)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE background_jobs SET status = 'running', attempts = attempts + 1, lease_owner = ?,
+			       lease_expires_at = ?, updated_at = ?
+			WHERE id = ?`, owner, now.Add(queueLease), now, id)
+		if err != nil {
+			return err
+		}
+
+		job, err = scanBackgroundJob(tx.QueryRow("SELECT "+backgroundJobColumns+" FROM background_jobs WHERE id = ?", id))
+		return err
+	})
+	return job, err
+}
+
+// runBackgroundJob executa a tarefa renovando o lease enquanto ela roda
+func runBackgroundJob(job *BackgroundJob) error {
+	handler, ok := backgroundJobHandlers[job.Kind]
+	if !ok {
+		return errUnknownBackgroundJob
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(queueLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				db.Exec("UPDATE background_jobs SET lease_expires_at = ? WHERE id = ? AND lease_owner = ?",
+					time.Now().UTC().Add(queueLease), job.ID, job.LeaseOwner)
+			}
+		}
+	}()
+
+	return safeRunBackgroundJob(handler, []byte(job.Payload))
+}
+
+// safeRunBackgroundJob transforma um panic da tarefa em erro, para que o
+// worker continue
+func safeRunBackgroundJob(handler backgroundJobHandler, payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(payload)
+}
+
+// finishBackgroundJob grava o resultado. Com erro, a tarefa volta para a fila
+// com espera exponencial ou, esgotadas as tentativas, vai para as mortas. Se o
+// lease tiver passado para outro worker, o resultado é descartado.
+func finishBackgroundJob(job *BackgroundJob, runErr error) error {
+	now := time.Now().UTC()
+	if runErr == nil {
+		// O corpo dos emails enviados (links de redefinição de senha, propostas)
+		// não fica guardado durante a retenção
+		_, err := db.Exec(`
+			UPDATE background_jobs SET status = 'succeeded', lease_owner = '', lease_expires_at = NULL,
+			       last_error = '', finished_at = ?, updated_at = ?,
+			       payload = CASE WHEN kind = 'email.send' THEN 'null' ELSE payload END
+			WHERE id = ? AND lease_owner = ?`, now, now, job.ID, job.LeaseOwner)
+		return err
+	}
+
+	if errors.Is(runErr, errUnknownBackgroundJob) || job.Attempts >= job.MaxAttempts {
+		log.Printf("Tarefa %d (%s) movida para as mortas após %d tentativa(s): %v", job.ID, job.Kind, job.Attempts, runErr)
+		_, err := db.Exec(`
+			UPDATE background_jobs SET status = 'dead', lease_owner = '', lease_expires_at = NULL,
+			       last_error = ?, finished_at = ?, updated_at = ?
+			WHERE id = ? AND lease_owner = ?`, runErr.Error(), now, now, job.ID, job.LeaseOwner)
+		return err
+	}
+
+	_, err := db.Exec(`
+		UPDATE background_jobs SET status = 'pending', lease_owner = '', lease_expires_at = NULL,
+		       last_error = ?, run_at = ?, updated_at = ?
+		WHERE id = ? AND lease_owner = ?`, runErr.Error(), now.Add(queueBackoff(job.Attempts)), now, job.ID, job.LeaseOwner)
+	return err
+}
+
+// enqueueScheduledTasks enfileira as tarefas periódicas cujo horário chegou
+func enqueueScheduledTasks() {
+	now := time.Now().UTC()
+	for _, task := range scheduledTasks {
+		var nextRunAt time.Time
+		err := db.QueryRow("SELECT next_run_at FROM scheduled_tasks WHERE kind = ?", task.Kind).Scan(&nextRunAt)
+		if err != nil && err != sql.ErrNoRows {
+			log.Printf("Erro ao consultar tarefa periódica %s: %v", task.Kind, err)
+			continue
+		}
+		if err == nil && nextRunAt.After(now) {
+			continue
+		}
+
+		// A condição no UPDATE garante que, entre instâncias, só uma enfileira
+		// esta execução
+		err = withTx(func(tx *sql.Tx) error {
+			result, err := tx.Exec(`
+				INSERT INTO scheduled_tasks (kind, next_run_at, last_enqueued_at) VALUES (?, ?, ?)
+				ON CONFLICT (kind) DO UPDATE SET next_run_at = excluded.next_run_at, last_enqueued_at = excluded.last_enqueued_at
+				WHERE scheduled_tasks.next_run_at <= ?`, task.Kind, now.Add(task.Interval), now, now)
+			if err != nil {
+				return err
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				return nil
+			}
+			return enqueueBackgroundJob(tx, task.Kind, nil)
+		})
+		if err != nil {
+			log.Printf("Erro ao enfileirar tarefa periódica %s: %v", task.Kind, err)
+		}
+	}
+}
+
+// startQueueWorkers inicia QUEUE_WORKERS workers e o agendador das tarefas periódicas
+func startQueueWorkers() {
+	host, _ := os.Hostname()
+	workers := getEnvInt("QUEUE_WORKERS", 2)
+
+	for i := 1; i <= workers; i++ {
+		workerID := fmt.Sprintf("%s:%d:%d", host, os.Getpid(), i)
+		go func() {
+			for {
+				job, err := claimBackgroundJob(workerID)
+				if err != nil {
+					log.Printf("Erro ao buscar tarefa da fila: %v", err)
+				}
+				if job == nil {
+					time.Sleep(queuePollInterval)
+					continue
+				}
+
+				runErr := runBackgroundJob(job)
+				if err := finishBackgroundJob(job, runErr); err != nil {
+					log.Printf("Erro ao registrar resultado da tarefa %d: %v", job.ID, err)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		for {
+			enqueueScheduledTasks()
+			time.Sleep(queuePollInterval)
+		}
+	}()
+}
+
+// getBackgroundJobsHandler lista as tarefas da fila (?status=, ?kind=) e a
+// contagem por status
+func getBackgroundJobsHandler(c *gin.Context) {
+	query := "SELECT " + backgroundJobColumns + " FROM background_jobs WHERE 1 = 1"
+	args := []interface{}{}
+	if status := c.Query("status"); status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	if kind := c.Query("kind"); kind != "" {
+		query += " AND kind = ?"
+		args = append(args, kind)
+	}
+	query += " ORDER BY id DESC LIMIT 100"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar tarefas"})
+		return
+	}
+	defer rows.Close()
+
+	jobs := []BackgroundJob{}
+	for rows.Next() {
+		job, err := scanBackgroundJob(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar tarefas"})
+			return
+		}
+		jobs = append(jobs, *job)
+	}
+
+	counts := gin.H{"pending": 0, "running": 0, "succeeded": 0, "dead": 0}
+	countRows, err := db.Query("SELECT status, COUNT(*) FROM background_jobs GROUP BY status")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar tarefas"})
+		return
+	}
+	defer countRows.Close()
+	for countRows.Next() {
+		var status string
+		var n int
+		if err := countRows.Scan(&status, &n); err == nil {
+			counts[status] = n
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs, "counts": counts})
+}
+
+// backgroundJobPayloadView devolve o payload exibido aos administradores. De
+// emails aparecem apenas destinatários, assunto e nomes dos anexos: o corpo
+// pode conter tokens de redefinição de senha e dados de candidatos.
+func backgroundJobPayloadView(job *BackgroundJob) interface{} {
+	if job.Kind == "email.send" {
+		var msg Email
+		if err := json.Unmarshal([]byte(job.Payload), &msg); err != nil || msg.To == nil {
+			return nil
+		}
+		attachments := []string{}
+		for _, a := range msg.Attachments {
+			attachments = append(attachments, a.Filename)
+		}
+		return gin.H{"to": msg.To, "subject": msg.Subject, "attachments": attachments}
+	}
+
+	if !json.Valid([]byte(job.Payload)) {
+		return nil
+	}
+	return json.RawMessage(job.Payload)
+}
+
+func getBackgroundJobHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	job, err := scanBackgroundJob(db.QueryRow("SELECT "+backgroundJobColumns+" FROM background_jobs WHERE id = ?", jobID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarefa não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job, "payload": backgroundJobPayloadView(job)})
+}
+
+// retryBackgroundJobHandler devolve uma tarefa morta à fila, com as
+// tentativas zeradas
+func retryBackgroundJobHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	now := time.Now().UTC()
+	result, err := db.Exec(`
+		UPDATE background_jobs SET status = 'pending', attempts = 0, run_at = ?, finished_at = NULL, updated_at = ?
+		WHERE id = ? AND status = 'dead'`, now, now, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao reenfileirar tarefa"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		var status string
+		if err := db.QueryRow("SELECT status FROM background_jobs WHERE id = ?", jobID).Scan(&status); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tarefa não encontrada"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Apenas tarefas mortas podem ser reenfileiradas"})
+		return
+	}
+
+	job, _ := scanBackgroundJob(db.QueryRow("SELECT "+backgroundJobColumns+" FROM background_jobs WHERE id = ?", jobID))
+	c.JSON(http.StatusOK, gin.H{"message": "Tarefa reenfileirada", "job": job})
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupTestDB troca o banco global por um banco vazio em um diretório temporário
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	previous := db
+	openDB(filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(func() {
+		db.Close()
+		db = previous
+	})
+}
+
+func TestQueueBackoff(t *testing.T) {
+	defer func(base time.Duration) { queueRetryBase = base }(queueRetryBase)
+	queueRetryBase = 30 * time.Second
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, queueMaxBackoff},
+		{1000, queueMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := queueBackoff(tt.attempts); got != tt.want {
+			t.Errorf("queueBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func loadBackgroundJob(t *testing.T, id int) *BackgroundJob {
+	t.Helper()
+	job, err := scanBackgroundJob(db.QueryRow("SELECT "+backgroundJobColumns+" FROM background_jobs WHERE id = ?", id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func mustClaim(t *testing.T, workerID string) *BackgroundJob {
+	t.Helper()
+	job, err := claimBackgroundJob(workerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func expireLease(t *testing.T, id int) {
+	t.Helper()
+	if _, err := db.Exec("UPDATE background_jobs SET lease_expires_at = ? WHERE id = ?", time.Now().UTC().Add(-time.Second), id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClaimBackgroundJob(t *testing.T) {
+	setupTestDB(t)
+
+	if err := enqueueBackgroundJobAt(db, "email.send", nil, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if job := mustClaim(t, "w1"); job != nil {
+		t.Fatalf("tarefa agendada para o futuro foi reservada: %+v", job)
+	}
+
+	if err := enqueueBackgroundJob(db, "email.send", map[string]string{"to": "a@x.com"}); err != nil {
+		t.Fatal(err)
+	}
+	first := mustClaim(t, "w1")
+	if first == nil {
+		t.Fatal("nenhuma tarefa reservada")
+	}
+	if first.Status != "running" || first.Attempts != 1 || first.LeaseOwner == "" || first.LeaseExpiresAt == nil {
+		t.Fatalf("reserva inesperada: %+v", first)
+	}
+	if first.Payload != `{"to":"a@x.com"}` {
+		t.Errorf("payload = %s", first.Payload)
+	}
+
+	// Enquanto o lease vale, nenhum outro worker pega a tarefa
+	if job := mustClaim(t, "w2"); job != nil {
+		t.Fatalf("tarefa com lease válido foi reservada de novo: %+v", job)
+	}
+
+	// Com o lease expirado, outro worker assume e o resultado do primeiro é descartado
+	expireLease(t, first.ID)
+	second := mustClaim(t, "w2")
+	if second == nil || second.ID != first.ID || second.Attempts != 2 || second.LeaseOwner == first.LeaseOwner {
+		t.Fatalf("tarefa não foi assumida pelo segundo worker: %+v", second)
+	}
+	if err := finishBackgroundJob(first, nil); err != nil {
+		t.Fatal(err)
+	}
+	if job := loadBackgroundJob(t, first.ID); job.Status != "running" || job.LeaseOwner != second.LeaseOwner {
+		t.Fatalf("resultado do worker sem lease foi gravado: %+v", job)
+	}
+
+	// Falha volta para a fila com espera
+	if err := finishBackgroundJob(second, errors.New("smtp fora do ar")); err != nil {
+		t.Fatal(err)
+	}
+	job := loadBackgroundJob(t, first.ID)
+	if job.Status != "pending" || job.LastError != "smtp fora do ar" || job.LeaseOwner != "" {
+		t.Fatalf("falha não devolveu a tarefa à fila: %+v", job)
+	}
+	if wait := time.Until(job.RunAt); wait < queueBackoff(2)-time.Minute || wait > queueBackoff(2)+time.Minute {
+		t.Errorf("nova tentativa em %v, want cerca de %v", wait, queueBackoff(2))
+	}
+	if claimed := mustClaim(t, "w1"); claimed != nil {
+		t.Fatalf("tarefa reservada antes da espera: %+v", claimed)
+	}
+
+	// Sucesso
+	db.Exec("UPDATE background_jobs SET run_at = ? WHERE id = ?", time.Now().UTC().Add(-time.Second), first.ID)
+	third := mustClaim(t, "w1")
+	if third == nil || third.Attempts != 3 {
+		t.Fatalf("tarefa não foi reservada após a espera: %+v", third)
+	}
+	if err := finishBackgroundJob(third, nil); err != nil {
+		t.Fatal(err)
+	}
+	if job := loadBackgroundJob(t, first.ID); job.Status != "succeeded" || job.FinishedAt == nil || job.LastError != "" {
+		t.Fatalf("sucesso não registrado: %+v", job)
+	}
+}
+
+func TestClaimBackgroundJobDead(t *testing.T) {
+	setupTestDB(t)
+	defer func(n int) { queueMaxAttempts = n }(queueMaxAttempts)
+	queueMaxAttempts = 1
+
+	tests := []struct {
+		name   string
+		finish func(job *BackgroundJob)
+		kind   string
+	}{
+		{
+			name: "tentativas esgotadas",
+			kind: "email.send",
+			finish: func(job *BackgroundJob) {
+				if err := finishBackgroundJob(job, errors.New("falhou")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name:   "worker caiu na última tentativa",
+			kind:   "email.send",
+			finish: func(job *BackgroundJob) { expireLease(t, job.ID) },
+		},
+		{
+			name: "tipo desconhecido",
+			kind: "nao.existe",
+			finish: func(job *BackgroundJob) {
+				if err := finishBackgroundJob(job, runBackgroundJob(job)); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := enqueueBackgroundJob(db, tt.kind, nil); err != nil {
+				t.Fatal(err)
+			}
+			job := mustClaim(t, "w1")
+			if job == nil {
+				t.Fatal("nenhuma tarefa reservada")
+			}
+			tt.finish(job)
+
+			if claimed := mustClaim(t, "w2"); claimed != nil {
+				t.Fatalf("tarefa morta foi reservada: %+v", claimed)
+			}
+			if job := loadBackgroundJob(t, job.ID); job.Status != "dead" || job.FinishedAt == nil || job.LastError == "" {
+				t.Fatalf("tarefa não foi para as mortas: %+v", job)
+			}
+		})
+	}
+}
+
+func TestEnqueueScheduledTasks(t *testing.T) {
+	setupTestDB(t)
+	defer func(tasks []scheduledTask) { scheduledTasks = tasks }(scheduledTasks)
+	scheduledTasks = []scheduledTask{
+		{Kind: "queue.cleanup", Interval: time.Hour},
+		{Kind: "webhooks.deliver", Interval: 5 * time.Second},
+	}
+
+	count := func(kind string) int {
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM background_jobs WHERE kind = ?", kind).Scan(&n); err != nil && err != sql.ErrNoRows {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	enqueueScheduledTasks()
+	enqueueScheduledTasks()
+	if count("queue.cleanup") != 1 || count("webhooks.deliver") != 1 {
+		t.Fatalf("cada tarefa deveria ser enfileirada uma vez, got %d e %d", count("queue.cleanup"), count("webhooks.deliver"))
+	}
+
+	// Horário vencido: enfileira de novo
+	db.Exec("UPDATE scheduled_tasks SET next_run_at = ? WHERE kind = 'webhooks.deliver'", time.Now().UTC().Add(-time.Second))
+	enqueueScheduledTasks()
+	if count("queue.cleanup") != 1 || count("webhooks.deliver") != 2 {
+		t.Fatalf("got %d e %d, want 1 e 2", count("queue.cleanup"), count("webhooks.deliver"))
+	}
+}
+
+func TestScheduledTasksHaveHandlers(t *testing.T) {
+	for _, task := range scheduledTasks {
+		if backgroundJobHandlers[task.Kind] == nil {
+			t.Errorf("tarefa periódica %s sem handler", task.Kind)
+		}
+		if task.Interval <= 0 {
+			t.Errorf("tarefa periódica %s sem intervalo", task.Kind)
+		}
+	}
+}
+
+func TestBackgroundJobPayloadRedaction(t *testing.T) {
+	setupTestDB(t)
+
+	reset := Email{
+		To:          []string{"ana@x.com"},
+		Subject:     "Redefinição de senha",
+		Body:        "Use o token abaixo: segredo-123",
+		Attachments: []EmailAttachment{{Filename: "proposta.pdf", Data: []byte("segredo-123")}},
+	}
+	if err := enqueueEmail(db, reset); err != nil {
+		t.Fatal(err)
+	}
+	job := mustClaim(t, "w1")
+
+	view, err := json.Marshal(gin.H{"job": job, "payload": backgroundJobPayloadView(job)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(view), "segredo-123") {
+		t.Errorf("resposta expõe o conteúdo do email: %s", view)
+	}
+	for _, want := range []string{"ana@x.com", "Redefinição de senha", "proposta.pdf"} {
+		if !strings.Contains(string(view), want) {
+			t.Errorf("resposta sem %q: %s", want, view)
+		}
+	}
+
+	if err := finishBackgroundJob(job, nil); err != nil {
+		t.Fatal(err)
+	}
+	if stored := loadBackgroundJob(t, job.ID); strings.Contains(stored.Payload, "segredo-123") {
+		t.Errorf("payload do email enviado continua gravado: %s", stored.Payload)
+	}
+
+	// Tarefas sem dados sensíveis mostram o payload como está
+	other := &BackgroundJob{Kind: "jobs.purge", Payload: `{"job_id":7}`}
+	if got, _ := json.Marshal(backgroundJobPayloadView(other)); string(got) != `{"job_id":7}` {
+		t.Errorf("payload = %s", got)
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Motivo usado nas reprovações automáticas pelas perguntas de triagem
+const screeningKnockoutReason = "screening_knockout"
+
+var defaultRejectionReasons = []RejectionReason{
+	{Code: "not_qualified", Label: "Não atende aos requisitos da vaga"},
+	{Code: screeningKnockoutReason, Label: "Eliminado nas perguntas de triagem"},
+	{Code: "position_filled", Label: "Vaga preenchida por outro candidato"},
+	{Code: "salary_mismatch", Label: "Expectativa salarial incompatível"},
+	{Code: "experience_level", Label: "Nível de experiência diferente do esperado"},
+	{Code: "candidate_unresponsive", Label: "Candidato não respondeu"},
+	{Code: "other", Label: "Outro motivo"},
+}
+
+// Atraso padrão, em minutos, para enviar a mensagem de reprovação
+var rejectionMessageDelay = getEnvInt("REJECTION_MESSAGE_DELAY_MINUTES", 0)
+
+var rejectionReasonCode = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func getRejectionReasonsHandler(c *gin.Context) {
+	query := "SELECT id, code, label, active, created_at FROM rejection_reasons"
+	if c.Query("include_inactive") != "true" {
+		query += " WHERE active = 1"
+	}
+	rows, err := db.Query(query + " ORDER BY id")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar motivos"})
+		return
+	}
+	defer rows.Close()
+
+	reasons := []RejectionReason{}
+	for rows.Next() {
+		var r RejectionReason
+		if err := rows.Scan(&r.ID, &r.Code, &r.Label, &r.Active, &r.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler motivos"})
+			return
+		}
+		reasons = append(reasons, r)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reasons": reasons})
+}
+
+func createRejectionReasonHandler(c *gin.Context) {
+	var req RejectionReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !rejectionReasonCode.MatchString(req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "O código deve conter apenas letras minúsculas, números e _"})
+		return
+	}
+
+	reason := RejectionReason{Code: req.Code, Label: strings.TrimSpace(req.Label), Active: true, CreatedAt: time.Now()}
+	if req.Active != nil {
+		reason.Active = *req.Active
+	}
+
+	result, err := db.Exec("INSERT INTO rejection_reasons (code, label, active, created_at) VALUES (?, ?, ?, ?)",
+		reason.Code, reason.Label, reason.Active, reason.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Já existe um motivo com este código"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar motivo"})
+		return
+	}
+	id, _ := result.LastInsertId()
+	reason.ID = int(id)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Motivo criado com sucesso",
+		"reason":  reason,
+	})
+}
+
+// updateRejectionReasonHandler altera o texto ou desativa o motivo. O código
+// não muda e motivos nunca são excluídos, para preservar os relatórios.
+func updateRejectionReasonHandler(c *gin.Context) {
+	reasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req struct {
+		Label  string `json:"label" binding:"required,max=200"`
+		Active *bool  `json:"active"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var code string
+	if err := db.QueryRow("SELECT code FROM rejection_reasons WHERE id = ?", reasonID).Scan(&code); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Motivo não encontrado"})
+		return
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	if code == screeningKnockoutReason && !active {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Este motivo é usado pelas reprovações automáticas e não pode ser desativado"})
+		return
+	}
+
+	_, err = db.Exec("UPDATE rejection_reasons SET label = ?, active = ? WHERE id = ?", strings.TrimSpace(req.Label), active, reasonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar motivo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Motivo atualizado com sucesso"})
+}
+
+var (
+	errInvalidRejectionReason = errors.New("Motivo de reprovação inválido")
+	errAlreadyRejected        = errors.New("Esta candidatura já foi reprovada")
+	errNotRejectable          = errors.New("Esta candidatura não pode ser reprovada")
+)
+
+// loadActiveRejectionReason carrega um motivo do catálogo que ainda pode ser usado
+func loadActiveRejectionReason(id int) (*RejectionReason, error) {
+	var reason RejectionReason
+	err := db.QueryRow("SELECT id, code, label, active, created_at FROM rejection_reasons WHERE id = ?", id).Scan(
+		&reason.ID, &reason.Code, &reason.Label, &reason.Active, &reason.CreatedAt)
+	if err != nil || !reason.Active {
+		return nil, errInvalidRejectionReason
+	}
+	return &reason, nil
+}
+
+// checkRejectable confirma que uma candidatura com este status pode ser reprovada
+func checkRejectable(status string) error {
+	switch status {
+	case "rejected":
+		return errAlreadyRejected
+	case "withdrawn", "hired":
+		return errNotRejectable
+	}
+	return nil
+}
+
+// newRejectionMessage monta a mensagem de reprovação pedida, ou nil quando o
+// candidato não deve ser avisado
+func newRejectionMessage(req *RejectRequest, userID int, ctx *interviewContext) (*CandidateMessage, error) {
+	if req.NotifyCandidate != nil && !*req.NotifyCandidate {
+		return nil, nil
+	}
+
+	subject, body, err := composeCandidateMessage(req.TemplateID, userID, defaultRejectionSubject, defaultRejectionBody, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	delay := rejectionMessageDelay
+	if req.DelayMinutes != nil {
+		delay = *req.DelayMinutes
+	}
+	return &CandidateMessage{
+		ApplicationID: ctx.ApplicationID,
+		Kind:          "rejection",
+		Subject:       subject,
+		Body:          body,
+		SendAt:        time.Now().UTC().Add(time.Duration(delay) * time.Minute),
+		CreatedBy:     userID,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// rejectApplication grava a reprovação, desde que a candidatura ainda esteja no
+// status lido em ctx, e encerra propostas e links de agendamento em aberto
+func rejectApplication(tx *sql.Tx, ctx *interviewContext, reason *RejectionReason, note string, message *CandidateMessage, userID int) error {
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE applications SET status = 'rejected', rejection_reason_id = ?, rejection_note = ?, rejected_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?`, reason.ID, note, now, now, ctx.ApplicationID, ctx.Status)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return errApplicationChanged
+	}
+
+	if err := recordStatusChange(tx, ctx.ApplicationID, ctx.Status, "rejected", reason.Label, userID); err != nil {
+		return err
+	}
+
+	// Propostas em andamento e links de agendamento deixam de valer
+	_, err = tx.Exec(`
+		UPDATE offers SET status = 'withdrawn', updated_at = ?
+		WHERE application_id = ? AND status IN ('draft', 'pending_approval', 'approved', 'sent')`, now, ctx.ApplicationID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE scheduling_links SET revoked_at = ?
+		WHERE application_id = ? AND interview_id IS NULL AND revoked_at IS NULL`, now, ctx.ApplicationID)
+	if err != nil {
+		return err
+	}
+
+	if message != nil {
+		return insertCandidateMessage(tx, message)
+	}
+	return nil
+}
+
+// rejectApplicationHandler reprova a candidatura com um motivo do catálogo e,
+// salvo pedido em contrário, agenda a mensagem de reprovação ao candidato
+func rejectApplicationHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, appID); !ok {
+		return
+	}
+
+	ctx, err := loadInterviewContext(appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	reason, err := loadActiveRejectionReason(req.ReasonID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := checkRejectable(ctx.Status); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	message, err := newRejectionMessage(&req, userID, ctx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		if err := rejectApplication(tx, ctx, reason, strings.TrimSpace(req.Note), message, userID); err != nil {
+			return err
+		}
+		if messageDueNow(message) {
+			return enqueueBackgroundJob(tx, "candidate_messages.send", nil)
+		}
+		return nil
+	})
+	if errors.Is(err, errApplicationChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao reprovar candidatura"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Candidatura reprovada",
+		"reason":            reason,
+		"candidate_message": message,
+	})
+}
+
+// getRejectionReportHandler conta as reprovações por motivo nas vagas em que
+// o usuário faz parte da equipe de contratação (administradores veem todas)
+func getRejectionReportHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	where := []string{"a.status = 'rejected'"}
+	var args []interface{}
+	if !isAdmin(userID) {
+		where = append(where, hiringSideSQL)
+		args = append(args, userID, userID)
+	}
+
+	if value := c.Query("job_id"); value != "" {
+		jobID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "job_id inválido"})
+			return
+		}
+		where = append(where, "j.id = ?")
+		args = append(args, jobID)
+	}
+	for _, param := range []struct{ name, op string }{{"from", ">="}, {"to", "<"}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Parâmetro '%s' deve estar no formato AAAA-MM-DD", param.name)})
+			return
+		}
+		if param.name == "to" {
+			day = day.AddDate(0, 0, 1)
+		}
+		where = append(where, "COALESCE(a.rejected_at, a.updated_at) "+param.op+" ?")
+		args = append(args, day)
+	}
+
+	rows, err := db.Query(`
+		SELECT r.id, COALESCE(r.code, ''), COALESCE(r.label, 'Sem motivo informado'), COUNT(*)
+		FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		LEFT JOIN rejection_reasons r ON a.rejection_reason_id = r.id
+		WHERE `+strings.Join(where, " AND ")+`
+		GROUP BY r.id
+		ORDER BY COUNT(*) DESC, r.id`, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar relatório"})
+		return
+	}
+	defer rows.Close()
+
+	items := []gin.H{}
+	total := 0
+	for rows.Next() {
+		var id sql.NullInt64
+		var code, label string
+		var count int
+		if err := rows.Scan(&id, &code, &label, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar relatório"})
+			return
+		}
+		var reasonID *int64
+		if id.Valid {
+			reasonID = &id.Int64
+		}
+		items = append(items, gin.H{"reason_id": reasonID, "code": code, "label": label, "count": count})
+		total += count
+	}
+
+	for _, item := range items {
+		item["percentage"] = math.Round(float64(item["count"].(int))*1000/float64(total)) / 10
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":   total,
+		"reasons": items,
+	})
+}
+
+// visibleRejection devolve o motivo da reprovação para a equipe de
+// contratação; o candidato não vê o motivo interno
+func visibleRejection(appID, userID int) gin.H {
+	var reasonID sql.NullInt64
+	var code, label, note string
+	var rejectedAt sql.NullTime
+	err := db.QueryRow(`
+		SELECT a.rejection_reason_id, COALESCE(r.code, ''), COALESCE(r.label, ''), a.rejection_note, a.rejected_at
+		FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		LEFT JOIN rejection_reasons r ON a.rejection_reason_id = r.id
+		WHERE a.id = ? AND a.status = 'rejected' AND `+hiringSideSQL, appID, userID, userID).Scan(
+		&reasonID, &code, &label, &note, &rejectedAt)
+	if err != nil || !reasonID.Valid {
+		return nil
+	}
+
+	rejection := gin.H{"reason_id": reasonID.Int64, "code": code, "label": label, "note": note}
+	if rejectedAt.Valid {
+		rejection["rejected_at"] = rejectedAt.Time
+	}
+	return rejection
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// extractDocumentText devolve o texto de um PDF ou DOCX, uma linha por parágrafo
+func extractDocumentText(data []byte, contentType string) (string, error) {
+	switch contentType {
+	case mimeDOCX:
+		return extractDOCXText(data)
+	case mimePDF:
+		return extractPDFText(data)
+	}
+	return "", errUnsupportedType
+}
+
+// extractDOCXText lê word/document.xml e converte parágrafos em linhas
+func extractDOCXText(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var document *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			document = f
+			break
+		}
+	}
+	if document == nil {
+		return "", errUnsupportedType
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(rc, 20<<20))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+var (
+	pdfObjectPattern = regexp.MustCompile(`\d+\s+\d+\s+obj\b`)
+	pdfStreamPattern = regexp.MustCompile(`\bstream\r?\n`)
+	pdfLengthPattern = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+)
+
+// extractPDFText percorre os content streams do PDF e interpreta os operadores
+// de texto (Tj, TJ, ', ", T*, Td). Atende currículos gerados por editores de
+// texto comuns; PDFs escaneados ou com fontes sem mapeamento não têm texto legível.
+func extractPDFText(data []byte) (string, error) {
+	var sb strings.Builder
+
+	// Cada objeto "N G obj ... endobj" é tratado isoladamente: o dicionário do
+	// stream é só o trecho entre o cabeçalho do objeto e a palavra stream
+	pos := 0
+	for pos < len(data) {
+		loc := pdfObjectPattern.FindIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		objStart := pos + loc[1]
+
+		objEnd := bytes.Index(data[objStart:], []byte("endobj"))
+		if objEnd < 0 {
+			objEnd = len(data)
+		} else {
+			objEnd += objStart
+		}
+
+		// Os dados binários do stream podem conter "endobj", mas a palavra
+		// stream sempre vem antes deles
+		sm := pdfStreamPattern.FindIndex(data[objStart:objEnd])
+		if sm == nil {
+			pos = objEnd
+			continue
+		}
+		dict := string(data[objStart : objStart+sm[0]])
+		start := objStart + sm[1]
+
+		end := -1
+		if m := pdfLengthPattern.FindStringSubmatch(dict); m != nil && m[2] == "" {
+			if n, err := strconv.Atoi(m[1]); err == nil && start+n <= len(data) {
+				end = start + n
+			}
+		}
+		if end < 0 {
+			idx := bytes.Index(data[start:], []byte("endstream"))
+			if idx < 0 {
+				break
+			}
+			end = start + idx
+		}
+		pos = end
+
+		// Fontes e imagens não contêm operadores de texto
+		if strings.Contains(dict, "/Subtype") || strings.Contains(dict, "/Length1") {
+			continue
+		}
+
+		raw := data[start:end]
+		if strings.Contains(dict, "/FlateDecode") {
+			zr, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			decoded, err := io.ReadAll(io.LimitReader(zr, 20<<20))
+			zr.Close()
+			if err != nil && len(decoded) == 0 {
+				continue
+			}
+			raw = decoded
+		} else if strings.Contains(dict, "/Filter") {
+			// Outros filtros (DCT, LZW...) não são suportados
+			continue
+		}
+
+		if bytes.Contains(raw, []byte("BT")) {
+			sb.WriteString(parsePDFContent(raw))
+		}
+	}
+
+	text := sb.String()
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("Não foi possível extrair texto do PDF")
+	}
+	return text, nil
+}
+
+// pdfOperand guarda strings e números empilhados antes de cada operador
+type pdfOperand struct {
+	text     string
+	number   float64
+	isString bool
+	isNumber bool
+	isArray  bool // marcador de início de array
+}
+
+// parsePDFContent interpreta um content stream e devolve o texto encontrado
+func parsePDFContent(content []byte) string {
+	var sb strings.Builder
+	var operands []pdfOperand
+	lastNewline := true
+	i := 0
+
+	write := func(text string) {
+		if text == "" {
+			return
+		}
+		sb.WriteString(text)
+		lastNewline = false
+	}
+	newline := func() {
+		if !lastNewline {
+			sb.WriteByte('\n')
+			lastNewline = true
+		}
+	}
+	lastText := func() string {
+		if len(operands) > 0 && operands[len(operands)-1].isString {
+			return operands[len(operands)-1].text
+		}
+		return ""
+	}
+
+	for i < len(content) {
+		ch := content[i]
+		switch {
+		case ch == '(':
+			text, next := readPDFLiteral(content, i)
+			operands = append(operands, pdfOperand{text: text, isString: true})
+			i = next
+		case ch == '<' && i+1 < len(content) && content[i+1] != '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return sb.String()
+			}
+			operands = append(operands, pdfOperand{text: decodePDFHex(string(content[i+1 : i+end])), isString: true})
+			i += end + 1
+		case ch == '[':
+			operands = append(operands, pdfOperand{isArray: true})
+			i++
+		case ch == ']':
+			// Junta as strings do array usado por TJ; ajustes grandes viram espaço
+			j := len(operands) - 1
+			for j >= 0 && !operands[j].isArray {
+				j--
+			}
+			var joined strings.Builder
+			for _, op := range operands[j+1:] {
+				if op.isString {
+					joined.WriteString(op.text)
+				} else if op.isNumber && op.number < -200 {
+					joined.WriteByte(' ')
+				}
+			}
+			if j < 0 {
+				j = 0
+			}
+			operands = append(operands[:j], pdfOperand{text: joined.String(), isString: true})
+			i++
+		case ch == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case ch == '/':
+			// Nomes (/F1, /Span...) são operandos sem texto
+			i++
+			for i < len(content) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+			operands = append(operands, pdfOperand{})
+		case ch == '<' || ch == '>' || ch == '{' || ch == '}' || ch == ')':
+			i++
+		case isPDFSpace(ch):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+
+			word := string(content[start:i])
+			if n, err := strconv.ParseFloat(word, 64); err == nil {
+				operands = append(operands, pdfOperand{number: n, isNumber: true})
+				continue
+			}
+
+			switch word {
+			case "Tj", "TJ":
+				write(lastText())
+			case "'", "\"":
+				newline()
+				write(lastText())
+			case "T*", "ET", "Tm":
+				newline()
+			case "Td", "TD":
+				// Deslocamento vertical indica nova linha, horizontal um espaço
+				if n := len(operands); n >= 2 && operands[n-1].isNumber {
+					if operands[n-1].number != 0 {
+						newline()
+					} else if operands[n-2].number > 0 && !lastNewline {
+						write(" ")
+					}
+				}
+			}
+			operands = operands[:0]
+		}
+	}
+
+	return sb.String()
+}
+
+func isPDFSpace(ch byte) bool {
+	return ch == ' ' || ch == '\n' || ch == '\r' || ch == '\t' || ch == '\f' || ch == 0
+}
+
+func isPDFDelimiter(ch byte) bool {
+	return isPDFSpace(ch) || strings.IndexByte("()<>[]{}/%", ch) >= 0
+}
+
+// readPDFLiteral lê uma string literal (...) tratando escapes e parênteses aninhados
+func readPDFLiteral(content []byte, i int) (string, int) {
+	var sb strings.Builder
+	depth := 0
+	i++ // pula o "("
+	for i < len(content) {
+		ch := content[i]
+		switch ch {
+		case '\\':
+			i++
+			if i >= len(content) {
+				return sb.String(), i
+			}
+			esc := content[i]
+			switch esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Continuação de linha
+			default:
+				if esc >= '0' && esc <= '7' {
+					n := 0
+					k := 0
+					for k < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7' {
+						n = n*8 + int(content[i]-'0')
+						i++
+						k++
+					}
+					sb.WriteRune(rune(byte(n)))
+					continue
+				}
+				sb.WriteByte(esc)
+			}
+			i++
+		case '(':
+			depth++
+			sb.WriteByte(ch)
+			i++
+		case ')':
+			if depth == 0 {
+				return sb.String(), i + 1
+			}
+			depth--
+			sb.WriteByte(ch)
+			i++
+		default:
+			// Bytes fora do ASCII são tratados como Latin-1
+			sb.WriteRune(rune(ch))
+			i++
+		}
+	}
+	return sb.String(), i
+}
+
+// decodePDFHex decodifica strings hexadecimais; textos UTF-16 (com BOM) são
+// convertidos, os demais tratados como Latin-1
+func decodePDFHex(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return r
+		}
+		return -1
+	}, s)
+	if len(s)%2 == 1 {
+		s += "0"
+	}
+
+	b := make([]byte, len(s)/2)
+	for i := 0; i < len(b); i++ {
+		n, _ := strconv.ParseUint(s[i*2:i*2+2], 16, 8)
+		b[i] = byte(n)
+	}
+
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		var sb strings.Builder
+		for i := 2; i+1 < len(b); i += 2 {
+			sb.WriteRune(rune(b[i])<<8 | rune(b[i+1]))
+		}
+		return sb.String()
+	}
+
+	var sb strings.Builder
+	for _, c := range b {
+		sb.WriteRune(rune(c))
+	}
+	return sb.String()
+}
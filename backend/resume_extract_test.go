@@ -0,0 +1,183 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// buildPDF monta um PDF simples com os objetos informados, na ordem
+func buildPDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func pdfStream(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(s string) string {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write([]byte(s))
+	w.Close()
+	return b.String()
+}
+
+func buildDOCX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestExtractPDFText(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/resume_type1.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "BT /F1 12 Tf 72 720 Td (Ana Lima) Tj T* (Analista de dados) Tj ET"
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "fixture com fonte Type1 antes do conteúdo",
+			data: fixture,
+			want: []string{"Maria Souza", "Desenvolvedora Go"},
+		},
+		{
+			name: "fonte embutida antes do conteúdo",
+			data: buildPDF(
+				"<< /Type /Catalog >>",
+				pdfStream("/Length1 4", "FONT"),
+				pdfStream("", content),
+			),
+			want: []string{"Ana Lima", "Analista de dados"},
+		},
+		{
+			name: "stream comprimido",
+			data: buildPDF(
+				"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+				pdfStream("/Filter /FlateDecode", flate(content)),
+			),
+			want: []string{"Ana Lima", "Analista de dados"},
+		},
+		{
+			name: "imagem com endobj nos dados binários",
+			data: buildPDF(
+				pdfStream("/Subtype /Image", "xx endobj 9 0 obj BT (falso) Tj ET"),
+				pdfStream("", content),
+			),
+			want: []string{"Ana Lima"},
+		},
+		{
+			name: "texto em hexadecimal UTF-16",
+			data: buildPDF(pdfStream("", "BT <FEFF00C90064006E0061> Tj ET")),
+			want: []string{"Édna"},
+		},
+		{
+			name:    "sem texto",
+			data:    buildPDF(pdfStream("/Subtype /Image", "binario")),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractDocumentText(tt.data, mimePDF)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, obteve %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("texto %q não contém %q", got, w)
+				}
+			}
+			if strings.Contains(got, "falso") {
+				t.Errorf("texto %q inclui conteúdo de stream ignorado", got)
+			}
+		})
+	}
+}
+
+func TestExtractDOCXText(t *testing.T) {
+	document := `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>João Pereira</w:t></w:r></w:p>
+<w:p><w:r><w:t>Go</w:t></w:r><w:r><w:tab/><w:t>SQL</w:t></w:r></w:p>
+<w:p><w:r><w:t>Linha 1</w:t><w:br/><w:t>Linha 2</w:t></w:r></w:p>
+</w:body></w:document>`
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "parágrafos, tabs e quebras",
+			files: map[string]string{"word/document.xml": document},
+			want:  "João Pereira\nGo\tSQL\nLinha 1\nLinha 2\n",
+		},
+		{
+			name:    "sem document.xml",
+			files:   map[string]string{"word/styles.xml": "<styles/>"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractDocumentText(buildDOCX(t, tt.files), mimeDOCX)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("esperava erro, obteve %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := extractDocumentText([]byte("não é zip"), mimeDOCX); err == nil {
+		t.Error("esperava erro para DOCX inválido")
+	}
+	if _, err := extractDocumentText(nil, "text/plain"); err != errUnsupportedType {
+		t.Errorf("got %v, want errUnsupportedType", err)
+	}
+}
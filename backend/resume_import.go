@@ -0,0 +1,481 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Títulos de seção reconhecidos nos currículos
+const (
+	sectionHeader     = ""
+	sectionSummary    = "summary"
+	sectionExperience = "experience"
+	sectionEducation  = "education"
+	sectionSkills     = "skills"
+	sectionOther      = "other"
+)
+
+var sectionTitles = map[string]string{
+	"resumo":                     sectionSummary,
+	"resumo profissional":        sectionSummary,
+	"sobre":                      sectionSummary,
+	"sobre mim":                  sectionSummary,
+	"perfil":                     sectionSummary,
+	"perfil profissional":        sectionSummary,
+	"objetivo":                   sectionSummary,
+	"summary":                    sectionSummary,
+	"professional summary":       sectionSummary,
+	"about":                      sectionSummary,
+	"about me":                   sectionSummary,
+	"profile":                    sectionSummary,
+	"experiência":                sectionExperience,
+	"experiências":               sectionExperience,
+	"experiência profissional":   sectionExperience,
+	"experiências profissionais": sectionExperience,
+	"histórico profissional":     sectionExperience,
+	"experience":                 sectionExperience,
+	"work experience":            sectionExperience,
+	"professional experience":    sectionExperience,
+	"employment history":         sectionExperience,
+	"formação":                   sectionEducation,
+	"formação acadêmica":         sectionEducation,
+	"escolaridade":               sectionEducation,
+	"education":                  sectionEducation,
+	"academic background":        sectionEducation,
+	"habilidades":                sectionSkills,
+	"competências":               sectionSkills,
+	"conhecimentos":              sectionSkills,
+	"tecnologias":                sectionSkills,
+	"skills":                     sectionSkills,
+	"technical skills":           sectionSkills,
+	"idiomas":                    sectionOther,
+	"languages":                  sectionOther,
+	"certificações":              sectionOther,
+	"certifications":             sectionOther,
+	"cursos":                     sectionOther,
+	"projetos":                   sectionOther,
+	"projects":                   sectionOther,
+	"contato":                    sectionOther,
+	"contact":                    sectionOther,
+	"referências":                sectionOther,
+	"references":                 sectionOther,
+}
+
+// Habilidades procuradas no texto inteiro, além da seção de habilidades
+var knownSkills = []string{
+	"Go", "Golang", "Python", "Java", "JavaScript", "TypeScript", "Node.js", "React", "Angular",
+	"Vue.js", "PHP", "Ruby", "Rails", "C#", ".NET", "C++", "Kotlin", "Swift", "Rust", "Scala",
+	"SQL", "PostgreSQL", "MySQL", "MongoDB", "Redis", "SQLite", "Docker", "Kubernetes", "AWS",
+	"Azure", "GCP", "Terraform", "Linux", "Git", "GraphQL", "REST", "Django", "Flask", "Spring",
+	"HTML", "CSS", "Figma", "Scrum", "Kafka", "RabbitMQ", "Elasticsearch", "Excel", "Power BI",
+}
+
+// knownSkillPatterns casa cada habilidade conhecida como palavra inteira
+// ("Go" não casa em "Google" nem "Java" em "JavaScript"), compiladas uma única vez
+var knownSkillPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(knownSkills))
+	for i, skill := range knownSkills {
+		patterns[i] = regexp.MustCompile(`(?i)(^|[^\w.+#])` + regexp.QuoteMeta(skill) + `($|[^\w+#])`)
+	}
+	return patterns
+}()
+
+var (
+	emailPattern     = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	resumePhoneRegex = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?\(?\d{2}\)?[\s.-]?\d{4,5}[\s.-]?\d{4}`)
+	urlPattern       = regexp.MustCompile(`(?i)\b(?:https?://[^\s|,;]+|(?:www\.)?(?:linkedin\.com|github\.com|gitlab\.com|behance\.net|dribbble\.com)/[^\s|,;]+)`)
+	dateToken        = `(?:\d{1,2}/\d{4}|\d{4}-\d{2}|(?:jan|fev|feb|mar|abr|apr|mai|may|jun|jul|ago|aug|set|sep|out|oct|nov|dez|dec)[a-zç]*\.?(?:\s+de)?\s+\d{4}|\d{4})`
+	dateRangePattern = regexp.MustCompile(`(?i)\(?(` + dateToken + `)\s*(?:-|–|—|a|até|to)\s*(` + dateToken + `|atual|presente|present|current|hoje|now|o momento)\)?`)
+	skillSplitter    = regexp.MustCompile(`\s*[,;•|·/]\s*|\s+-\s+`)
+	// Separadores de "Cargo - Empresa", do mais para o menos confiável
+	entrySplitters = []*regexp.Regexp{
+		regexp.MustCompile(`\s+(?:-|–|—|\|)\s+`),
+		regexp.MustCompile(`(?i)\s+(?:@|at|em|na|no)\s+`),
+		regexp.MustCompile(`,\s+`),
+	}
+)
+
+var monthNames = map[string]int{
+	"jan": 1, "fev": 2, "feb": 2, "mar": 3, "abr": 4, "apr": 4, "mai": 5, "may": 5,
+	"jun": 6, "jul": 7, "ago": 8, "aug": 8, "set": 9, "sep": 9, "out": 10, "oct": 10,
+	"nov": 11, "dez": 12, "dec": 12,
+}
+
+// resumeProposal são os campos sugeridos ao candidato; o email encontrado é
+// apenas informativo, pois não altera o email da conta
+type resumeProposal struct {
+	ResumeImportRequest
+	Email string `json:"email"`
+}
+
+// normalizeSectionTitle reduz a linha a uma forma comparável com sectionTitles
+func normalizeSectionTitle(line string) string {
+	line = strings.ToLower(strings.TrimSpace(line))
+	line = strings.Trim(line, ":•-–— ")
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// parseResumeDate converte datas como 03/2020, 2020-03, mar 2020 e 2020 em AAAA-MM
+func parseResumeDate(value string) (date string, current bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "atual", "presente", "present", "current", "hoje", "now", "o momento":
+		return "", true
+	}
+
+	if t, err := time.Parse("1/2006", value); err == nil {
+		return t.Format(monthLayout), false
+	}
+	if t, err := time.Parse(monthLayout, value); err == nil {
+		return t.Format(monthLayout), false
+	}
+	if t, err := time.Parse("2006", value); err == nil {
+		return t.Format(monthLayout), false
+	}
+
+	fields := strings.Fields(strings.ReplaceAll(value, ".", ""))
+	if len(fields) >= 2 {
+		if month, ok := monthNames[fields[0][:min(3, len(fields[0]))]]; ok {
+			if t, err := time.Parse("2006", fields[len(fields)-1]); err == nil {
+				return fmt.Sprintf("%04d-%02d", t.Year(), month), false
+			}
+		}
+	}
+	return "", false
+}
+
+// splitEntry separa "Cargo - Empresa" ou "Curso - Instituição" em duas partes
+func splitEntry(line string) (string, string) {
+	for _, splitter := range entrySplitters {
+		if parts := splitter.Split(line, 2); len(parts) == 2 {
+			return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		}
+	}
+	return strings.TrimSpace(line), ""
+}
+
+type resumeEntry struct {
+	header      string
+	start, end  string
+	current     bool
+	description []string
+}
+
+// parseEntries agrupa as linhas de uma seção em entradas, usando as linhas com
+// período (ex: "01/2020 - Atual") como âncoras. O título da entrada é a linha
+// anterior ao período ou o texto que acompanha o período na mesma linha.
+func parseEntries(lines []string) []resumeEntry {
+	var entries []resumeEntry
+	var pending []string
+
+	for _, line := range lines {
+		loc := dateRangePattern.FindStringSubmatchIndex(line)
+		if loc == nil {
+			pending = append(pending, line)
+			continue
+		}
+
+		start, _ := parseResumeDate(line[loc[2]:loc[3]])
+		end, current := parseResumeDate(line[loc[4]:loc[5]])
+		rest := strings.Trim(strings.TrimSpace(line[:loc[0]]+" "+line[loc[1]:]), " -–—|,")
+
+		entry := resumeEntry{start: start, end: end, current: current}
+		if rest != "" {
+			entry.header = rest
+		} else if len(pending) > 0 {
+			entry.header = pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+		}
+
+		// As linhas acumuladas antes deste título descrevem a entrada anterior
+		if len(entries) > 0 {
+			entries[len(entries)-1].description = append(entries[len(entries)-1].description, pending...)
+		}
+		pending = nil
+
+		entries = append(entries, entry)
+	}
+
+	if len(entries) > 0 {
+		entries[len(entries)-1].description = append(entries[len(entries)-1].description, pending...)
+	}
+	return entries
+}
+
+// linkLabel escolhe um rótulo para o link a partir do domínio
+func linkLabel(url string) string {
+	lower := strings.ToLower(url)
+	switch {
+	case strings.Contains(lower, "linkedin.com"):
+		return "LinkedIn"
+	case strings.Contains(lower, "github.com"):
+		return "GitHub"
+	case strings.Contains(lower, "gitlab.com"):
+		return "GitLab"
+	case strings.Contains(lower, "behance.net"):
+		return "Behance"
+	case strings.Contains(lower, "dribbble.com"):
+		return "Dribbble"
+	}
+	return "Site"
+}
+
+// parseResumeText aplica heurísticas ao texto extraído e propõe campos do perfil
+func parseResumeText(text string) resumeProposal {
+	var proposal resumeProposal
+	proposal.Links = []ProfileLinkRequest{}
+	proposal.Skills = []string{}
+	proposal.Experiences = []ExperienceRequest{}
+	proposal.Educations = []EducationRequest{}
+
+	proposal.Email = emailPattern.FindString(text)
+
+	// Telefones são procurados sem os emails e links para evitar falsos positivos
+	withoutContacts := urlPattern.ReplaceAllString(emailPattern.ReplaceAllString(text, " "), " ")
+	if phone := resumePhoneRegex.FindString(withoutContacts); phone != "" {
+		proposal.Phone = strings.TrimSpace(phone)
+	}
+
+	seenLinks := map[string]bool{}
+	for _, url := range urlPattern.FindAllString(text, -1) {
+		url = strings.TrimRight(url, ".)")
+		if !strings.HasPrefix(strings.ToLower(url), "http") {
+			url = "https://" + url
+		}
+		if seenLinks[url] || len(proposal.Links) >= 20 {
+			continue
+		}
+		seenLinks[url] = true
+		proposal.Links = append(proposal.Links, ProfileLinkRequest{Label: linkLabel(url), URL: url})
+	}
+
+	// Separar as linhas por seção
+	sections := map[string][]string{}
+	current := sectionHeader
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.Join(strings.Fields(raw), " ")
+		if line == "" {
+			continue
+		}
+		if section, ok := sectionTitles[normalizeSectionTitle(line)]; ok {
+			current = section
+			continue
+		}
+		sections[current] = append(sections[current], line)
+	}
+
+	// Nome e título vêm do cabeçalho, antes da primeira seção
+	for _, line := range sections[sectionHeader] {
+		if emailPattern.MatchString(line) || urlPattern.MatchString(line) || resumePhoneRegex.MatchString(line) {
+			continue
+		}
+		if proposal.Name == "" {
+			if words := len(strings.Fields(line)); words >= 2 && words <= 5 && !strings.ContainsAny(line, "0123456789") {
+				proposal.Name = line
+			}
+			continue
+		}
+		if proposal.Headline == "" && len(line) <= 160 {
+			proposal.Headline = line
+			break
+		}
+	}
+
+	if summary := strings.Join(sections[sectionSummary], " "); summary != "" {
+		proposal.Summary = truncate(summary, 5000)
+	}
+
+	for _, entry := range parseEntries(sections[sectionExperience]) {
+		if entry.start == "" || entry.header == "" {
+			continue
+		}
+		title, company := splitEntry(entry.header)
+		if company == "" {
+			company = title
+		}
+		proposal.Experiences = append(proposal.Experiences, ExperienceRequest{
+			Title:       truncate(title, 120),
+			Company:     truncate(company, 120),
+			StartDate:   entry.start,
+			EndDate:     entry.end,
+			Current:     entry.current,
+			Description: truncate(strings.Join(entry.description, "\n"), 5000),
+		})
+	}
+
+	for _, entry := range parseEntries(sections[sectionEducation]) {
+		if entry.start == "" || entry.header == "" {
+			continue
+		}
+		degree, institution := splitEntry(entry.header)
+		if institution == "" {
+			institution = degree
+		}
+		proposal.Educations = append(proposal.Educations, EducationRequest{
+			Institution: truncate(institution, 160),
+			Degree:      truncate(degree, 120),
+			StartDate:   entry.start,
+			EndDate:     entry.end,
+			Description: truncate(strings.Join(entry.description, "\n"), 5000),
+		})
+	}
+
+	// Habilidades da seção própria e as conhecidas encontradas no texto
+	seenSkills := map[string]bool{}
+	addSkill := func(name string) {
+		display, slug := normalizeSkill(strings.Trim(name, ".:"))
+		if slug == "" || len(display) > 60 || seenSkills[slug] || len(proposal.Skills) >= 100 {
+			return
+		}
+		seenSkills[slug] = true
+		proposal.Skills = append(proposal.Skills, display)
+	}
+	for _, line := range sections[sectionSkills] {
+		for _, skill := range skillSplitter.Split(line, -1) {
+			if len(strings.Fields(skill)) <= 4 {
+				addSkill(skill)
+			}
+		}
+	}
+	for i, pattern := range knownSkillPatterns {
+		if pattern.MatchString(text) {
+			addSkill(knownSkills[i])
+		}
+	}
+
+	return proposal
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
+
+// importResumeHandler extrai o texto do currículo enviado e devolve os campos
+// sugeridos, sem alterar o perfil
+func importResumeHandler(c *gin.Context) {
+	doc, err := readDocumentUpload(c)
+	if err != nil {
+		c.JSON(uploadErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	text, err := extractDocumentText(doc.Data, doc.ContentType)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Não foi possível ler o texto do currículo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Confira os dados sugeridos antes de confirmar",
+		"proposal": parseResumeText(text),
+		"text":     truncate(text, 20000),
+	})
+}
+
+// confirmResumeImportHandler grava no perfil os campos revisados pelo candidato.
+// Campos vazios não sobrescrevem o perfil; itens de listas são adicionados.
+func confirmResumeImportHandler(c *gin.Context) {
+	var req ResumeImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Phone != "" && !phonePattern.MatchString(req.Phone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Telefone inválido"})
+		return
+	}
+	for _, exp := range req.Experiences {
+		if err := validatePeriod(exp.StartDate, exp.EndDate, exp.Current); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	for _, edu := range req.Educations {
+		if err := validatePeriod(edu.StartDate, edu.EndDate, false); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID := c.GetInt("user_id")
+	now := time.Now()
+
+	// Tudo ou nada: uma falha no meio não deixa o perfil parcialmente preenchido
+	err := withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE users SET
+				name = COALESCE(NULLIF(?, ''), name),
+				headline = COALESCE(NULLIF(?, ''), headline),
+				summary = COALESCE(NULLIF(?, ''), summary),
+				location = COALESCE(NULLIF(?, ''), location),
+				phone = COALESCE(NULLIF(?, ''), phone),
+				updated_at = ?
+			WHERE id = ?`,
+			req.Name, req.Headline, req.Summary, req.Location, req.Phone, now, userID)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range req.Skills {
+			display, slug, err := canonicalSkill(tx, name)
+			if err != nil {
+				return err
+			}
+			if slug == "" {
+				continue
+			}
+			_, err = tx.Exec("INSERT OR IGNORE INTO user_skills (user_id, name, slug) VALUES (?, ?, ?)", userID, display, slug)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, link := range req.Links {
+			_, err := tx.Exec(`
+				INSERT INTO profile_links (user_id, label, url)
+				SELECT ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM profile_links WHERE user_id = ? AND url = ?)`,
+				userID, link.Label, link.URL, userID, link.URL)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, exp := range req.Experiences {
+			_, err := tx.Exec(`
+				INSERT INTO experiences (user_id, title, company, location, start_date, end_date, current, description, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				userID, exp.Title, exp.Company, exp.Location, exp.StartDate, exp.EndDate, exp.Current, exp.Description, now, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, edu := range req.Educations {
+			_, err := tx.Exec(`
+				INSERT INTO educations (user_id, institution, degree, field_of_study, start_date, end_date, description, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				userID, edu.Institution, edu.Degree, edu.FieldOfStudy, edu.StartDate, edu.EndDate, edu.Description, now, now)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao importar dados do currículo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Perfil preenchido com os dados do currículo"})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseResumeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want resumeProposal
+	}{
+		{
+			name: "currículo em português",
+			text: `Ana Lima
+Desenvolvedora Backend Sênior
+ana.lima@email.com | (11) 98765-4321 | linkedin.com/in/analima | https://github.com/analima
+
+Resumo
+Desenvolvedora com 8 anos de experiência
+em sistemas distribuídos.
+
+Experiência Profissional
+Engenheira de Software Sênior - Nubank
+03/2021 - Atual
+Serviços de pagamento em Go e Kafka.
+Migração de monólito para microsserviços.
+Desenvolvedora Backend @ Globo
+jan 2017 a fev 2021
+APIs REST em Python e Django.
+
+Formação Acadêmica
+Bacharelado em Ciência da Computação - USP
+2012 - 2016
+
+Habilidades
+Go, Python, PostgreSQL; Docker • Kubernetes
+Comunicação com clientes e times de produto`,
+			want: resumeProposal{
+				ResumeImportRequest: ResumeImportRequest{
+					Name:     "Ana Lima",
+					Headline: "Desenvolvedora Backend Sênior",
+					Summary:  "Desenvolvedora com 8 anos de experiência em sistemas distribuídos.",
+					Phone:    "(11) 98765-4321",
+					Links: []ProfileLinkRequest{
+						{Label: "LinkedIn", URL: "https://linkedin.com/in/analima"},
+						{Label: "GitHub", URL: "https://github.com/analima"},
+					},
+					// Primeiro as da seção de habilidades, depois as conhecidas no texto
+					Skills: []string{"Go", "Python", "PostgreSQL", "Docker", "Kubernetes", "REST", "Django", "Kafka"},
+					Experiences: []ExperienceRequest{
+						{
+							Title: "Engenheira de Software Sênior", Company: "Nubank", StartDate: "2021-03", Current: true,
+							Description: "Serviços de pagamento em Go e Kafka.\nMigração de monólito para microsserviços.",
+						},
+						{
+							Title: "Desenvolvedora Backend", Company: "Globo", StartDate: "2017-01", EndDate: "2021-02",
+							Description: "APIs REST em Python e Django.",
+						},
+					},
+					Educations: []EducationRequest{
+						{Institution: "USP", Degree: "Bacharelado em Ciência da Computação", StartDate: "2012-01", EndDate: "2016-01"},
+					},
+				},
+				Email: "ana.lima@email.com",
+			},
+		},
+		{
+			name: "currículo em inglês com período na linha do cargo",
+			text: `JOHN SMITH
+Product Designer
+Phone: +55 21 3456-7890
+Email: john.smith@example.org
+Portfolio: www.behance.net/johnsmith.
+
+PROFESSIONAL EXPERIENCE
+Senior Product Designer, Google (Aug 2019 – Present)
+Led the redesign of the checkout flow in Figma.
+Product Designer at Spotify, 2015-06 to 2019-07
+
+EDUCATION
+Design Gráfico | PUC-Rio | 2010 - 2014
+
+LANGUAGES
+English, Portuguese`,
+			want: resumeProposal{
+				ResumeImportRequest: ResumeImportRequest{
+					Name:     "JOHN SMITH",
+					Headline: "Product Designer",
+					Phone:    "+55 21 3456-7890",
+					Links:    []ProfileLinkRequest{{Label: "Behance", URL: "https://www.behance.net/johnsmith"}},
+					// "Google" não conta como Go
+					Skills: []string{"Figma"},
+					Experiences: []ExperienceRequest{
+						{
+							Title: "Senior Product Designer", Company: "Google", StartDate: "2019-08", Current: true,
+							Description: "Led the redesign of the checkout flow in Figma.",
+						},
+						{Title: "Product Designer", Company: "Spotify", StartDate: "2015-06", EndDate: "2019-07"},
+					},
+					Educations: []EducationRequest{
+						{Institution: "PUC-Rio", Degree: "Design Gráfico", StartDate: "2010-01", EndDate: "2014-01"},
+					},
+				},
+				Email: "john.smith@example.org",
+			},
+		},
+		{
+			name: "experiência sem período é descartada",
+			text: `Carlos Souza
+carlos@x.com
+Objetivo: vaga de estágio
+Projetos
+Site pessoal em JavaScript e Node.js, googleando tudo.
+Experiência
+Estagiário
+Sem data definida`,
+			want: resumeProposal{
+				ResumeImportRequest: ResumeImportRequest{
+					Name:        "Carlos Souza",
+					Headline:    "Objetivo: vaga de estágio",
+					Links:       []ProfileLinkRequest{},
+					Skills:      []string{"JavaScript", "Node.js"},
+					Experiences: []ExperienceRequest{},
+					Educations:  []EducationRequest{},
+				},
+				Email: "carlos@x.com",
+			},
+		},
+		{
+			name: "texto vazio",
+			text: "",
+			want: resumeProposal{
+				ResumeImportRequest: ResumeImportRequest{
+					Links:       []ProfileLinkRequest{},
+					Skills:      []string{},
+					Experiences: []ExperienceRequest{},
+					Educations:  []EducationRequest{},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseResumeText(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseResumeDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		current bool
+	}{
+		{"03/2020", "2020-03", false},
+		{"3/2020", "2020-03", false},
+		{"2020-03", "2020-03", false},
+		{"2020", "2020-01", false},
+		{"mar 2020", "2020-03", false},
+		{"Setembro de 2018", "2018-09", false},
+		{"Aug. 2019", "2019-08", false},
+		{"Atual", "", true},
+		{"o momento", "", true},
+		{"em breve", "", false},
+	}
+	for _, tt := range tests {
+		got, current := parseResumeDate(tt.in)
+		if got != tt.want || current != tt.current {
+			t.Errorf("parseResumeDate(%q) = %q, %v; want %q, %v", tt.in, got, current, tt.want, tt.current)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mimePDF  = "application/pdf"
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// Tamanho máximo aceito para currículos, em bytes
+var maxResumeSize = int64(getEnvInt("MAX_RESUME_SIZE", 5<<20))
+
+var (
+	errUploadTooLarge  = errors.New("Arquivo excede o tamanho máximo permitido")
+	errUnsupportedType = errors.New("Formato de arquivo não suportado, envie um PDF ou DOCX")
+)
+
+type uploadedDocument struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	Checksum    string
+}
+
+// detectDocumentType identifica o tipo pelo conteúdo do arquivo, sem confiar
+// na extensão nem no Content-Type enviado pelo cliente
+func detectDocumentType(data []byte) (string, error) {
+	switch http.DetectContentType(data) {
+	case mimePDF:
+		return mimePDF, nil
+	case "application/zip":
+		// DOCX é um pacote zip que contém word/document.xml
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return "", errUnsupportedType
+		}
+		for _, f := range zr.File {
+			if f.Name == "word/document.xml" {
+				return mimeDOCX, nil
+			}
+		}
+	}
+	return "", errUnsupportedType
+}
+
+// readDocumentUpload lê o campo "file" de um formulário multipart, aplicando o
+// limite de tamanho e a verificação de tipo
+func readDocumentUpload(c *gin.Context) (*uploadedDocument, error) {
+	// Margem para os cabeçalhos do multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxResumeSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, errUploadTooLarge
+		}
+		return nil, errors.New("Envie o arquivo no campo 'file'")
+	}
+
+	if fileHeader.Size > maxResumeSize {
+		return nil, errUploadTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxResumeSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxResumeSize {
+		return nil, errUploadTooLarge
+	}
+
+	contentType, err := detectDocumentType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return &uploadedDocument{
+		Filename:    sanitizeFilename(fileHeader.Filename, contentType),
+		ContentType: contentType,
+		Data:        data,
+		Checksum:    hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// sanitizeFilename mantém apenas o nome do arquivo com a extensão correta
+func sanitizeFilename(name, contentType string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || r == '"' || r == '/' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." {
+		name = "curriculo"
+	}
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return name + extensionFor(contentType)
+}
+
+func extensionFor(contentType string) string {
+	if contentType == mimeDOCX {
+		return ".docx"
+	}
+	if ext, ok := attachmentExtensions[contentType]; ok {
+		return ext
+	}
+	return ".pdf"
+}
+
+// uploadErrorStatus converte erros de upload no status HTTP adequado
+func uploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errUploadTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errUnsupportedType), errors.Is(err, errUnsupportedAttachment):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// serveStoredFile envia um arquivo do armazenamento como download
+func serveStoredFile(c *gin.Context, key, filename, contentType string, size int64) {
+	r, err := fileStorage.Open(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Arquivo não encontrado"})
+		return
+	}
+	defer r.Close()
+
+	c.DataFromReader(http.StatusOK, size, contentType, r, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, filename),
+	})
+}
+
+func getResumesHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	rows, err := db.Query(`
+		SELECT id, user_id, filename, content_type, size, checksum, created_at
+		FROM resumes
+		WHERE user_id = ?
+		ORDER BY created_at DESC`, userID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar currículos"})
+		return
+	}
+	defer rows.Close()
+
+	resumes := []Resume{}
+	for rows.Next() {
+		var resume Resume
+		err := rows.Scan(&resume.ID, &resume.UserID, &resume.Filename, &resume.ContentType,
+			&resume.Size, &resume.Checksum, &resume.CreatedAt)
+		if err != nil {
+			continue
+		}
+		resumes = append(resumes, resume)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"resumes": resumes})
+}
+
+func uploadResumeHandler(c *gin.Context) {
+	doc, err := readDocumentUpload(c)
+	if err != nil {
+		c.JSON(uploadErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	token, err := randomToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar currículo"})
+		return
+	}
+	key := fmt.Sprintf("resumes/%d/%s%s", userID, token, extensionFor(doc.ContentType))
+
+	if err := fileStorage.Save(key, bytes.NewReader(doc.Data)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar currículo"})
+		return
+	}
+
+	now := time.Now()
+	result, err := db.Exec(`
+		INSERT INTO resumes (user_id, filename, content_type, size, storage_key, checksum, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID, doc.Filename, doc.ContentType, len(doc.Data), key, doc.Checksum, now)
+
+	if err != nil {
+		fileStorage.Delete(key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar currículo"})
+		return
+	}
+
+	resumeID, _ := result.LastInsertId()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Currículo enviado com sucesso",
+		"resume": Resume{
+			ID:          int(resumeID),
+			UserID:      userID,
+			Filename:    doc.Filename,
+			ContentType: doc.ContentType,
+			Size:        int64(len(doc.Data)),
+			Checksum:    doc.Checksum,
+			CreatedAt:   now,
+		},
+	})
+}
+
+func downloadResumeHandler(c *gin.Context) {
+	resumeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var resume Resume
+	err = db.QueryRow(`
+		SELECT filename, content_type, size, storage_key FROM resumes
+		WHERE id = ? AND user_id = ?`, resumeID, userID).Scan(
+		&resume.Filename, &resume.ContentType, &resume.Size, &resume.StorageKey)
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Currículo não encontrado"})
+		return
+	}
+
+	serveStoredFile(c, resume.StorageKey, resume.Filename, resume.ContentType, resume.Size)
+}
+
+// deleteResumeHandler remove o currículo do perfil. As cópias anexadas a
+// candidaturas já enviadas não são afetadas.
+func deleteResumeHandler(c *gin.Context) {
+	resumeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var key string
+	err = db.QueryRow("SELECT storage_key FROM resumes WHERE id = ? AND user_id = ?", resumeID, userID).Scan(&key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Currículo não encontrado"})
+		return
+	}
+
+	_, err = db.Exec("DELETE FROM resumes WHERE id = ?", resumeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir currículo"})
+		return
+	}
+
+	fileStorage.Delete(key)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Currículo excluído com sucesso"})
+}
+
+// snapshotResume copia o currículo escolhido para uma chave própria da
+// candidatura, para que alterações posteriores no perfil não mudem o que foi enviado
+func snapshotResume(resumeID, userID int) (*ApplicationResume, error) {
+	var resume Resume
+	err := db.QueryRow(`
+		SELECT id, filename, content_type, size, storage_key, checksum FROM resumes
+		WHERE id = ? AND user_id = ?`, resumeID, userID).Scan(
+		&resume.ID, &resume.Filename, &resume.ContentType, &resume.Size, &resume.StorageKey, &resume.Checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("applications/%s%s", token, extensionFor(resume.ContentType))
+
+	if err := copyStoredFile(fileStorage, resume.StorageKey, key); err != nil {
+		return nil, err
+	}
+
+	return &ApplicationResume{
+		ResumeID:    resume.ID,
+		Filename:    resume.Filename,
+		ContentType: resume.ContentType,
+		Size:        resume.Size,
+		StorageKey:  key,
+		Checksum:    resume.Checksum,
+	}, nil
+}
+
+// downloadApplicationResumeHandler libera o currículo enviado apenas para o
+// candidato e para a equipe de contratação da vaga
+func downloadApplicationResumeHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var applicantID, jobID int
+	err = db.QueryRow("SELECT user_id, job_id FROM applications WHERE id = ?", appID).Scan(&applicantID, &jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	if userID != applicantID && !isHiringSide(jobID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Você não tem permissão para acessar este currículo"})
+		return
+	}
+
+	var doc ApplicationResume
+	err = db.QueryRow(`
+		SELECT filename, content_type, size, storage_key FROM application_resumes
+		WHERE application_id = ?`, appID).Scan(&doc.Filename, &doc.ContentType, &doc.Size, &doc.StorageKey)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Esta candidatura não possui currículo"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar currículo"})
+		return
+	}
+
+	serveStoredFile(c, doc.StorageKey, doc.Filename, doc.ContentType, doc.Size)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Antecedência com que quem salvou a vaga é avisado do encerramento
+var savedJobReminderWindow = time.Duration(getEnvInt("SAVED_JOB_REMINDER_HOURS", 48)) * time.Hour
+
+func saveJobHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var exists int
+	err = db.QueryRow("SELECT COUNT(*) FROM jobs WHERE id = ? AND deleted_at IS NULL", jobID).Scan(&exists)
+	if err != nil || exists == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
+		return
+	}
+
+	// Salvar de novo uma vaga já salva não altera nada
+	_, err = db.Exec(`
+		INSERT OR IGNORE INTO saved_jobs (user_id, job_id, created_at)
+		VALUES (?, ?, ?)`, c.GetInt("user_id"), jobID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar vaga"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Vaga salva", "saved": true})
+}
+
+func unsaveJobHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	_, err = db.Exec("DELETE FROM saved_jobs WHERE user_id = ? AND job_id = ?", c.GetInt("user_id"), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover vaga salva"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Vaga removida das salvas", "saved": false})
+}
+
+// getSavedJobsHandler lista as vagas salvas pelo usuário, das mais recentes
+// para as mais antigas. Vagas excluídas deixam de aparecer, mas voltam se
+// forem restauradas.
+func getSavedJobsHandler(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	rows, err := db.Query(`
+		SELECT j.id, j.title, j.company, j.location, j.salary, j.type, j.closes_at, s.created_at,
+		       EXISTS (SELECT 1 FROM applications a WHERE a.job_id = j.id AND a.user_id = s.user_id) as applied
+		FROM saved_jobs s
+		JOIN jobs j ON s.job_id = j.id
+		WHERE s.user_id = ? AND j.deleted_at IS NULL
+		ORDER BY s.created_at DESC`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar vagas salvas"})
+		return
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	jobs := []gin.H{}
+	for rows.Next() {
+		var job Job
+		var closesAt sql.NullTime
+		var savedAt time.Time
+		var applied bool
+		if err := rows.Scan(&job.ID, &job.Title, &job.Company, &job.Location, &job.Salary, &job.Type,
+			&closesAt, &savedAt, &applied); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar vagas salvas"})
+			return
+		}
+		if closesAt.Valid {
+			job.ClosesAt = &closesAt.Time
+		}
+
+		jobs = append(jobs, gin.H{
+			"id":        job.ID,
+			"title":     job.Title,
+			"company":   job.Company,
+			"location":  job.Location,
+			"salary":    job.Salary,
+			"type":      job.Type,
+			"closes_at": job.ClosesAt,
+			"closed":    closesAt.Valid && !closesAt.Time.After(now),
+			"applied":   applied,
+			"saved_at":  savedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_jobs": jobs})
+}
+
+// runSavedJobReminders avisa quem salvou uma vaga que ela encerra dentro de
+// SAVED_JOB_REMINDER_HOURS. Cada vaga salva gera um aviso por data de
+// encerramento, e quem já se candidatou não é avisado.
+func runSavedJobReminders(payload []byte) error {
+	now := time.Now().UTC()
+	rows, err := db.Query(`
+		SELECT s.user_id, j.id, j.title, j.company, j.closes_at
+		FROM saved_jobs s
+		JOIN jobs j ON s.job_id = j.id
+		WHERE s.reminded_at IS NULL AND j.deleted_at IS NULL
+		  AND j.closes_at > ? AND j.closes_at <= ?
+		  AND NOT EXISTS (SELECT 1 FROM applications a WHERE a.job_id = j.id AND a.user_id = s.user_id)`,
+		now, now.Add(savedJobReminderWindow))
+	if err != nil {
+		return err
+	}
+
+	type reminder struct {
+		userID, jobID  int
+		title, company string
+		closesAt       time.Time
+	}
+	var reminders []reminder
+	for rows.Next() {
+		var r reminder
+		if err := rows.Scan(&r.userID, &r.jobID, &r.title, &r.company, &r.closesAt); err != nil {
+			rows.Close()
+			return err
+		}
+		reminders = append(reminders, r)
+	}
+	rows.Close()
+
+	for _, r := range reminders {
+		err := withTx(func(tx *sql.Tx) error {
+			result, err := tx.Exec(`
+				UPDATE saved_jobs SET reminded_at = ?
+				WHERE user_id = ? AND job_id = ? AND reminded_at IS NULL`, now, r.userID, r.jobID)
+			if err != nil {
+				return err
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				return nil
+			}
+
+			message := fmt.Sprintf("A vaga %s (%s), que você salvou, encerra as inscrições em %s (UTC)",
+				r.title, r.company, r.closesAt.UTC().Format("02/01/2006 15:04"))
+			return createNotification(tx, r.userID, "saved_job_closing", message, 0)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Endereço do frontend onde o candidato abre o link de agendamento
+var schedulingBaseURL = strings.TrimRight(getEnv("SCHEDULING_BASE_URL", "http://localhost:5173/agendar"), "/")
+
+var (
+	errLinkUnavailable = errors.New("Este link de agendamento não está mais disponível")
+	errSlotUnavailable = errors.New("Este horário não está mais disponível")
+)
+
+// signSchedulingToken gera o token do link: id e expiração assinados com HMAC,
+// o que impede que o candidato forje links para outras candidaturas
+func signSchedulingToken(linkID int, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", linkID, expiresAt.Unix())
+	return payload + "." + schedulingSignature(payload)
+}
+
+func schedulingSignature(payload string) string {
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte("scheduling:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseSchedulingToken confere a assinatura e devolve o id do link
+func parseSchedulingToken(token string) (int, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(schedulingSignature(payload))) {
+		return 0, false
+	}
+
+	linkID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return 0, false
+	}
+	return linkID, true
+}
+
+func schedulingURL(link *SchedulingLink) string {
+	return schedulingBaseURL + "/" + signSchedulingToken(link.ID, link.ExpiresAt)
+}
+
+func loadSchedulingLink(q dbtx, linkID int) (*SchedulingLink, error) {
+	var link SchedulingLink
+	var interviewID sql.NullInt64
+	var revokedAt sql.NullTime
+	err := q.QueryRow(`
+		SELECT id, application_id, type, duration_minutes, time_zone, location, video_link,
+		       window_start, window_end, expires_at, interview_id, revoked_at, created_by, created_at
+		FROM scheduling_links WHERE id = ?`, linkID).Scan(&link.ID, &link.ApplicationID, &link.Type,
+		&link.DurationMinutes, &link.TimeZone, &link.Location, &link.VideoLink, &link.WindowStart,
+		&link.WindowEnd, &link.ExpiresAt, &interviewID, &revokedAt, &link.CreatedBy, &link.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if interviewID.Valid {
+		id := int(interviewID.Int64)
+		link.InterviewID = &id
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+
+	rows, err := q.Query(`
+		SELECT u.id, u.name, u.email
+		FROM scheduling_link_interviewers li
+		JOIN users u ON li.user_id = u.id
+		WHERE li.link_id = ?
+		ORDER BY u.name`, linkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	link.Interviewers = []InterviewParticipant{}
+	for rows.Next() {
+		var p InterviewParticipant
+		if err := rows.Scan(&p.UserID, &p.Name, &p.Email); err != nil {
+			return nil, err
+		}
+		link.Interviewers = append(link.Interviewers, p)
+	}
+	return &link, rows.Err()
+}
+
+// schedulingLinkStatus resume a situação do link: open, booked, revoked ou expired
+func schedulingLinkStatus(link *SchedulingLink) string {
+	switch {
+	case link.InterviewID != nil:
+		return "booked"
+	case link.RevokedAt != nil:
+		return "revoked"
+	case time.Now().After(link.ExpiresAt):
+		return "expired"
+	}
+	return "open"
+}
+
+func (link *SchedulingLink) interviewerIDs() []int {
+	ids := make([]int, 0, len(link.Interviewers))
+	for _, p := range link.Interviewers {
+		ids = append(ids, p.UserID)
+	}
+	return ids
+}
+
+func createSchedulingLinkHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req SchedulingLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, appID); !ok {
+		return
+	}
+
+	ctx, err := loadInterviewContext(appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return
+	}
+
+	if ctx.Status == "withdrawn" || ctx.Status == "rejected" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Não é possível agendar entrevistas para esta candidatura"})
+		return
+	}
+
+	if req.Type == "video" && req.VideoLink == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe o link da chamada para entrevistas por vídeo"})
+		return
+	}
+	if req.Type == "onsite" && strings.TrimSpace(req.Location) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe o local da entrevista presencial"})
+		return
+	}
+
+	loc, err := time.LoadLocation(req.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fuso horário inválido: " + req.TimeZone})
+		return
+	}
+
+	windowStart, windowEnd, err := parseDateRange(req.From, req.To, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interviewers, err := loadInterviewers(ctx.JobID, req.InterviewerIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = 72
+	}
+	expiresAt := time.Now().UTC().Add(time.Duration(req.ExpiresInHours) * time.Hour).Truncate(time.Second)
+	if expiresAt.After(windowEnd) {
+		expiresAt = windowEnd
+	}
+
+	link := &SchedulingLink{
+		ApplicationID:   appID,
+		Type:            req.Type,
+		DurationMinutes: req.DurationMinutes,
+		TimeZone:        req.TimeZone,
+		Location:        req.Location,
+		VideoLink:       req.VideoLink,
+		WindowStart:     windowStart,
+		WindowEnd:       windowEnd,
+		ExpiresAt:       expiresAt,
+		Interviewers:    interviewers,
+		CreatedBy:       c.GetInt("user_id"),
+		CreatedAt:       time.Now(),
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO scheduling_links (application_id, type, duration_minutes, time_zone, location, video_link,
+			                              window_start, window_end, expires_at, created_by, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			appID, req.Type, req.DurationMinutes, req.TimeZone, req.Location, req.VideoLink,
+			windowStart, windowEnd, expiresAt, link.CreatedBy, link.CreatedAt)
+		if err != nil {
+			return err
+		}
+		id, _ := result.LastInsertId()
+		link.ID = int(id)
+
+		for _, p := range interviewers {
+			if _, err := tx.Exec("INSERT INTO scheduling_link_interviewers (link_id, user_id) VALUES (?, ?)", link.ID, p.UserID); err != nil {
+				return err
+			}
+		}
+
+		return enqueueEmail(tx, Email{
+			To:      []string{ctx.Candidate.Email},
+			Subject: fmt.Sprintf("Agende sua entrevista - %s", ctx.JobTitle),
+			Body: fmt.Sprintf("Olá, %s.\n\nEscolha o melhor horário para a sua entrevista para a vaga %s (%s):\n%s\n\nO link é válido até %s.",
+				ctx.Candidate.Name, ctx.JobTitle, ctx.Company, schedulingURL(link), expiresAt.In(loc).Format("02/01/2006 15:04")),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar link de agendamento"})
+		return
+	}
+
+	url := schedulingURL(link)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Link de agendamento criado com sucesso",
+		"link":    link,
+		"url":     url,
+	})
+}
+
+func getSchedulingLinksHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, appID); !ok {
+		return
+	}
+
+	rows, err := db.Query("SELECT id FROM scheduling_links WHERE application_id = ? ORDER BY created_at DESC, id DESC", appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar links de agendamento"})
+		return
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	links := []gin.H{}
+	for _, id := range ids {
+		link, err := loadSchedulingLink(db, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar links de agendamento"})
+			return
+		}
+		item := gin.H{"link": link, "status": schedulingLinkStatus(link)}
+		if schedulingLinkStatus(link) == "open" {
+			item["url"] = schedulingURL(link)
+		}
+		links = append(links, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// revokeSchedulingLinkHandler invalida um link que ainda não foi usado
+func revokeSchedulingLinkHandler(c *gin.Context) {
+	linkID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	link, err := loadSchedulingLink(db, linkID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link de agendamento não encontrado"})
+		return
+	}
+
+	if _, ok := requireHiringAccess(c, link.ApplicationID); !ok {
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE scheduling_links SET revoked_at = ?
+		WHERE id = ? AND interview_id IS NULL AND revoked_at IS NULL`, time.Now(), linkID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao revogar link de agendamento"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "O link já foi usado ou revogado"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Link de agendamento revogado com sucesso"})
+}
+
+// publicSchedulingLink valida o token do link público e carrega seus dados
+func publicSchedulingLink(c *gin.Context) (*SchedulingLink, *interviewContext, bool) {
+	linkID, ok := parseSchedulingToken(c.Param("token"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link de agendamento inválido ou expirado"})
+		return nil, nil, false
+	}
+
+	link, err := loadSchedulingLink(db, linkID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link de agendamento inválido ou expirado"})
+		return nil, nil, false
+	}
+
+	ctx, err := loadInterviewContext(link.ApplicationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link de agendamento inválido ou expirado"})
+		return nil, nil, false
+	}
+	return link, ctx, true
+}
+
+// getPublicSchedulingHandler mostra ao candidato os horários livres do link
+func getPublicSchedulingHandler(c *gin.Context) {
+	link, ctx, ok := publicSchedulingLink(c)
+	if !ok {
+		return
+	}
+
+	loc, err := time.LoadLocation(link.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	status := schedulingLinkStatus(link)
+	response := gin.H{
+		"job_title":        ctx.JobTitle,
+		"company":          ctx.Company,
+		"candidate_name":   ctx.Candidate.Name,
+		"type":             link.Type,
+		"duration_minutes": link.DurationMinutes,
+		"time_zone":        link.TimeZone,
+		"status":           status,
+		"slots":            []AvailableSlot{},
+	}
+
+	switch status {
+	case "open":
+		slots, err := computeOpenSlots(db, link.interviewerIDs(), link.WindowStart, link.WindowEnd,
+			time.Duration(link.DurationMinutes)*time.Minute)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular horários"})
+			return
+		}
+		response["slots"] = toAvailableSlots(slots, loc)
+	case "booked":
+		var startsAt, endsAt time.Time
+		db.QueryRow("SELECT starts_at, ends_at FROM interviews WHERE id = ?", *link.InterviewID).Scan(&startsAt, &endsAt)
+		response["interview"] = AvailableSlot{StartsAt: startsAt, EndsAt: endsAt, Local: startsAt.In(loc).Format("2006-01-02T15:04")}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// bookPublicSchedulingHandler reserva o horário escolhido pelo candidato. A
+// conferência do horário, a criação da entrevista e o uso do link acontecem
+// na mesma transação: o link só pode ser usado uma vez e o horário não pode
+// ter sido ocupado por outro agendamento.
+func bookPublicSchedulingHandler(c *gin.Context) {
+	var req BookSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, ctx, ok := publicSchedulingLink(c)
+	if !ok {
+		return
+	}
+
+	if schedulingLinkStatus(link) != "open" || ctx.Status == "withdrawn" || ctx.Status == "rejected" {
+		c.JSON(http.StatusGone, gin.H{"error": errLinkUnavailable.Error()})
+		return
+	}
+
+	loc, err := time.LoadLocation(link.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	start, err := parseInterviewTime(req.StartsAt, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start = start.UTC().Truncate(time.Minute)
+	duration := time.Duration(link.DurationMinutes) * time.Minute
+
+	// A equipe pode ter mudado desde a criação do link
+	interviewers, err := loadInterviewers(ctx.JobID, link.interviewerIDs())
+	if err != nil || len(interviewers) == 0 {
+		c.JSON(http.StatusGone, gin.H{"error": errLinkUnavailable.Error()})
+		return
+	}
+
+	uid, err := newInterviewUID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao agendar entrevista"})
+		return
+	}
+
+	now := time.Now()
+	iv := &Interview{
+		ApplicationID: link.ApplicationID,
+		Type:          link.Type,
+		StartsAt:      start,
+		EndsAt:        start.Add(duration),
+		TimeZone:      link.TimeZone,
+		Location:      link.Location,
+		VideoLink:     link.VideoLink,
+		Status:        "scheduled",
+		UID:           uid,
+		Interviewers:  interviewers,
+		CreatedBy:     link.CreatedBy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		slots, err := computeOpenSlots(tx, link.interviewerIDs(), link.WindowStart, link.WindowEnd, duration)
+		if err != nil {
+			return err
+		}
+		available := false
+		for _, s := range slots {
+			if s.Start.Equal(start) {
+				available = true
+				break
+			}
+		}
+		if !available {
+			return errSlotUnavailable
+		}
+
+		if _, err := insertInterview(tx, iv, ctx); err != nil {
+			if errors.Is(err, errInterviewConflict) {
+				return errSlotUnavailable
+			}
+			return err
+		}
+
+		result, err := tx.Exec(`
+			UPDATE scheduling_links SET interview_id = ?
+			WHERE id = ? AND interview_id IS NULL AND revoked_at IS NULL AND expires_at > ?`,
+			iv.ID, link.ID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errLinkUnavailable
+		}
+
+		message := fmt.Sprintf("%s agendou a entrevista para a vaga %s", ctx.Candidate.Name, ctx.JobTitle)
+		if err := createNotification(tx, link.CreatedBy, "interview_booked", message, link.ApplicationID); err != nil {
+			return err
+		}
+		return sendInterviewInvites(tx, iv, ctx, nil)
+	})
+	if errors.Is(err, errSlotUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, errLinkUnavailable) {
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Erro ao agendar entrevista pelo link %d: %v", link.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao agendar entrevista"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Entrevista agendada com sucesso",
+		"starts_at": iv.StartsAt,
+		"ends_at":   iv.EndsAt,
+		"local":     iv.StartsAt.In(loc).Format("2006-01-02T15:04"),
+		"time_zone": iv.TimeZone,
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedulingToken(t *testing.T) {
+	valid := signSchedulingToken(42, time.Now().Add(time.Hour))
+	parts := strings.Split(valid, ".")
+	tampered := []byte(parts[2])
+	tampered[0] ^= 1
+
+	tests := []struct {
+		name   string
+		token  string
+		wantID int
+		wantOK bool
+	}{
+		{name: "válido", token: valid, wantID: 42, wantOK: true},
+		{name: "expirado", token: signSchedulingToken(42, time.Now().Add(-time.Minute))},
+		{name: "id trocado", token: "43." + parts[1] + "." + parts[2]},
+		{name: "expiração trocada", token: parts[0] + ".9999999999." + parts[2]},
+		{name: "assinatura trocada", token: parts[0] + "." + parts[1] + "." + string(tampered)},
+		{name: "sem assinatura", token: parts[0] + "." + parts[1]},
+		{name: "partes a mais", token: valid + ".1"},
+		{name: "vazio", token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := parseSchedulingToken(tt.token)
+			if ok != tt.wantOK || (ok && id != tt.wantID) {
+				t.Errorf("parseSchedulingToken(%q) = %d, %v; want %d, %v", tt.token, id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+
+	// A assinatura é específica dos links de agendamento
+	if schedulingSignature("42.1") == schedulingSignature("42.2") {
+		t.Error("assinaturas iguais para payloads diferentes")
+	}
+}
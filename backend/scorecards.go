@@ -0,0 +1,599 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Peso de cada recomendação no cálculo da recomendação geral
+var recommendationWeights = map[string]float64{
+	"strong_yes": 2,
+	"yes":        1,
+	"no":         -1,
+	"strong_no":  -2,
+}
+
+var (
+	errScorecardSubmitted = errors.New("Scorecards enviados não podem ser alterados")
+	errCriterionNotFound  = errors.New("Critério não encontrado")
+)
+
+func listScorecardCriteria(q dbtx, jobID int) ([]ScorecardCriterion, error) {
+	rows, err := q.Query(`
+		SELECT id, job_id, name, description, scale_max, position
+		FROM scorecard_criteria
+		WHERE job_id = ?
+		ORDER BY position, id`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	criteria := []ScorecardCriterion{}
+	for rows.Next() {
+		var criterion ScorecardCriterion
+		if err := rows.Scan(&criterion.ID, &criterion.JobID, &criterion.Name, &criterion.Description,
+			&criterion.ScaleMax, &criterion.Position); err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, criterion)
+	}
+	return criteria, rows.Err()
+}
+
+// listScorecards devolve todos os scorecards da candidatura, incluindo rascunhos;
+// quem chama decide o que pode ser exibido
+func listScorecards(appID int) ([]Scorecard, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.application_id, s.interviewer_id, u.name, s.recommendation, s.comments, s.status,
+		       s.submitted_at, s.created_at, s.updated_at
+		FROM scorecards s
+		JOIN users u ON s.interviewer_id = u.id
+		WHERE s.application_id = ?
+		ORDER BY s.created_at, s.id`, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := []Scorecard{}
+	index := map[int]int{}
+	for rows.Next() {
+		var card Scorecard
+		var submittedAt sql.NullTime
+		if err := rows.Scan(&card.ID, &card.ApplicationID, &card.InterviewerID, &card.InterviewerName,
+			&card.Recommendation, &card.Comments, &card.Status, &submittedAt, &card.CreatedAt, &card.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if submittedAt.Valid {
+			card.SubmittedAt = &submittedAt.Time
+		}
+		card.Ratings = []ScorecardRating{}
+		index[card.ID] = len(cards)
+		cards = append(cards, card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ratingRows, err := db.Query(`
+		SELECT r.scorecard_id, r.criterion_id, r.criterion, r.rating, r.scale_max, r.comment
+		FROM scorecard_ratings r
+		JOIN scorecards s ON r.scorecard_id = s.id
+		WHERE s.application_id = ?
+		ORDER BY r.criterion_id`, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer ratingRows.Close()
+
+	for ratingRows.Next() {
+		var cardID int
+		var rating ScorecardRating
+		if err := ratingRows.Scan(&cardID, &rating.CriterionID, &rating.Criterion, &rating.Rating,
+			&rating.ScaleMax, &rating.Comment); err != nil {
+			return nil, err
+		}
+		if i, ok := index[cardID]; ok {
+			cards[i].Ratings = append(cards[i].Ratings, rating)
+		}
+	}
+	return cards, ratingRows.Err()
+}
+
+// canSeeOtherScorecards aplica a regra contra viés: quem tem um scorecard só vê
+// os dos colegas depois de enviar o seu. Quem não avalia a candidatura vê os
+// enviados apenas se for o dono da vaga ou gerente de contratação.
+func canSeeOtherScorecards(ctx *hiringContext, userID int, cards []Scorecard) bool {
+	for _, card := range cards {
+		if card.InterviewerID == userID {
+			return card.Status == "submitted"
+		}
+	}
+
+	if ctx.JobOwnerID == userID {
+		return true
+	}
+
+	var role string
+	db.QueryRow("SELECT role FROM job_team_members WHERE job_id = ? AND user_id = ?", ctx.JobID, userID).Scan(&role)
+	return role == "hiring_manager"
+}
+
+// summarizeScorecards calcula médias e a recomendação geral dos scorecards enviados
+func summarizeScorecards(cards []Scorecard) ScorecardSummary {
+	summary := ScorecardSummary{
+		Recommendations: map[string]int{"strong_yes": 0, "yes": 0, "no": 0, "strong_no": 0},
+		Criteria:        []CriterionAverage{},
+	}
+
+	var normalizedSum, recommendationSum float64
+	var ratingCount int
+	criteria := map[int]*CriterionAverage{}
+	var order []int
+
+	for _, card := range cards {
+		if card.Status != "submitted" {
+			continue
+		}
+		summary.SubmittedCount++
+		summary.Recommendations[card.Recommendation]++
+		recommendationSum += recommendationWeights[card.Recommendation]
+
+		for _, rating := range card.Ratings {
+			// Escalas começam em 1: a nota mínima vale 0 e a máxima vale 1
+			normalizedSum += float64(rating.Rating-1) / float64(rating.ScaleMax-1)
+			ratingCount++
+
+			avg, ok := criteria[rating.CriterionID]
+			if !ok {
+				avg = &CriterionAverage{CriterionID: rating.CriterionID, Criterion: rating.Criterion, ScaleMax: rating.ScaleMax}
+				criteria[rating.CriterionID] = avg
+				order = append(order, rating.CriterionID)
+			}
+			avg.Average += float64(rating.Rating)
+			avg.Count++
+		}
+	}
+
+	for _, id := range order {
+		avg := criteria[id]
+		avg.Average = math.Round(avg.Average/float64(avg.Count)*100) / 100
+		summary.Criteria = append(summary.Criteria, *avg)
+	}
+
+	if ratingCount > 0 {
+		score := math.Round(normalizedSum/float64(ratingCount)*1000) / 10
+		summary.OverallScore = &score
+	}
+
+	if summary.SubmittedCount > 0 {
+		mean := recommendationSum / float64(summary.SubmittedCount)
+		switch {
+		case mean >= 1.5:
+			summary.Recommendation = "strong_yes"
+		case mean >= 0.5:
+			summary.Recommendation = "yes"
+		case mean <= -1.5:
+			summary.Recommendation = "strong_no"
+		case mean <= -0.5:
+			summary.Recommendation = "no"
+		default:
+			summary.Recommendation = "mixed"
+		}
+	}
+	return summary
+}
+
+// visibleScorecardSummary devolve o resumo para a equipe de contratação, ou
+// nil quando o usuário ainda não pode ver as avaliações dos colegas
+func visibleScorecardSummary(appID, userID int) *ScorecardSummary {
+	ctx := &hiringContext{ApplicationID: appID}
+	err := db.QueryRow(`
+		SELECT j.id, j.user_id FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		WHERE a.id = ?`, appID).Scan(&ctx.JobID, &ctx.JobOwnerID)
+	if err != nil || !isHiringSide(ctx.JobID, userID) {
+		return nil
+	}
+
+	cards, err := listScorecards(appID)
+	if err != nil || !canSeeOtherScorecards(ctx, userID, cards) {
+		return nil
+	}
+
+	summary := summarizeScorecards(cards)
+	return &summary
+}
+
+func getScorecardTemplateHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if !requireHiringTeam(c, jobID) {
+		return
+	}
+
+	criteria, err := listScorecardCriteria(db, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar critérios"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"criteria": criteria})
+}
+
+// updateScorecardTemplateHandler substitui os critérios da vaga. Scorecards já
+// enviados mantêm a cópia dos critérios; dos rascunhos são removidas as notas
+// de critérios excluídos ou acima da nova escala.
+func updateScorecardTemplateHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ScorecardTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	names := map[string]bool{}
+	for i := range req.Criteria {
+		criterion := &req.Criteria[i]
+		criterion.Name = strings.TrimSpace(criterion.Name)
+		key := strings.ToLower(criterion.Name)
+		if key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "O nome do critério é obrigatório"})
+			return
+		}
+		if names[key] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Critério duplicado: %s", criterion.Name)})
+			return
+		}
+		names[key] = true
+		if criterion.ScaleMax == 0 {
+			criterion.ScaleMax = 5
+		}
+	}
+
+	if !requireJobOwner(c, jobID) {
+		return
+	}
+
+	var criteria []ScorecardCriterion
+	err = withTx(func(tx *sql.Tx) error {
+		existing, err := listScorecardCriteria(tx, jobID)
+		if err != nil {
+			return err
+		}
+		current := map[int]bool{}
+		for _, criterion := range existing {
+			current[criterion.ID] = true
+		}
+
+		kept := map[int]bool{}
+		for position, criterion := range req.Criteria {
+			if criterion.ID != 0 {
+				if !current[criterion.ID] {
+					return fmt.Errorf("%w: %d", errCriterionNotFound, criterion.ID)
+				}
+				kept[criterion.ID] = true
+				_, err = tx.Exec(`
+					UPDATE scorecard_criteria SET name = ?, description = ?, scale_max = ?, position = ?
+					WHERE id = ?`, criterion.Name, criterion.Description, criterion.ScaleMax, position, criterion.ID)
+				if err != nil {
+					return err
+				}
+				_, err = tx.Exec(`
+					DELETE FROM scorecard_ratings
+					WHERE criterion_id = ? AND rating > ?
+					AND scorecard_id IN (SELECT id FROM scorecards WHERE status = 'draft')`, criterion.ID, criterion.ScaleMax)
+				if err != nil {
+					return err
+				}
+				_, err = tx.Exec(`
+					UPDATE scorecard_ratings SET criterion = ?, scale_max = ?
+					WHERE criterion_id = ? AND scorecard_id IN (SELECT id FROM scorecards WHERE status = 'draft')`,
+					criterion.Name, criterion.ScaleMax, criterion.ID)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			_, err = tx.Exec(`
+				INSERT INTO scorecard_criteria (job_id, name, description, scale_max, position)
+				VALUES (?, ?, ?, ?, ?)`, jobID, criterion.Name, criterion.Description, criterion.ScaleMax, position)
+			if err != nil {
+				return err
+			}
+		}
+
+		for id := range current {
+			if kept[id] {
+				continue
+			}
+			_, err = tx.Exec(`
+				DELETE FROM scorecard_ratings
+				WHERE criterion_id = ? AND scorecard_id IN (SELECT id FROM scorecards WHERE status = 'draft')`, id)
+			if err != nil {
+				return err
+			}
+			if _, err = tx.Exec("DELETE FROM scorecard_criteria WHERE id = ?", id); err != nil {
+				return err
+			}
+		}
+
+		criteria, err = listScorecardCriteria(tx, jobID)
+		return err
+	})
+	if errors.Is(err, errCriterionNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar critérios"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Critérios atualizados com sucesso",
+		"criteria": criteria,
+	})
+}
+
+// getScorecardsHandler lista os scorecards visíveis para o usuário. O próprio
+// scorecard sempre aparece; os dos colegas seguem a regra de ocultação.
+func getScorecardsHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ctx, ok := requireHiringAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	cards, err := listScorecards(appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar scorecards"})
+		return
+	}
+
+	seeOthers := canSeeOtherScorecards(ctx, userID, cards)
+
+	visible := []Scorecard{}
+	hidden := 0
+	for _, card := range cards {
+		switch {
+		case card.InterviewerID == userID:
+			visible = append(visible, card)
+		case card.Status == "submitted" && seeOthers:
+			visible = append(visible, card)
+		case card.Status == "submitted":
+			hidden++
+		}
+	}
+
+	var summary *ScorecardSummary
+	if seeOthers {
+		s := summarizeScorecards(cards)
+		summary = &s
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scorecards":   visible,
+		"hidden_count": hidden,
+		"summary":      summary,
+	})
+}
+
+// saveScorecardHandler cria ou atualiza o rascunho do scorecard do usuário
+func saveScorecardHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ScorecardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, ok := requireHiringAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	criteria, err := listScorecardCriteria(db, ctx.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar critérios"})
+		return
+	}
+	if len(criteria) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A vaga ainda não possui critérios de avaliação"})
+		return
+	}
+
+	byID := map[int]ScorecardCriterion{}
+	for _, criterion := range criteria {
+		byID[criterion.ID] = criterion
+	}
+
+	seen := map[int]bool{}
+	for _, rating := range req.Ratings {
+		criterion, ok := byID[rating.CriterionID]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Critério %d não pertence a esta vaga", rating.CriterionID)})
+			return
+		}
+		if seen[rating.CriterionID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Critério repetido: %s", criterion.Name)})
+			return
+		}
+		seen[rating.CriterionID] = true
+		if rating.Rating > criterion.ScaleMax {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A nota de %s deve ficar entre 1 e %d", criterion.Name, criterion.ScaleMax)})
+			return
+		}
+	}
+
+	now := time.Now()
+	err = withTx(func(tx *sql.Tx) error {
+		var cardID int64
+		var status string
+		err := tx.QueryRow("SELECT id, status FROM scorecards WHERE application_id = ? AND interviewer_id = ?", appID, userID).Scan(&cardID, &status)
+		switch {
+		case err == sql.ErrNoRows:
+			result, err := tx.Exec(`
+				INSERT INTO scorecards (application_id, interviewer_id, recommendation, comments, status, created_at, updated_at)
+				VALUES (?, ?, ?, ?, 'draft', ?, ?)`, appID, userID, req.Recommendation, req.Comments, now, now)
+			if err != nil {
+				return err
+			}
+			cardID, _ = result.LastInsertId()
+		case err != nil:
+			return err
+		case status == "submitted":
+			return errScorecardSubmitted
+		default:
+			_, err = tx.Exec("UPDATE scorecards SET recommendation = ?, comments = ?, updated_at = ? WHERE id = ?",
+				req.Recommendation, req.Comments, now, cardID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec("DELETE FROM scorecard_ratings WHERE scorecard_id = ?", cardID); err != nil {
+			return err
+		}
+		for _, rating := range req.Ratings {
+			criterion := byID[rating.CriterionID]
+			_, err := tx.Exec(`
+				INSERT INTO scorecard_ratings (scorecard_id, criterion_id, criterion, rating, scale_max, comment)
+				VALUES (?, ?, ?, ?, ?, ?)`, cardID, criterion.ID, criterion.Name, rating.Rating, criterion.ScaleMax, rating.Comment)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errScorecardSubmitted) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar scorecard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scorecard salvo como rascunho"})
+}
+
+// submitScorecardHandler envia o rascunho do usuário. Todos os critérios e a
+// recomendação são obrigatórios, e depois do envio o scorecard não muda mais.
+func submitScorecardHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ctx, ok := requireHiringAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	cards, err := listScorecards(appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar scorecard"})
+		return
+	}
+
+	var card *Scorecard
+	for i := range cards {
+		if cards[i].InterviewerID == userID {
+			card = &cards[i]
+		}
+	}
+	if card == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Preencha o scorecard antes de enviá-lo"})
+		return
+	}
+	if card.Status == "submitted" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Este scorecard já foi enviado"})
+		return
+	}
+	if card.Recommendation == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Informe a recomendação antes de enviar"})
+		return
+	}
+
+	criteria, err := listScorecardCriteria(db, ctx.JobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar critérios"})
+		return
+	}
+	rated := map[int]bool{}
+	for _, rating := range card.Ratings {
+		rated[rating.CriterionID] = true
+	}
+	for _, criterion := range criteria {
+		if !rated[criterion.ID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Avalie o critério %s antes de enviar", criterion.Name)})
+			return
+		}
+	}
+
+	now := time.Now()
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE scorecards SET status = 'submitted', submitted_at = ?, updated_at = ?
+			WHERE id = ? AND status = 'draft'`, now, now, card.ID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return errScorecardSubmitted
+		}
+
+		if ctx.JobOwnerID == userID {
+			return nil
+		}
+		message := fmt.Sprintf("%s enviou um scorecard sobre %s (%s)", card.InterviewerName, ctx.CandidateName, ctx.JobTitle)
+		return createNotification(tx, ctx.JobOwnerID, "scorecard_submitted", message, appID)
+	})
+	if errors.Is(err, errScorecardSubmitted) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Este scorecard já foi enviado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao enviar scorecard"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scorecard enviado com sucesso"})
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validateScreeningQuestion confere as opções e regras de eliminação de acordo com o tipo
+func validateScreeningQuestion(req *ScreeningQuestionRequest) error {
+	switch req.Type {
+	case "single_choice", "multi_choice":
+		if len(req.Options) < 2 {
+			return errors.New("Perguntas de múltipla escolha precisam de pelo menos duas opções")
+		}
+		options := make(map[string]bool)
+		for _, option := range req.Options {
+			if options[option] {
+				return fmt.Errorf("Opção duplicada: %s", option)
+			}
+			options[option] = true
+		}
+		for _, value := range req.KnockoutValues {
+			if !options[value] {
+				return fmt.Errorf("Resposta eliminatória não está entre as opções: %s", value)
+			}
+		}
+	case "yes_no":
+		req.Options = []string{"yes", "no"}
+		for _, value := range req.KnockoutValues {
+			if value != "yes" && value != "no" {
+				return errors.New("Respostas eliminatórias de sim/não devem ser 'yes' ou 'no'")
+			}
+		}
+	case "number":
+		req.Options = nil
+		if len(req.KnockoutValues) > 0 {
+			return errors.New("Use min_value e max_value para eliminar respostas numéricas")
+		}
+		if req.MinValue != nil && req.MaxValue != nil && *req.MinValue > *req.MaxValue {
+			return errors.New("min_value não pode ser maior que max_value")
+		}
+	case "text":
+		req.Options = nil
+		if len(req.KnockoutValues) > 0 {
+			return errors.New("Perguntas de texto livre não podem ser eliminatórias")
+		}
+	}
+
+	if req.Type != "number" && (req.MinValue != nil || req.MaxValue != nil) {
+		return errors.New("min_value e max_value só se aplicam a perguntas numéricas")
+	}
+	return nil
+}
+
+func listScreeningQuestions(jobID int) ([]ScreeningQuestion, error) {
+	rows, err := db.Query(`
+		SELECT id, job_id, question, type, options, required, knockout_values, min_value, max_value, position, created_at
+		FROM screening_questions
+		WHERE job_id = ?
+		ORDER BY position, id`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []ScreeningQuestion{}
+	for rows.Next() {
+		var q ScreeningQuestion
+		var options, knockout string
+		var minValue, maxValue sql.NullFloat64
+		err := rows.Scan(&q.ID, &q.JobID, &q.Question, &q.Type, &options, &q.Required, &knockout,
+			&minValue, &maxValue, &q.Position, &q.CreatedAt)
+		if err != nil {
+			continue
+		}
+
+		json.Unmarshal([]byte(options), &q.Options)
+		json.Unmarshal([]byte(knockout), &q.KnockoutValues)
+		if minValue.Valid {
+			q.MinValue = &minValue.Float64
+		}
+		if maxValue.Valid {
+			q.MaxValue = &maxValue.Float64
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+// evaluateAnswers valida as respostas contra as perguntas da vaga e indica as
+// que são eliminatórias. Os valores são normalizados para gravação.
+func evaluateAnswers(questions []ScreeningQuestion, answers []ScreeningAnswer) ([]ScreeningAnswer, error) {
+	byQuestion := make(map[int]ScreeningAnswer)
+	for _, answer := range answers {
+		if _, ok := byQuestion[answer.QuestionID]; ok {
+			return nil, fmt.Errorf("Pergunta %d respondida mais de uma vez", answer.QuestionID)
+		}
+		byQuestion[answer.QuestionID] = answer
+	}
+
+	var result []ScreeningAnswer
+	for _, q := range questions {
+		answer, ok := byQuestion[q.ID]
+		delete(byQuestion, q.ID)
+
+		if !ok || answer.Value == nil || answer.Value == "" {
+			if q.Required {
+				return nil, fmt.Errorf("A pergunta \"%s\" é obrigatória", q.Question)
+			}
+			continue
+		}
+
+		evaluated, err := evaluateAnswer(q, answer.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Resposta inválida para \"%s\": %s", q.Question, err.Error())
+		}
+		evaluated.QuestionID = q.ID
+		evaluated.Question = q.Question
+		result = append(result, evaluated)
+	}
+
+	for questionID := range byQuestion {
+		return nil, fmt.Errorf("A pergunta %d não pertence a esta vaga", questionID)
+	}
+
+	return result, nil
+}
+
+func evaluateAnswer(q ScreeningQuestion, value interface{}) (ScreeningAnswer, error) {
+	knockout := make(map[string]bool)
+	for _, v := range q.KnockoutValues {
+		knockout[v] = true
+	}
+
+	switch q.Type {
+	case "text":
+		text, ok := value.(string)
+		if !ok {
+			return ScreeningAnswer{}, errors.New("esperado um texto")
+		}
+		if len(text) > 5000 {
+			return ScreeningAnswer{}, errors.New("texto muito longo")
+		}
+		return ScreeningAnswer{Value: text}, nil
+
+	case "yes_no":
+		b, ok := value.(bool)
+		if !ok {
+			return ScreeningAnswer{}, errors.New("esperado true ou false")
+		}
+		answer := "no"
+		if b {
+			answer = "yes"
+		}
+		return ScreeningAnswer{Value: b, Knockout: knockout[answer]}, nil
+
+	case "single_choice":
+		choice, ok := value.(string)
+		if !ok || !containsString(q.Options, choice) {
+			return ScreeningAnswer{}, errors.New("escolha uma das opções")
+		}
+		return ScreeningAnswer{Value: choice, Knockout: knockout[choice]}, nil
+
+	case "multi_choice":
+		list, ok := value.([]interface{})
+		if !ok || len(list) == 0 {
+			return ScreeningAnswer{}, errors.New("esperada uma lista de opções")
+		}
+		var choices []string
+		isKnockout := false
+		seen := make(map[string]bool)
+		for _, item := range list {
+			choice, ok := item.(string)
+			if !ok || !containsString(q.Options, choice) {
+				return ScreeningAnswer{}, errors.New("escolha apenas entre as opções")
+			}
+			if seen[choice] {
+				continue
+			}
+			seen[choice] = true
+			choices = append(choices, choice)
+			isKnockout = isKnockout || knockout[choice]
+		}
+		return ScreeningAnswer{Value: choices, Knockout: isKnockout}, nil
+
+	case "number":
+		n, ok := value.(float64)
+		if !ok {
+			return ScreeningAnswer{}, errors.New("esperado um número")
+		}
+		isKnockout := (q.MinValue != nil && n < *q.MinValue) || (q.MaxValue != nil && n > *q.MaxValue)
+		return ScreeningAnswer{Value: n, Knockout: isKnockout}, nil
+	}
+
+	return ScreeningAnswer{}, errors.New("tipo de pergunta desconhecido")
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func listApplicationAnswers(appID int) ([]ScreeningAnswer, error) {
+	rows, err := db.Query(`
+		SELECT question_id, question, value, knockout FROM application_answers
+		WHERE application_id = ?
+		ORDER BY question_id`, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	answers := []ScreeningAnswer{}
+	for rows.Next() {
+		var answer ScreeningAnswer
+		var value string
+		if err := rows.Scan(&answer.QuestionID, &answer.Question, &value, &answer.Knockout); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(value), &answer.Value)
+		answers = append(answers, answer)
+	}
+	return answers, rows.Err()
+}
+
+// candidateScreeningQuestions remove as respostas eliminatórias e os limites
+// numéricos, que só a equipe de contratação pode ver; do contrário o
+// candidato saberia o que responder para passar pela triagem
+func candidateScreeningQuestions(questions []ScreeningQuestion, jobID, userID int) []ScreeningQuestion {
+	if isHiringSide(jobID, userID) {
+		return questions
+	}
+	for i := range questions {
+		questions[i].KnockoutValues = nil
+		questions[i].MinValue = nil
+		questions[i].MaxValue = nil
+	}
+	return questions
+}
+
+// candidateScreeningAnswers remove a marcação das respostas que eliminaram o
+// candidato, pelo mesmo motivo de candidateScreeningQuestions
+func candidateScreeningAnswers(answers []ScreeningAnswer, jobID, userID int) []ScreeningAnswer {
+	if isHiringSide(jobID, userID) {
+		return answers
+	}
+	for i := range answers {
+		answers[i].Knockout = false
+	}
+	return answers
+}
+
+func getScreeningQuestionsHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	questions, err := listScreeningQuestions(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar perguntas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"questions": candidateScreeningQuestions(questions, jobID, c.GetInt("user_id"))})
+}
+
+func createScreeningQuestionHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ScreeningQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateScreeningQuestion(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireJobOwner(c, jobID) {
+		return
+	}
+
+	options, _ := json.Marshal(nonNilStrings(req.Options))
+	knockout, _ := json.Marshal(nonNilStrings(req.KnockoutValues))
+
+	result, err := db.Exec(`
+		INSERT INTO screening_questions (job_id, question, type, options, required, knockout_values, min_value, max_value, position, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		jobID, req.Question, req.Type, string(options), req.Required, string(knockout), req.MinValue, req.MaxValue, req.Position, time.Now())
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar pergunta"})
+		return
+	}
+
+	questionID, _ := result.LastInsertId()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Pergunta criada com sucesso",
+		"question_id": questionID,
+	})
+}
+
+func updateScreeningQuestionHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	questionID, err := strconv.Atoi(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ScreeningQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateScreeningQuestion(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireJobOwner(c, jobID) {
+		return
+	}
+
+	options, _ := json.Marshal(nonNilStrings(req.Options))
+	knockout, _ := json.Marshal(nonNilStrings(req.KnockoutValues))
+
+	// Respostas já enviadas guardam o texto da pergunta e não são reavaliadas
+	result, err := db.Exec(`
+		UPDATE screening_questions SET question = ?, type = ?, options = ?, required = ?, knockout_values = ?, min_value = ?, max_value = ?, position = ?
+		WHERE id = ? AND job_id = ?`,
+		req.Question, req.Type, string(options), req.Required, string(knockout), req.MinValue, req.MaxValue, req.Position, questionID, jobID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar pergunta"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pergunta não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pergunta atualizada com sucesso"})
+}
+
+func deleteScreeningQuestionHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	questionID, err := strconv.Atoi(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if !requireJobOwner(c, jobID) {
+		return
+	}
+
+	result, err := db.Exec("DELETE FROM screening_questions WHERE id = ? AND job_id = ?", questionID, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir pergunta"})
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pergunta não encontrada"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pergunta excluída com sucesso"})
+}
+
+// nonNilStrings garante que listas vazias sejam gravadas como [] e não null
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCandidateScreeningViews(t *testing.T) {
+	setupTestDB(t)
+	db.Exec("INSERT INTO users (id, email, password, name) VALUES (1, 'dono@x.com', '', 'Dono'), (2, 'time@x.com', '', 'Time'), (3, 'ana@x.com', '', 'Ana')")
+	db.Exec("INSERT INTO jobs (id, title, description, company, location, type, user_id) VALUES (1, 'Dev', 'Dev', 'X', 'SP', 'full-time', 1)")
+	db.Exec("INSERT INTO job_team_members (job_id, user_id, role, added_by) VALUES (1, 2, 'interviewer', 1)")
+
+	limit := 3.0
+	tests := []struct {
+		name       string
+		userID     int
+		wantHidden bool
+	}{
+		{name: "dono da vaga", userID: 1},
+		{name: "membro da equipe", userID: 2},
+		{name: "candidato", userID: 3, wantHidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questions := candidateScreeningQuestions([]ScreeningQuestion{
+				{ID: 1, Type: "yes_no", KnockoutValues: []string{"no"}},
+				{ID: 2, Type: "number", MinValue: &limit, MaxValue: &limit},
+			}, 1, tt.userID)
+			answers := candidateScreeningAnswers([]ScreeningAnswer{
+				{QuestionID: 1, Value: "no", Knockout: true},
+				{QuestionID: 2, Value: 5.0},
+			}, 1, tt.userID)
+
+			body, err := json.Marshal(map[string]interface{}{"questions": questions, "answers": answers})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, field := range []string{"knockout_values", "min_value", "max_value", `"knockout"`} {
+				if hidden := !strings.Contains(string(body), field); hidden != tt.wantHidden {
+					t.Errorf("%s oculto = %v, want %v: %s", field, hidden, tt.wantHidden, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tamanho máximo do CSV da taxonomia de habilidades
+const maxSkillsImportSize = 2 << 20
+
+var errSkillsDryRun = errors.New("simulação de importação")
+
+// skillImportRowError é um problema em uma linha do CSV. A linha é ignorada e
+// as demais continuam sendo importadas.
+type skillImportRowError struct{ msg string }
+
+func (e *skillImportRowError) Error() string { return e.msg }
+
+func rowError(format string, args ...interface{}) error {
+	return &skillImportRowError{fmt.Sprintf(format, args...)}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// UnmarshalJSON aceita a habilidade da vaga como objeto ({"name", "level"})
+// ou apenas pelo nome, sem nível
+func (r *JobSkillRequest) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*r = JobSkillRequest{Name: name}
+		return nil
+	}
+	type plain JobSkillRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// canonicalSkill normaliza o nome e troca sinônimos pelo nome canônico da
+// taxonomia. Habilidades fora da taxonomia ficam com o nome informado.
+func canonicalSkill(q dbtx, name string) (display, slug string, err error) {
+	display, slug = normalizeSkill(name)
+	if slug == "" {
+		return "", "", nil
+	}
+
+	var canonicalName, canonicalSlug string
+	err = q.QueryRow(`
+		SELECT name, slug FROM skills WHERE slug = ?
+		UNION ALL
+		SELECT s.name, s.slug FROM skill_aliases a JOIN skills s ON a.skill_id = s.id WHERE a.slug = ?
+		LIMIT 1`, slug, slug).Scan(&canonicalName, &canonicalSlug)
+	if err == sql.ErrNoRows {
+		return display, slug, nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return canonicalName, canonicalSlug, nil
+}
+
+// searchSkillsHandler é o autocomplete da taxonomia. Busca pelo nome canônico e
+// pelos sinônimos; resultados que começam com o termo vêm primeiro.
+func searchSkillsHandler(c *gin.Context) {
+	_, slug := normalizeSkill(c.Query("q"))
+	contains := "%" + likeEscaper.Replace(slug) + "%"
+	prefix := likeEscaper.Replace(slug) + "%"
+	category := strings.TrimSpace(c.Query("category"))
+
+	rows, err := db.Query(`
+		SELECT s.id, s.name, s.slug, s.category, s.created_at, s.updated_at,
+		       COALESCE((SELECT GROUP_CONCAT(a.name, '|') FROM skill_aliases a WHERE a.skill_id = s.id), '')
+		FROM skills s
+		WHERE (s.slug LIKE ? ESCAPE '\' OR EXISTS (
+		          SELECT 1 FROM skill_aliases a WHERE a.skill_id = s.id AND a.slug LIKE ? ESCAPE '\'))
+		  AND (? = '' OR s.category = ? COLLATE NOCASE)
+		ORDER BY (s.slug LIKE ? ESCAPE '\' OR EXISTS (
+		          SELECT 1 FROM skill_aliases a WHERE a.skill_id = s.id AND a.slug LIKE ? ESCAPE '\')) DESC, s.name
+		LIMIT ?`,
+		contains, contains, category, category, prefix, prefix, limitParam(c, 10, 50))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar habilidades"})
+		return
+	}
+	defer rows.Close()
+
+	skills := []Skill{}
+	for rows.Next() {
+		var skill Skill
+		var aliases string
+		err := rows.Scan(&skill.ID, &skill.Name, &skill.Slug, &skill.Category, &skill.CreatedAt, &skill.UpdatedAt, &aliases)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar habilidades"})
+			return
+		}
+		skill.Aliases = []string{}
+		if aliases != "" {
+			skill.Aliases = strings.Split(aliases, "|")
+		}
+		skills = append(skills, skill)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"skills": skills})
+}
+
+// importSkillsHandler importa a taxonomia de um CSV (campo "file") com as
+// colunas name, category e aliases, esta com os sinônimos separados por "|"
+// ou ";". Habilidades já cadastradas são atualizadas e sinônimos são somados
+// aos existentes. Linhas com problema são ignoradas e listadas em "errors";
+// com dry_run=true nada é gravado.
+func importSkillsHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSkillsImportSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errUploadTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Envie o arquivo no campo 'file'"})
+		return
+	}
+	if fileHeader.Size > maxSkillsImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errUploadTooLarge.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler arquivo"})
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV vazio ou inválido"})
+		return
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "O CSV precisa de uma coluna 'name'"})
+		return
+	}
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	var created, updated, aliases int
+	rowErrors := []gin.H{}
+	err = withTx(func(tx *sql.Tx) error {
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			line, _ := reader.FieldPos(0)
+
+			isNew, added, err := importSkillRow(tx, field(record, "name"), field(record, "category"), field(record, "aliases"))
+			var rowErr *skillImportRowError
+			if errors.As(err, &rowErr) {
+				rowErrors = append(rowErrors, gin.H{"line": line, "error": rowErr.msg})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if isNew {
+				created++
+			} else {
+				updated++
+			}
+			aliases += added
+		}
+
+		if err := recanonicalizeSkills(tx); err != nil {
+			return err
+		}
+		if dryRun {
+			return errSkillsDryRun
+		}
+		return nil
+	})
+
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("CSV inválido na linha %d", parseErr.Line)})
+		return
+	case err != nil && err != errSkillsDryRun:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao importar habilidades"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Importação concluída",
+		"dry_run": dryRun,
+		"created": created,
+		"updated": updated,
+		"aliases": aliases,
+		"errors":  rowErrors,
+	})
+}
+
+// importSkillRow grava uma linha do CSV. Tudo é validado antes da primeira
+// escrita, para que uma linha com problema não seja importada pela metade.
+func importSkillRow(tx *sql.Tx, name, category, aliasList string) (created bool, added int, err error) {
+	display, slug := normalizeSkill(name)
+	if slug == "" {
+		return false, 0, rowError("Nome da habilidade vazio")
+	}
+	if len(display) > 60 {
+		return false, 0, rowError("Nome %q com mais de 60 caracteres", display)
+	}
+	category = strings.Join(strings.Fields(category), " ")
+	if len(category) > 60 {
+		return false, 0, rowError("Categoria com mais de 60 caracteres")
+	}
+
+	var owner string
+	err = tx.QueryRow(`
+		SELECT s.name FROM skill_aliases a JOIN skills s ON a.skill_id = s.id
+		WHERE a.slug = ?`, slug).Scan(&owner)
+	if err == nil {
+		return false, 0, rowError("%q já é sinônimo de %q", display, owner)
+	}
+	if err != sql.ErrNoRows {
+		return false, 0, err
+	}
+
+	var skillID int
+	err = tx.QueryRow("SELECT id FROM skills WHERE slug = ?", slug).Scan(&skillID)
+	if err != nil && err != sql.ErrNoRows {
+		return false, 0, err
+	}
+	created = err == sql.ErrNoRows
+
+	type alias struct{ name, slug string }
+	var newAliases []alias
+	seen := map[string]bool{slug: true}
+	for _, name := range strings.FieldsFunc(aliasList, func(r rune) bool { return r == '|' || r == ';' }) {
+		aliasName, aliasSlug := normalizeSkill(name)
+		if aliasSlug == "" || seen[aliasSlug] {
+			continue
+		}
+		seen[aliasSlug] = true
+		if len(aliasName) > 60 {
+			return false, 0, rowError("Sinônimo %q com mais de 60 caracteres", aliasName)
+		}
+
+		var canonical string
+		err := tx.QueryRow("SELECT name FROM skills WHERE slug = ?", aliasSlug).Scan(&canonical)
+		if err == nil {
+			return false, 0, rowError("Sinônimo %q já é a habilidade %q", aliasName, canonical)
+		}
+		if err != sql.ErrNoRows {
+			return false, 0, err
+		}
+
+		var ownerID int
+		err = tx.QueryRow(`
+			SELECT s.id, s.name FROM skill_aliases a JOIN skills s ON a.skill_id = s.id
+			WHERE a.slug = ?`, aliasSlug).Scan(&ownerID, &owner)
+		if err == nil && ownerID != skillID {
+			return false, 0, rowError("Sinônimo %q já pertence a %q", aliasName, owner)
+		}
+		if err == nil {
+			continue
+		}
+		if err != sql.ErrNoRows {
+			return false, 0, err
+		}
+		newAliases = append(newAliases, alias{aliasName, aliasSlug})
+	}
+
+	now := time.Now()
+	if created {
+		result, err := tx.Exec("INSERT INTO skills (name, slug, category, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+			display, slug, category, now, now)
+		if err != nil {
+			return false, 0, err
+		}
+		id, _ := result.LastInsertId()
+		skillID = int(id)
+	} else {
+		_, err := tx.Exec("UPDATE skills SET name = ?, category = ?, updated_at = ? WHERE id = ?",
+			display, category, now, skillID)
+		if err != nil {
+			return false, 0, err
+		}
+	}
+
+	for _, a := range newAliases {
+		if _, err := tx.Exec("INSERT INTO skill_aliases (slug, name, skill_id) VALUES (?, ?, ?)", a.slug, a.name, skillID); err != nil {
+			return false, 0, err
+		}
+	}
+	return created, len(newAliases), nil
+}
+
+// recanonicalizeSkills aplica a taxonomia às habilidades já gravadas em vagas e
+// perfis: sinônimos viram o nome canônico e nomes canônicos renomeados são
+// atualizados. Se a vaga ou o perfil já tinha a habilidade canônica, o
+// sinônimo é descartado; numa vaga, a canônica passa a obrigatória se o
+// sinônimo era.
+func recanonicalizeSkills(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		UPDATE job_skills SET required = 1
+		WHERE required = 0 AND EXISTS (
+			SELECT 1 FROM job_skills o
+			JOIN skill_aliases a ON a.slug = o.slug
+			JOIN skills s ON a.skill_id = s.id
+			WHERE o.job_id = job_skills.job_id AND o.required = 1 AND s.slug = job_skills.slug)`)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"job_skills", "user_skills"} {
+		statements := []string{
+			fmt.Sprintf(`UPDATE OR IGNORE %[1]s SET slug = (
+				SELECT s.slug FROM skill_aliases a JOIN skills s ON a.skill_id = s.id WHERE a.slug = %[1]s.slug)
+			WHERE slug IN (SELECT slug FROM skill_aliases)`, table),
+			fmt.Sprintf("DELETE FROM %s WHERE slug IN (SELECT slug FROM skill_aliases)", table),
+			fmt.Sprintf(`UPDATE %[1]s SET name = (SELECT name FROM skills WHERE skills.slug = %[1]s.slug)
+			WHERE slug IN (SELECT slug FROM skills)`, table),
+		}
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSkill(t *testing.T) {
+	tests := []struct {
+		in, display, slug string
+	}{
+		{"Go", "Go", "go"},
+		{"  Spring   Boot ", "Spring Boot", "spring-boot"},
+		{"Node.JS", "Node.JS", "node.js"},
+		{"Análise\tde  Dados", "Análise de Dados", "análise-de-dados"},
+		{"C++", "C++", "c++"},
+		{"   ", "", ""},
+	}
+	for _, tt := range tests {
+		display, slug := normalizeSkill(tt.in)
+		if display != tt.display || slug != tt.slug {
+			t.Errorf("normalizeSkill(%q) = %q, %q; want %q, %q", tt.in, display, slug, tt.display, tt.slug)
+		}
+	}
+}
+
+func TestJobSkillRequestUnmarshal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    JobSkillRequest
+		wantErr bool
+	}{
+		{in: `"Go"`, want: JobSkillRequest{Name: "Go"}},
+		{in: `{"name": "Go", "level": "advanced"}`, want: JobSkillRequest{Name: "Go", Level: "advanced"}},
+		{in: `{"name": "SQL"}`, want: JobSkillRequest{Name: "SQL"}},
+		{in: `42`, wantErr: true},
+	}
+	for _, tt := range tests {
+		var got JobSkillRequest
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("Unmarshal(%s) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+// importSkills importa as linhas (nome, categoria, sinônimos) em uma transação
+func importSkills(t *testing.T, rows ...[3]string) {
+	t.Helper()
+	err := withTx(func(tx *sql.Tx) error {
+		for _, row := range rows {
+			if _, _, err := importSkillRow(tx, row[0], row[1], row[2]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCanonicalSkill(t *testing.T) {
+	setupTestDB(t)
+	importSkills(t,
+		[3]string{"JavaScript", "Linguagens", "JS|Javascript ES6"},
+		[3]string{"PostgreSQL", "Bancos de dados", "Postgres;  psql "},
+	)
+
+	tests := []struct {
+		in, display, slug string
+	}{
+		{"JavaScript", "JavaScript", "javascript"},
+		{"javascript", "JavaScript", "javascript"},
+		{"js", "JavaScript", "javascript"},
+		{" javascript   es6 ", "JavaScript", "javascript"},
+		{"PSQL", "PostgreSQL", "postgresql"},
+		{"Elixir", "Elixir", "elixir"},
+		{"  Rust  Lang ", "Rust Lang", "rust-lang"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		display, slug, err := canonicalSkill(db, tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if display != tt.display || slug != tt.slug {
+			t.Errorf("canonicalSkill(%q) = %q, %q; want %q, %q", tt.in, display, slug, tt.display, tt.slug)
+		}
+	}
+}
+
+func TestImportSkillRow(t *testing.T) {
+	setupTestDB(t)
+	importSkills(t, [3]string{"Go", "Linguagens", "Golang"}, [3]string{"Python", "Linguagens", ""})
+
+	tests := []struct {
+		name        string
+		row         [3]string
+		wantCreated bool
+		wantAdded   int
+		wantErr     string
+	}{
+		{name: "nova com sinônimos", row: [3]string{"Kotlin", "Linguagens", "kt|KT|Kotlin"}, wantCreated: true, wantAdded: 1},
+		{name: "existente ganha sinônimo", row: [3]string{"Go", "Linguagens", "Golang|Go Lang"}, wantAdded: 1},
+		{name: "nome vazio", row: [3]string{"  ", "", ""}, wantErr: "vazio"},
+		{name: "nome longo", row: [3]string{strings.Repeat("a", 61), "", ""}, wantErr: "mais de 60"},
+		{name: "nome já é sinônimo", row: [3]string{"golang", "", ""}, wantErr: `já é sinônimo de "Go"`},
+		{name: "sinônimo já é habilidade", row: [3]string{"Ruby", "", "python"}, wantErr: `já é a habilidade "Python"`},
+		{name: "sinônimo de outra habilidade", row: [3]string{"Ruby", "", "Golang"}, wantErr: `já pertence a "Go"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created bool
+			var added int
+			err := withTx(func(tx *sql.Tx) error {
+				var err error
+				created, added, err = importSkillRow(tx, tt.row[0], tt.row[1], tt.row[2])
+				return err
+			})
+			if tt.wantErr != "" {
+				var rowErr *skillImportRowError
+				if !errors.As(err, &rowErr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want erro de linha contendo %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created != tt.wantCreated || added != tt.wantAdded {
+				t.Errorf("got created=%v added=%d, want %v %d", created, added, tt.wantCreated, tt.wantAdded)
+			}
+		})
+	}
+
+	// A linha rejeitada não deixa nada gravado
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM skills WHERE slug = 'ruby'").Scan(&n)
+	if n != 0 {
+		t.Error("habilidade de linha rejeitada foi gravada")
+	}
+}
+
+func TestRecanonicalizeSkills(t *testing.T) {
+	setupTestDB(t)
+	db.Exec("INSERT INTO users (id, email, password, name) VALUES (1, 'a@x.com', '', 'A')")
+	db.Exec("INSERT INTO jobs (id, title, description, company, location, type, user_id) VALUES (1, 'Dev', 'Dev', 'X', 'SP', 'full-time', 1)")
+	// Sinônimo obrigatório e canônica desejável na mesma vaga; perfil com os dois
+	db.Exec(`INSERT INTO job_skills (job_id, name, slug, required) VALUES
+		(1, 'JS', 'js', 1), (1, 'javascript', 'javascript', 0), (1, 'Postgres', 'postgres', 0)`)
+	db.Exec("INSERT INTO user_skills (user_id, name, slug) VALUES (1, 'JS', 'js'), (1, 'javascript', 'javascript')")
+
+	importSkills(t, [3]string{"JavaScript", "", "JS"}, [3]string{"PostgreSQL", "", "Postgres"})
+	if err := withTx(recanonicalizeSkills); err != nil {
+		t.Fatal(err)
+	}
+
+	skills, err := listJobSkills(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]JobSkill{}
+	for _, s := range skills {
+		got[s.Slug] = s
+	}
+	if len(got) != 2 || got["javascript"].Name != "JavaScript" || !got["javascript"].Required ||
+		got["postgresql"].Name != "PostgreSQL" || got["postgresql"].Required {
+		t.Errorf("habilidades da vaga: %+v", skills)
+	}
+
+	var names []string
+	rows, _ := db.Query("SELECT name FROM user_skills WHERE user_id = 1")
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		names = append(names, name)
+	}
+	rows.Close()
+	if len(names) != 1 || names[0] != "JavaScript" {
+		t.Errorf("habilidades do perfil: %v", names)
+	}
+}
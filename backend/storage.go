@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStorage abstrai onde os arquivos enviados ficam guardados. Hoje existe
+// apenas a implementação em disco local; um backend compatível com S3 pode
+// ser adicionado implementando a mesma interface.
+type FileStorage interface {
+	Save(key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Armazenamento usado pela aplicação
+var fileStorage FileStorage = NewLocalStorage(getEnv("UPLOAD_DIR", "./uploads"))
+
+type LocalStorage struct {
+	baseDir string
+}
+
+func NewLocalStorage(baseDir string) *LocalStorage {
+	return &LocalStorage{baseDir: baseDir}
+}
+
+// path resolve a chave dentro do diretório base, recusando caminhos que escapem dele
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if strings.Contains(clean, "..") {
+		return "", errors.New("chave de arquivo inválida")
+	}
+	return filepath.Join(s.baseDir, clean), nil
+}
+
+func (s *LocalStorage) Save(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// copyStoredFile duplica um arquivo dentro do armazenamento
+func copyStoredFile(storage FileStorage, src, dst string) error {
+	r, err := storage.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return storage.Save(dst, r)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+)
+
+// normalizeTags padroniza as tags em minúsculas, sem espaços nas pontas e sem
+// repetições
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// listJobApplicationTags devolve as tags de todas as candidaturas da vaga,
+// indexadas pelo ID da candidatura
+func listJobApplicationTags(jobID int) (map[int][]string, error) {
+	rows, err := db.Query(`
+		SELECT t.application_id, t.tag
+		FROM application_tags t
+		JOIN applications a ON t.application_id = a.id
+		WHERE a.job_id = ?
+		ORDER BY t.tag`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := map[int][]string{}
+	for rows.Next() {
+		var appID int
+		var tag string
+		if err := rows.Scan(&appID, &tag); err != nil {
+			return nil, err
+		}
+		tags[appID] = append(tags[appID], tag)
+	}
+	return tags, rows.Err()
+}
+
+// visibleTags devolve as tags da candidatura apenas para a equipe de
+// contratação; o candidato recebe nil
+func visibleTags(appID, userID int) []string {
+	var jobID int
+	if err := db.QueryRow("SELECT job_id FROM applications WHERE id = ?", appID).Scan(&jobID); err != nil {
+		return nil
+	}
+	if !isHiringSide(jobID, userID) {
+		return nil
+	}
+
+	rows, err := db.Query("SELECT tag FROM application_tags WHERE application_id = ? ORDER BY tag", appID)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
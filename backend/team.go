@@ -0,0 +1,254 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hiringSideSQL restringe a consulta às vagas em que o usuário é dono ou
+// membro da equipe de contratação. Espera o alias "j" para a tabela jobs e
+// recebe o ID do usuário duas vezes.
+const hiringSideSQL = `(j.user_id = ? OR EXISTS (
+	SELECT 1 FROM job_team_members m WHERE m.job_id = j.id AND m.user_id = ?))`
+
+// isHiringSide indica se o usuário é dono da vaga ou membro da equipe
+func isHiringSide(jobID, userID int) bool {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM jobs j WHERE j.id = ? AND "+hiringSideSQL,
+		jobID, userID, userID).Scan(&count)
+	return err == nil && count > 0
+}
+
+// hiringTeamIDs devolve os IDs do dono da vaga e dos membros da equipe
+func hiringTeamIDs(q dbtx, jobID int) ([]int, error) {
+	rows, err := q.Query(`
+		SELECT user_id FROM jobs WHERE id = ?
+		UNION
+		SELECT user_id FROM job_team_members WHERE job_id = ?`, jobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// requireHiringTeam carrega a vaga e confirma que o usuário logado é o dono
+// ou faz parte da equipe de contratação
+func requireHiringTeam(c *gin.Context, jobID int) bool {
+	var ownerID int
+	err := db.QueryRow("SELECT user_id FROM jobs WHERE id = ?", jobID).Scan(&ownerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vaga não encontrada"})
+		return false
+	}
+
+	if !isHiringSide(jobID, c.GetInt("user_id")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas a equipe de contratação pode acessar esta vaga"})
+		return false
+	}
+	return true
+}
+
+// hiringContext reúne os dados da candidatura usados pela equipe de contratação
+type hiringContext struct {
+	ApplicationID int
+	JobID         int
+	JobOwnerID    int
+	JobTitle      string
+	CandidateName string
+}
+
+// requireHiringAccess carrega a candidatura e confirma que o usuário faz parte
+// da equipe de contratação. Usado por recursos internos que o candidato nunca
+// vê, como notas e scorecards.
+func requireHiringAccess(c *gin.Context, appID int) (*hiringContext, bool) {
+	ctx := &hiringContext{ApplicationID: appID}
+	err := db.QueryRow(`
+		SELECT j.id, j.user_id, j.title, u.name
+		FROM applications a
+		JOIN jobs j ON a.job_id = j.id
+		JOIN users u ON a.user_id = u.id
+		WHERE a.id = ?`, appID).Scan(&ctx.JobID, &ctx.JobOwnerID, &ctx.JobTitle, &ctx.CandidateName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return nil, false
+	}
+
+	if !isHiringSide(ctx.JobID, c.GetInt("user_id")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Apenas a equipe de contratação pode acessar este recurso"})
+		return nil, false
+	}
+	return ctx, true
+}
+
+// listHiringTeam devolve o dono da vaga seguido dos membros da equipe
+func listHiringTeam(jobID int) ([]JobTeamMember, error) {
+	rows, err := db.Query(`
+		SELECT j.id, u.id, u.name, u.email, 'owner', j.created_at
+		FROM jobs j
+		JOIN users u ON j.user_id = u.id
+		WHERE j.id = ?
+		UNION ALL
+		SELECT m.job_id, u.id, u.name, u.email, m.role, m.created_at
+		FROM job_team_members m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.job_id = ?`, jobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	team := []JobTeamMember{}
+	for rows.Next() {
+		var member JobTeamMember
+		if err := rows.Scan(&member.JobID, &member.UserID, &member.Name, &member.Email,
+			&member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		team = append(team, member)
+	}
+	return team, rows.Err()
+}
+
+func getJobTeamHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if !requireHiringTeam(c, jobID) {
+		return
+	}
+
+	team, err := listHiringTeam(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar equipe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"team": team})
+}
+
+// addJobTeamMemberHandler convida um usuário cadastrado para a equipe da vaga.
+// Apenas o dono da vaga gerencia a equipe.
+func addJobTeamMemberHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req TeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireJobOwner(c, jobID) {
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var member JobTeamMember
+	err = db.QueryRow("SELECT id, name, email FROM users WHERE email = ?", req.Email).Scan(
+		&member.UserID, &member.Name, &member.Email)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuário não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar usuário"})
+		return
+	}
+
+	if member.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "O dono da vaga já faz parte da equipe"})
+		return
+	}
+
+	// Candidatos da vaga não podem ver as notas sobre os concorrentes
+	var applied int
+	db.QueryRow("SELECT COUNT(*) FROM applications WHERE job_id = ? AND user_id = ?", jobID, member.UserID).Scan(&applied)
+	if applied > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Candidatos desta vaga não podem fazer parte da equipe"})
+		return
+	}
+
+	now := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO job_team_members (job_id, user_id, role, added_by, created_at)
+		VALUES (?, ?, ?, ?, ?)`, jobID, member.UserID, req.Role, userID, now)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Este usuário já faz parte da equipe"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao adicionar membro"})
+		return
+	}
+
+	member.JobID = jobID
+	member.Role = req.Role
+	member.CreatedAt = now
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Membro adicionado com sucesso",
+		"member":  member,
+	})
+}
+
+func removeJobTeamMemberHandler(c *gin.Context) {
+	jobID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if !requireJobOwner(c, jobID) {
+		return
+	}
+
+	// Quem sai da equipe também deixa a cadeia de aprovação de propostas da vaga
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec("DELETE FROM job_team_members WHERE job_id = ? AND user_id = ?", jobID, memberID)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		_, err = tx.Exec("DELETE FROM job_offer_approvers WHERE job_id = ? AND user_id = ?", jobID, memberID)
+		return err
+	})
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Membro não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover membro"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Membro removido com sucesso"})
+}
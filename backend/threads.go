@@ -0,0 +1,581 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tamanho máximo de cada anexo, em bytes, e quantidade de anexos por mensagem
+var maxAttachmentSize = int64(getEnvInt("MAX_ATTACHMENT_SIZE", 5<<20))
+
+const maxAttachmentsPerMessage = 5
+
+// Tempo sem leitura após o qual o destinatário é avisado por email; 0 desativa
+var threadEmailFallback = time.Duration(getEnvInt("THREAD_EMAIL_FALLBACK_MINUTES", 60)) * time.Minute
+
+// Tipos aceitos nos anexos, além de PDF e DOCX, com a extensão usada no nome
+var attachmentExtensions = map[string]string{
+	"image/png":                 ".png",
+	"image/jpeg":                ".jpg",
+	"image/gif":                 ".gif",
+	"text/plain; charset=utf-8": ".txt",
+}
+
+var errUnsupportedAttachment = errors.New("Formato de anexo não suportado, envie PDF, DOCX, imagem ou texto")
+
+// detectAttachmentType identifica o tipo do anexo pelo conteúdo
+func detectAttachmentType(data []byte) (string, error) {
+	if contentType, err := detectDocumentType(data); err == nil {
+		return contentType, nil
+	}
+	contentType := http.DetectContentType(data)
+	if _, ok := attachmentExtensions[contentType]; ok {
+		return contentType, nil
+	}
+	return "", errUnsupportedAttachment
+}
+
+// readAttachment lê um arquivo do formulário aplicando o limite de tamanho e
+// a verificação de tipo
+func readAttachment(fileHeader *multipart.FileHeader) (*uploadedDocument, error) {
+	if fileHeader.Size > maxAttachmentSize {
+		return nil, errUploadTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAttachmentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxAttachmentSize {
+		return nil, errUploadTooLarge
+	}
+
+	contentType, err := detectAttachmentType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &uploadedDocument{
+		Filename:    sanitizeFilename(fileHeader.Filename, contentType),
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+// readThreadMessage aceita JSON ({"body": ...}) ou multipart, com o texto no
+// campo "body" e os anexos no campo "files"
+func readThreadMessage(c *gin.Context) (string, []*uploadedDocument, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		var req struct {
+			Body string `json:"body" binding:"required,max=10000"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return "", nil, err
+		}
+		return strings.TrimSpace(req.Body), nil, nil
+	}
+
+	// Margem para os cabeçalhos do multipart e o texto da mensagem
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAttachmentSize*maxAttachmentsPerMessage+1<<20)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return "", nil, errUploadTooLarge
+		}
+		return "", nil, errors.New("Formulário inválido")
+	}
+
+	body := ""
+	if values := form.Value["body"]; len(values) > 0 {
+		body = strings.TrimSpace(values[0])
+	}
+	if len(body) > 10000 {
+		return "", nil, errors.New("A mensagem deve ter no máximo 10000 caracteres")
+	}
+
+	files := form.File["files"]
+	if len(files) > maxAttachmentsPerMessage {
+		return "", nil, fmt.Errorf("Envie no máximo %d anexos por mensagem", maxAttachmentsPerMessage)
+	}
+
+	attachments := []*uploadedDocument{}
+	for _, fileHeader := range files {
+		doc, err := readAttachment(fileHeader)
+		if err != nil {
+			return "", nil, err
+		}
+		attachments = append(attachments, doc)
+	}
+	return body, attachments, nil
+}
+
+// requireThreadAccess carrega a candidatura e confirma que o usuário participa
+// da conversa: o candidato ou a equipe de contratação da vaga
+func requireThreadAccess(c *gin.Context, appID int) (*interviewContext, bool) {
+	ctx, err := loadInterviewContext(appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return nil, false
+	}
+
+	userID := c.GetInt("user_id")
+	if ctx.Candidate.UserID != userID && !isHiringSide(ctx.JobID, userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Candidatura não encontrada"})
+		return nil, false
+	}
+	return ctx, true
+}
+
+// threadParticipants devolve o candidato e a equipe de contratação da vaga
+func threadParticipants(ctx *interviewContext) ([]InterviewParticipant, error) {
+	team, err := listHiringTeam(ctx.JobID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := []InterviewParticipant{ctx.Candidate}
+	for _, member := range team {
+		participants = append(participants, InterviewParticipant{UserID: member.UserID, Name: member.Name, Email: member.Email})
+	}
+	return participants, nil
+}
+
+func listThreadMessages(appID int) ([]ThreadMessage, error) {
+	rows, err := db.Query(`
+		SELECT m.id, m.application_id, m.sender_id, u.name, m.body, m.created_at
+		FROM thread_messages m
+		JOIN users u ON m.sender_id = u.id
+		WHERE m.application_id = ?
+		ORDER BY m.created_at, m.id`, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []ThreadMessage{}
+	index := map[int]int{}
+	for rows.Next() {
+		var m ThreadMessage
+		if err := rows.Scan(&m.ID, &m.ApplicationID, &m.SenderID, &m.SenderName, &m.Body, &m.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m.Attachments = []ThreadAttachment{}
+		m.Receipts = []ThreadReadReceipt{}
+		index[m.ID] = len(messages)
+		messages = append(messages, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`
+		SELECT t.id, t.message_id, t.filename, t.content_type, t.size, t.storage_key, t.created_at
+		FROM thread_attachments t
+		JOIN thread_messages m ON t.message_id = m.id
+		WHERE m.application_id = ?
+		ORDER BY t.id`, appID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var a ThreadAttachment
+		if err := rows.Scan(&a.ID, &a.MessageID, &a.Filename, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m := &messages[index[a.MessageID]]
+		m.Attachments = append(m.Attachments, a)
+	}
+	rows.Close()
+
+	rows, err = db.Query(`
+		SELECT r.message_id, r.user_id, u.name, r.read_at
+		FROM thread_message_recipients r
+		JOIN thread_messages m ON r.message_id = m.id
+		JOIN users u ON r.user_id = u.id
+		WHERE m.application_id = ?
+		ORDER BY u.name`, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var messageID int
+		var receipt ThreadReadReceipt
+		var readAt sql.NullTime
+		if err := rows.Scan(&messageID, &receipt.UserID, &receipt.Name, &readAt); err != nil {
+			return nil, err
+		}
+		if readAt.Valid {
+			receipt.ReadAt = &readAt.Time
+		}
+		m := &messages[index[messageID]]
+		m.Receipts = append(m.Receipts, receipt)
+	}
+	return messages, rows.Err()
+}
+
+// getThreadHandler lista a conversa da candidatura com anexos e confirmações de leitura
+func getThreadHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if _, ok := requireThreadAccess(c, appID); !ok {
+		return
+	}
+
+	messages, err := listThreadMessages(appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar mensagens"})
+		return
+	}
+
+	var unread int
+	db.QueryRow(`
+		SELECT COUNT(*) FROM thread_message_recipients r
+		JOIN thread_messages m ON r.message_id = m.id
+		WHERE m.application_id = ? AND r.user_id = ? AND r.read_at IS NULL`, appID, c.GetInt("user_id")).Scan(&unread)
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages, "unread": unread})
+}
+
+// sendThreadMessageHandler envia uma mensagem na conversa da candidatura. Os
+// demais participantes passam a tê-la como não lida.
+func sendThreadMessageHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ctx, ok := requireThreadAccess(c, appID)
+	if !ok {
+		return
+	}
+
+	if ctx.Status == "withdrawn" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Não é possível enviar mensagens em candidaturas retiradas"})
+		return
+	}
+
+	body, uploads, err := readThreadMessage(c)
+	if err != nil {
+		c.JSON(uploadErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if body == "" && len(uploads) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Escreva uma mensagem ou envie um anexo"})
+		return
+	}
+
+	participants, err := threadParticipants(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao enviar mensagem"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	now := time.Now().UTC()
+
+	// Os arquivos são gravados antes da transação e apagados se ela falhar
+	attachments := []ThreadAttachment{}
+	for _, doc := range uploads {
+		token, err := randomToken(16)
+		if err == nil {
+			key := fmt.Sprintf("threads/%d/%s%s", appID, token, extensionFor(doc.ContentType))
+			err = fileStorage.Save(key, bytes.NewReader(doc.Data))
+			attachments = append(attachments, ThreadAttachment{
+				Filename:    doc.Filename,
+				ContentType: doc.ContentType,
+				Size:        int64(len(doc.Data)),
+				StorageKey:  key,
+				CreatedAt:   now,
+			})
+		}
+		if err != nil {
+			for _, a := range attachments {
+				fileStorage.Delete(a.StorageKey)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao salvar anexo"})
+			return
+		}
+	}
+
+	var senderName string
+	db.QueryRow("SELECT name FROM users WHERE id = ?", userID).Scan(&senderName)
+
+	message := ThreadMessage{
+		ApplicationID: appID,
+		SenderID:      userID,
+		SenderName:    senderName,
+		Body:          body,
+		Attachments:   attachments,
+		Receipts:      []ThreadReadReceipt{},
+		CreatedAt:     now,
+	}
+
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec("INSERT INTO thread_messages (application_id, sender_id, body, created_at) VALUES (?, ?, ?, ?)",
+			appID, userID, body, now)
+		if err != nil {
+			return err
+		}
+		id, _ := result.LastInsertId()
+		message.ID = int(id)
+
+		for i := range message.Attachments {
+			a := &message.Attachments[i]
+			a.MessageID = message.ID
+			result, err := tx.Exec(`
+				INSERT INTO thread_attachments (message_id, filename, content_type, size, storage_key, created_at)
+				VALUES (?, ?, ?, ?, ?, ?)`, a.MessageID, a.Filename, a.ContentType, a.Size, a.StorageKey, a.CreatedAt)
+			if err != nil {
+				return err
+			}
+			id, _ := result.LastInsertId()
+			a.ID = int(id)
+		}
+
+		seen := map[int]bool{userID: true}
+		for _, recipient := range participants {
+			recipientID := recipient.UserID
+			if seen[recipientID] {
+				continue
+			}
+			seen[recipientID] = true
+
+			if _, err := tx.Exec("INSERT INTO thread_message_recipients (message_id, user_id) VALUES (?, ?)", message.ID, recipientID); err != nil {
+				return err
+			}
+			notice := fmt.Sprintf("Nova mensagem de %s sobre a vaga %s", senderName, ctx.JobTitle)
+			if err := createNotification(tx, recipientID, "thread_message", notice, appID); err != nil {
+				return err
+			}
+			message.Receipts = append(message.Receipts, ThreadReadReceipt{UserID: recipientID, Name: recipient.Name})
+		}
+
+		recipients := []int{}
+		for id := range seen {
+			recipients = append(recipients, id)
+		}
+		publishEvent(tx, "message.created", gin.H{
+			"application_id": appID,
+			"message_id":     message.ID,
+			"sender_id":      userID,
+			"sender_name":    senderName,
+			"kind":           "thread",
+		}, recipients...)
+		return nil
+	})
+	if err != nil {
+		for _, a := range attachments {
+			fileStorage.Delete(a.StorageKey)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao enviar mensagem"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "Mensagem enviada com sucesso",
+		"thread_message": message,
+	})
+}
+
+// markThreadReadHandler marca como lidas as mensagens da conversa recebidas
+// pelo usuário logado
+func markThreadReadHandler(c *gin.Context) {
+	appID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if _, ok := requireThreadAccess(c, appID); !ok {
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE thread_message_recipients SET read_at = ?
+		WHERE user_id = ? AND read_at IS NULL
+		  AND message_id IN (SELECT id FROM thread_messages WHERE application_id = ?)`,
+		time.Now().UTC(), c.GetInt("user_id"), appID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao marcar mensagens como lidas"})
+		return
+	}
+	marked, _ := result.RowsAffected()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mensagens marcadas como lidas", "marked": marked})
+}
+
+// getUnreadThreadsHandler conta as mensagens não lidas do usuário, no total e
+// por candidatura
+func getUnreadThreadsHandler(c *gin.Context) {
+	rows, err := db.Query(`
+		SELECT m.application_id, j.title, COUNT(*)
+		FROM thread_message_recipients r
+		JOIN thread_messages m ON r.message_id = m.id
+		JOIN applications a ON m.application_id = a.id
+		JOIN jobs j ON a.job_id = j.id
+		WHERE r.user_id = ? AND r.read_at IS NULL
+		GROUP BY m.application_id
+		ORDER BY MAX(m.created_at) DESC`, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao contar mensagens"})
+		return
+	}
+	defer rows.Close()
+
+	threads := []gin.H{}
+	total := 0
+	for rows.Next() {
+		var appID, count int
+		var jobTitle string
+		if err := rows.Scan(&appID, &jobTitle, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao contar mensagens"})
+			return
+		}
+		threads = append(threads, gin.H{"application_id": appID, "job_title": jobTitle, "unread": count})
+		total += count
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "threads": threads})
+}
+
+func downloadThreadAttachmentHandler(c *gin.Context) {
+	attachmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var a ThreadAttachment
+	var appID int
+	err = db.QueryRow(`
+		SELECT t.filename, t.content_type, t.size, t.storage_key, m.application_id
+		FROM thread_attachments t
+		JOIN thread_messages m ON t.message_id = m.id
+		WHERE t.id = ?`, attachmentID).Scan(&a.Filename, &a.ContentType, &a.Size, &a.StorageKey, &appID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anexo não encontrado"})
+		return
+	}
+
+	if _, ok := requireThreadAccess(c, appID); !ok {
+		return
+	}
+
+	serveStoredFile(c, a.StorageKey, a.Filename, a.ContentType, a.Size)
+}
+
+// sendUnreadThreadEmails avisa por email quem tem mensagens não lidas há mais
+// de THREAD_EMAIL_FALLBACK_MINUTES. Cada mensagem gera no máximo um aviso, e
+// as mensagens de uma mesma conversa são agrupadas.
+func sendUnreadThreadEmails() error {
+	if threadEmailFallback <= 0 {
+		return nil
+	}
+
+	rows, err := db.Query(`
+		SELECT r.message_id, r.user_id, u.email, m.application_id, s.name, m.body
+		FROM thread_message_recipients r
+		JOIN thread_messages m ON r.message_id = m.id
+		JOIN users u ON r.user_id = u.id
+		JOIN users s ON m.sender_id = s.id
+		WHERE r.read_at IS NULL AND r.emailed_at IS NULL AND m.created_at <= ?
+		ORDER BY r.user_id, m.application_id, m.created_at`, time.Now().UTC().Add(-threadEmailFallback))
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		userID, appID int
+		email         string
+		messageIDs    []int
+		lines         []string
+	}
+	var groups []*pending
+	for rows.Next() {
+		var messageID, userID, appID int
+		var email, sender, body string
+		if err := rows.Scan(&messageID, &userID, &email, &appID, &sender, &body); err != nil {
+			continue
+		}
+		if n := len(groups); n == 0 || groups[n-1].userID != userID || groups[n-1].appID != appID {
+			groups = append(groups, &pending{userID: userID, appID: appID, email: email})
+		}
+		if body == "" {
+			body = "(mensagem com anexo)"
+		}
+		g := groups[len(groups)-1]
+		g.messageIDs = append(g.messageIDs, messageID)
+		g.lines = append(g.lines, fmt.Sprintf("%s: %s", sender, body))
+	}
+	rows.Close()
+
+	for _, g := range groups {
+		ctx, err := loadInterviewContext(g.appID)
+		if err != nil {
+			continue
+		}
+
+		// Marcado na mesma transação em que o email entra na fila, para não
+		// repetir o aviso; mensagens lidas nesse meio tempo ficam de fora
+		err = withTx(func(tx *sql.Tx) error {
+			var lines []string
+			for i, id := range g.messageIDs {
+				result, err := tx.Exec(`
+					UPDATE thread_message_recipients SET emailed_at = ?
+					WHERE message_id = ? AND user_id = ? AND read_at IS NULL AND emailed_at IS NULL`,
+					time.Now().UTC(), id, g.userID)
+				if err != nil {
+					return err
+				}
+				if n, _ := result.RowsAffected(); n > 0 {
+					lines = append(lines, g.lines[i])
+				}
+			}
+			if len(lines) == 0 {
+				return nil
+			}
+
+			return enqueueEmail(tx, Email{
+				To:      []string{g.email},
+				Subject: fmt.Sprintf("Você tem %d mensagem(ns) não lida(s) sobre a vaga %s", len(lines), ctx.JobTitle),
+				Body:    strings.Join(lines, "\n\n"),
+			})
+		})
+		if err != nil {
+			log.Printf("Erro ao registrar aviso de mensagens não lidas: %v", err)
+		}
+	}
+	return nil
+}
+
+// runThreadEmailFallback é a tarefa periódica da fila que avisa sobre as
+// mensagens não lidas
+func runThreadEmailFallback(payload []byte) error {
+	return sendUnreadThreadEmails()
+}
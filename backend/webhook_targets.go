@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// Permite webhooks para a rede interna, útil apenas em desenvolvimento
+var webhookAllowPrivateTargets = getEnvBool("WEBHOOK_ALLOW_PRIVATE_TARGETS", false)
+
+var errWebhookTarget = errors.New("O webhook não pode apontar para endereços internos")
+
+// Faixas reservadas que não são cobertas pelos métodos de net.IP
+var reservedWebhookNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// isPublicIP indica se o endereço pode receber webhooks: loopback, redes
+// privadas, link-local (incluindo o endereço de metadados da nuvem) e faixas
+// reservadas ficam de fora
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range reservedWebhookNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookURL valida a URL no cadastro: exige http ou https e recusa
+// hosts que resolvem para endereços internos
+func checkWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("O webhook deve usar http ou https")
+	}
+	if webhookAllowPrivateTargets {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return errors.New("Não foi possível resolver o endereço do webhook")
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return errWebhookTarget
+		}
+	}
+	return nil
+}
+
+// webhookDialControl confere o endereço já resolvido no momento da conexão,
+// o que também cobre redirecionamentos e DNS que muda depois do cadastro
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	if webhookAllowPrivateTargets {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errWebhookTarget
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return errWebhookTarget
+	}
+	return nil
+}
+
+// webhookHTTPClient envia os webhooks de integração e de notificação. Não usa
+// proxy, para que a verificação de endereço valha para o destino real.
+var webhookHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: webhookDialControl}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        20,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// webhookErrorMessage descreve a falha de envio sem repassar detalhes da rede,
+// que revelariam quais hosts e portas existem do lado do servidor
+func webhookErrorMessage(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errWebhookTarget):
+		return errWebhookTarget.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "tempo de resposta esgotado"
+	}
+	return "falha na conexão com o destino"
+}
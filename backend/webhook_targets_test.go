@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// allowPrivateWebhookTargets libera o envio para o servidor de teste, que
+// escuta em 127.0.0.1
+func allowPrivateWebhookTargets(t *testing.T) {
+	allow := webhookAllowPrivateTargets
+	webhookAllowPrivateTargets = true
+	t.Cleanup(func() { webhookAllowPrivateTargets = allow })
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"200.147.67.142", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"127.10.0.5", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.0.10", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"::1", false},
+		{"::", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{url: "https://8.8.8.8/hook"},
+		{url: "http://[2606:4700:4700::1111]:8080/hook"},
+		{url: "ftp://8.8.8.8/hook", wantErr: "O webhook deve usar http ou https"},
+		{url: "https:///hook", wantErr: "O webhook deve usar http ou https"},
+		{url: "nada", wantErr: "O webhook deve usar http ou https"},
+		{url: "http://127.0.0.1:8080/hook", wantErr: errWebhookTarget.Error()},
+		{url: "http://localhost/hook", wantErr: errWebhookTarget.Error()},
+		{url: "http://169.254.169.254/latest/meta-data/", wantErr: errWebhookTarget.Error()},
+		{url: "https://10.0.0.5/hook", wantErr: errWebhookTarget.Error()},
+		{url: "http://[::1]/hook", wantErr: errWebhookTarget.Error()},
+		{url: "http://0.0.0.0:8080/hook", wantErr: errWebhookTarget.Error()},
+	}
+	for _, tt := range tests {
+		err := checkWebhookURL(tt.url)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.wantErr {
+			t.Errorf("checkWebhookURL(%q) = %q, want %q", tt.url, got, tt.wantErr)
+		}
+	}
+}
+
+func TestWebhookDialGuard(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	// O endereço é conferido na conexão, antes de qualquer requisição
+	status, deliveryErr := postWebhook(server.URL, "segredo", &WebhookDelivery{EventID: "evt_1", EventType: "job.created", Payload: "{}"})
+	if status != 0 || deliveryErr != errWebhookTarget.Error() || hits != 0 {
+		t.Errorf("got %d %q com %d requisições, want bloqueio sem requisição", status, deliveryErr, hits)
+	}
+
+	// A descrição do erro não revela o que existe na rede do servidor
+	allowPrivateWebhookTargets(t)
+	_, deliveryErr = postWebhook("http://127.0.0.1:1", "segredo", &WebhookDelivery{EventID: "evt_1", EventType: "job.created", Payload: "{}"})
+	if deliveryErr != "falha na conexão com o destino" {
+		t.Errorf("erro de conexão: %q", deliveryErr)
+	}
+}
+
+func TestNotificationWebhookRetries(t *testing.T) {
+	setupTestDB(t)
+	allowPrivateWebhookTargets(t)
+	defer func(base time.Duration, max int) { webhookRetryBase, webhookMaxAttempts = base, max }(webhookRetryBase, webhookMaxAttempts)
+	webhookRetryBase, webhookMaxAttempts = time.Minute, 3
+
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	db.Exec("INSERT INTO users (id, email, password, name) VALUES (1, 'ana@x.com', '', 'Ana')")
+	db.Exec("INSERT INTO notification_settings (user_id, webhook_url, webhook_secret) VALUES (1, ?, 'whsec_x')", server.URL)
+	db.Exec("INSERT INTO notification_deliveries (id, user_id, event, channel, message) VALUES (1, 1, 'status_changed', 'webhook', 'Status alterado')")
+
+	var attempts int
+	var lastError string
+	var nextAttempt, failedAt *time.Time
+	load := func() {
+		t.Helper()
+		err := db.QueryRow("SELECT attempts, last_error, next_attempt_at, failed_at FROM notification_deliveries WHERE id = 1").Scan(
+			&attempts, &lastError, &nextAttempt, &failedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, wantAttempts := range []int{1, 2} {
+		start := time.Now()
+		if err := dispatchNotificationWebhooks(); err != nil {
+			t.Fatal(err)
+		}
+		load()
+		if attempts != wantAttempts || lastError != "status 503" || failedAt != nil || nextAttempt == nil {
+			t.Fatalf("tentativa %d: attempts=%d erro=%q próxima=%v falha=%v", wantAttempts, attempts, lastError, nextAttempt, failedAt)
+		}
+		if wait := nextAttempt.Sub(start); wait < webhookBackoff(attempts)-time.Second || wait > webhookBackoff(attempts)+time.Second {
+			t.Errorf("tentativa %d: próxima em %v, want %v", attempts, wait, webhookBackoff(attempts))
+		}
+
+		// Antes do horário agendado a entrega não é tentada de novo
+		dispatchNotificationWebhooks()
+		if hits != wantAttempts {
+			t.Fatalf("%d requisições, want %d", hits, wantAttempts)
+		}
+		db.Exec("UPDATE notification_deliveries SET next_attempt_at = ? WHERE id = 1", time.Now().UTC().Add(-time.Second))
+	}
+
+	// A última tentativa marca a entrega como falha
+	dispatchNotificationWebhooks()
+	load()
+	if attempts != 3 || failedAt == nil || nextAttempt != nil {
+		t.Errorf("depois da última tentativa: attempts=%d próxima=%v falha=%v", attempts, nextAttempt, failedAt)
+	}
+}
+
+func TestPostNotificationWebhook(t *testing.T) {
+	allowPrivateWebhookTargets(t)
+
+	var got *http.Request
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	settings := &NotificationSettings{WebhookURL: server.URL, WebhookSecret: "whsec_notif"}
+	appID := 9
+	d := notificationDelivery{ID: 42, Event: "application_received", Message: "Nova candidatura", ApplicationID: &appID, CreatedAt: time.Now()}
+	if deliveryErr := postNotificationWebhook(settings, d); deliveryErr != "" {
+		t.Fatal(deliveryErr)
+	}
+
+	if got.Header.Get("X-Webhook-Id") != "ntf_42" || got.Header.Get("X-Webhook-Event") != "application_received" {
+		t.Errorf("cabeçalhos inesperados: %v", got.Header)
+	}
+	if got.Header.Get("X-Signature") != "" {
+		t.Error("cabeçalho antigo X-Signature ainda enviado")
+	}
+	timestamp := got.Header.Get("X-Webhook-Timestamp")
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		t.Fatalf("timestamp inválido: %q", timestamp)
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"id": d.ID, "event": d.Event, "message": d.Message, "application_id": d.ApplicationID, "created_at": d.CreatedAt,
+	})
+	if want := signPayload("whsec_notif", []byte(timestamp+"."+string(payload))); got.Header.Get("X-Webhook-Signature") != want {
+		t.Errorf("assinatura %q, want %q", got.Header.Get("X-Webhook-Signature"), want)
+	}
+	if body["message"] != "Nova candidatura" {
+		t.Errorf("corpo inesperado: %v", body)
+	}
+}
@@ -0,0 +1,585 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Eventos que podem ser assinados pelos endpoints
+var webhookEventTypes = map[string]bool{
+	"job.created":                true,
+	"job.closed":                 true,
+	"application.created":        true,
+	"application.status_changed": true,
+}
+
+var (
+	// Tentativas por entrega antes de marcá-la como falha
+	webhookMaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	// Espera antes da segunda tentativa; dobra a cada nova falha
+	webhookRetryBase = time.Duration(getEnvInt("WEBHOOK_RETRY_BASE_SECONDS", 30)) * time.Second
+	// Falhas seguidas, em qualquer entrega, que desativam o endpoint
+	webhookDisableAfter = getEnvInt("WEBHOOK_DISABLE_AFTER_FAILURES", 20)
+)
+
+const webhookMaxBackoff = 6 * time.Hour
+
+// Evita que duas rodadas de entrega rodem ao mesmo tempo
+var webhookDeliveryMu sync.Mutex
+
+// webhookBackoff devolve a espera antes da próxima tentativa, depois de
+// attempts tentativas que falharam
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookRetryBase
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	return backoff
+}
+
+// enqueueWebhookEvent grava uma entrega para cada endpoint ativo inscrito no
+// evento, na mesma transação da alteração que o gerou. Recebem o evento os
+// endpoints do dono da vaga e os de administradores marcados com all_jobs.
+func enqueueWebhookEvent(q dbtx, eventType string, jobID int, data interface{}) error {
+	rows, err := q.Query(`
+		SELECT id, events FROM webhook_endpoints
+		WHERE active = 1 AND (all_jobs = 1 OR user_id = (SELECT user_id FROM jobs WHERE id = ?))`, jobID)
+	if err != nil {
+		return err
+	}
+
+	var endpointIDs []int
+	for rows.Next() {
+		var id int
+		var events string
+		if err := rows.Scan(&id, &events); err != nil {
+			rows.Close()
+			return err
+		}
+		for _, e := range strings.Split(events, ",") {
+			if e == eventType {
+				endpointIDs = append(endpointIDs, id)
+				break
+			}
+		}
+	}
+	rows.Close()
+	if len(endpointIDs) == 0 {
+		return nil
+	}
+
+	token, err := randomToken(12)
+	if err != nil {
+		return err
+	}
+	eventID := "evt_" + token
+	now := time.Now().UTC()
+
+	payload, err := json.Marshal(gin.H{
+		"id":         eventID,
+		"type":       eventType,
+		"created_at": now,
+		"data":       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, endpointID := range endpointIDs {
+		_, err := q.Exec(`
+			INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, next_attempt_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)`, endpointID, eventID, eventType, string(payload), now, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jobWebhookData monta os dados da vaga enviados nos eventos job.*
+func jobWebhookData(q dbtx, jobID int) (gin.H, error) {
+	var job Job
+	err := q.QueryRow("SELECT id, title, company, location, type, user_id FROM jobs WHERE id = ?", jobID).Scan(
+		&job.ID, &job.Title, &job.Company, &job.Location, &job.Type, &job.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"job_id":   job.ID,
+		"title":    job.Title,
+		"company":  job.Company,
+		"location": job.Location,
+		"type":     job.Type,
+		"owner_id": job.UserID,
+	}, nil
+}
+
+// deliverDueWebhooks envia as entregas pendentes cujo horário chegou
+func deliverDueWebhooks() error {
+	webhookDeliveryMu.Lock()
+	defer webhookDeliveryMu.Unlock()
+
+	rows, err := db.Query(`
+		SELECT d.id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.attempts, e.url, e.secret
+		FROM webhook_deliveries d
+		JOIN webhook_endpoints e ON d.endpoint_id = e.id
+		WHERE d.status = 'pending' AND d.next_attempt_at <= ? AND e.active = 1
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT 100`, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	type dueDelivery struct {
+		delivery    WebhookDelivery
+		url, secret string
+	}
+	var due []dueDelivery
+	for rows.Next() {
+		var d dueDelivery
+		if err := rows.Scan(&d.delivery.ID, &d.delivery.EndpointID, &d.delivery.EventID, &d.delivery.EventType,
+			&d.delivery.Payload, &d.delivery.Attempts, &d.url, &d.secret); err != nil {
+			continue
+		}
+		due = append(due, d)
+	}
+	rows.Close()
+
+	for _, d := range due {
+		// Um endpoint pode ter sido desativado por uma entrega anterior desta rodada
+		var active bool
+		if err := db.QueryRow("SELECT active FROM webhook_endpoints WHERE id = ?", d.delivery.EndpointID).Scan(&active); err != nil || !active {
+			continue
+		}
+
+		statusCode, deliveryErr := postWebhook(d.url, d.secret, &d.delivery)
+		if err := recordWebhookAttempt(&d.delivery, statusCode, deliveryErr); err != nil {
+			log.Printf("Erro ao registrar entrega de webhook %d: %v", d.delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// postWebhook envia a entrega assinada e devolve o status HTTP recebido e a
+// descrição do erro, vazia em caso de sucesso
+func postWebhook(url, secret string, d *WebhookDelivery) (int, string) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(d.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	// A assinatura cobre o horário, para que o destino possa recusar reenvios antigos
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", d.EventID)
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", signPayload(secret, []byte(timestamp+"."+d.Payload)))
+
+	resp, err := webhookHTTPClient.Do(req)
+	if err != nil {
+		return 0, webhookErrorMessage(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Sprintf("status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, ""
+}
+
+// recordWebhookAttempt grava o resultado da tentativa, agenda a próxima com
+// espera exponencial e desativa o endpoint depois de muitas falhas seguidas
+func recordWebhookAttempt(d *WebhookDelivery, statusCode int, deliveryErr string) error {
+	now := time.Now().UTC()
+	attempts := d.Attempts + 1
+
+	return withTx(func(tx *sql.Tx) error {
+		if deliveryErr == "" {
+			_, err := tx.Exec(`
+				UPDATE webhook_deliveries SET status = 'succeeded', attempts = ?, last_status_code = ?, last_error = '',
+				       next_attempt_at = NULL, delivered_at = ?
+				WHERE id = ?`, attempts, statusCode, now, d.ID)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec("UPDATE webhook_endpoints SET consecutive_failures = 0 WHERE id = ?", d.EndpointID)
+			return err
+		}
+
+		status := "pending"
+		var nextAttempt interface{} = now.Add(webhookBackoff(attempts))
+		if attempts >= webhookMaxAttempts {
+			status = "failed"
+			nextAttempt = nil
+		}
+		_, err := tx.Exec(`
+			UPDATE webhook_deliveries SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, next_attempt_at = ?
+			WHERE id = ?`, status, attempts, statusCode, deliveryErr, nextAttempt, d.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE webhook_endpoints SET consecutive_failures = consecutive_failures + 1 WHERE id = ?", d.EndpointID)
+		if err != nil {
+			return err
+		}
+
+		var failures, ownerID int
+		var url string
+		err = tx.QueryRow("SELECT consecutive_failures, user_id, url FROM webhook_endpoints WHERE id = ?", d.EndpointID).Scan(
+			&failures, &ownerID, &url)
+		if err != nil {
+			return err
+		}
+		if failures < webhookDisableAfter {
+			return nil
+		}
+
+		reason := fmt.Sprintf("Desativado após %d falhas seguidas", failures)
+		_, err = tx.Exec(`
+			UPDATE webhook_endpoints SET active = 0, disabled_at = ?, disabled_reason = ?, updated_at = ?
+			WHERE id = ? AND active = 1`, now, reason, now, d.EndpointID)
+		if err != nil {
+			return err
+		}
+		return createNotification(tx, ownerID, "webhook_disabled", fmt.Sprintf("O webhook %s foi desativado após %d falhas seguidas", url, failures), 0)
+	})
+}
+
+// runWebhookDeliveries é a tarefa periódica da fila que envia as entregas pendentes
+func runWebhookDeliveries(payload []byte) error {
+	return deliverDueWebhooks()
+}
+
+func scanWebhookEndpoint(row interface{ Scan(...interface{}) error }) (*WebhookEndpoint, error) {
+	var e WebhookEndpoint
+	var events string
+	var disabledAt sql.NullTime
+	err := row.Scan(&e.ID, &e.UserID, &e.URL, &e.Secret, &events, &e.AllJobs, &e.Active,
+		&e.ConsecutiveFailures, &disabledAt, &e.DisabledReason, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	e.Events = strings.Split(events, ",")
+	if disabledAt.Valid {
+		e.DisabledAt = &disabledAt.Time
+	}
+	return &e, nil
+}
+
+const webhookEndpointColumns = `id, user_id, url, secret, events, all_jobs, active,
+	consecutive_failures, disabled_at, disabled_reason, created_at, updated_at`
+
+// requireWebhookEndpoint carrega um endpoint do usuário logado
+func requireWebhookEndpoint(c *gin.Context) (*WebhookEndpoint, bool) {
+	endpointID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return nil, false
+	}
+
+	endpoint, err := scanWebhookEndpoint(db.QueryRow("SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = ? AND user_id = ?",
+		endpointID, c.GetInt("user_id")))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook não encontrado"})
+		return nil, false
+	}
+	return endpoint, true
+}
+
+// validateWebhookRequest confere a URL, os eventos e a permissão para all_jobs
+func validateWebhookRequest(req *WebhookEndpointRequest, userID int) error {
+	if err := checkWebhookURL(req.URL); err != nil {
+		return err
+	}
+	for _, e := range req.Events {
+		if !webhookEventTypes[e] {
+			return fmt.Errorf("Evento inválido: %s", e)
+		}
+	}
+	if req.AllJobs && !isAdmin(userID) {
+		return fmt.Errorf("Apenas administradores podem receber eventos de todas as vagas")
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	token, err := randomToken(24)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + token, nil
+}
+
+func getWebhooksHandler(c *gin.Context) {
+	rows, err := db.Query("SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE user_id = ? ORDER BY id", c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar webhooks"})
+		return
+	}
+	defer rows.Close()
+
+	endpoints := []WebhookEndpoint{}
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar webhooks"})
+			return
+		}
+		endpoints = append(endpoints, *endpoint)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": endpoints})
+}
+
+func getWebhookHandler(c *gin.Context) {
+	endpoint, ok := requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook": endpoint})
+}
+
+// createWebhookHandler cadastra um endpoint. O segredo usado nas assinaturas
+// aparece apenas nesta resposta.
+func createWebhookHandler(c *gin.Context) {
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	if err := validateWebhookRequest(&req, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar webhook"})
+		return
+	}
+
+	now := time.Now()
+	active := req.Active == nil || *req.Active
+	result, err := db.Exec(`
+		INSERT INTO webhook_endpoints (user_id, url, secret, events, all_jobs, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, req.URL, secret, strings.Join(req.Events, ","), req.AllJobs, active, now, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar webhook"})
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Webhook criado com sucesso",
+		"webhook": WebhookEndpoint{
+			ID:        int(id),
+			UserID:    userID,
+			URL:       req.URL,
+			Events:    req.Events,
+			AllJobs:   req.AllJobs,
+			Active:    active,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		"secret": secret,
+	})
+}
+
+// updateWebhookHandler altera o endpoint. Reativá-lo zera a contagem de falhas.
+func updateWebhookHandler(c *gin.Context) {
+	endpoint, ok := requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateWebhookRequest(&req, endpoint.UserID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	active := endpoint.Active
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	now := time.Now()
+	query := "UPDATE webhook_endpoints SET url = ?, events = ?, all_jobs = ?, active = ?, updated_at = ?"
+	if active && !endpoint.Active {
+		query += ", consecutive_failures = 0, disabled_at = NULL, disabled_reason = ''"
+	}
+	_, err := db.Exec(query+" WHERE id = ?", req.URL, strings.Join(req.Events, ","), req.AllJobs, active, now, endpoint.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar webhook"})
+		return
+	}
+
+	updated, _ := scanWebhookEndpoint(db.QueryRow("SELECT "+webhookEndpointColumns+" FROM webhook_endpoints WHERE id = ?", endpoint.ID))
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook atualizado com sucesso", "webhook": updated})
+}
+
+func deleteWebhookHandler(c *gin.Context) {
+	endpoint, ok := requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	err := withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM webhook_deliveries WHERE endpoint_id = ?", endpoint.ID); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM webhook_endpoints WHERE id = ?", endpoint.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao excluir webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook excluído com sucesso"})
+}
+
+func rotateWebhookSecretHandler(c *gin.Context) {
+	endpoint, ok := requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar segredo"})
+		return
+	}
+
+	_, err = db.Exec("UPDATE webhook_endpoints SET secret = ?, updated_at = ? WHERE id = ?", secret, time.Now(), endpoint.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar segredo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Segredo renovado com sucesso", "secret": secret})
+}
+
+// getWebhookDeliveriesHandler lista as entregas mais recentes do endpoint
+// (?status=pending|succeeded|failed)
+func getWebhookDeliveriesHandler(c *gin.Context) {
+	endpoint, ok := requireWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	query := `
+		SELECT id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+		       last_status_code, last_error, replay_of, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE endpoint_id = ?`
+	args := []interface{}{endpoint.ID}
+	if status := c.Query("status"); status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id DESC LIMIT 100"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar entregas"})
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		var nextAttempt, deliveredAt sql.NullTime
+		var replayOf sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&nextAttempt, &d.LastStatusCode, &d.LastError, &replayOf, &deliveredAt, &d.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar entregas"})
+			return
+		}
+		if nextAttempt.Valid {
+			d.NextAttemptAt = &nextAttempt.Time
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		if replayOf.Valid {
+			id := int(replayOf.Int64)
+			d.ReplayOf = &id
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// replayWebhookDeliveryHandler reenvia uma entrega como uma nova, com o mesmo
+// evento e conteúdo, preservando o registro da original
+func replayWebhookDeliveryHandler(c *gin.Context) {
+	deliveryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var d WebhookDelivery
+	var active bool
+	err = db.QueryRow(`
+		SELECT d.endpoint_id, d.event_id, d.event_type, d.payload, e.active
+		FROM webhook_deliveries d
+		JOIN webhook_endpoints e ON d.endpoint_id = e.id
+		WHERE d.id = ? AND e.user_id = ?`, deliveryID, c.GetInt("user_id")).Scan(
+		&d.EndpointID, &d.EventID, &d.EventType, &d.Payload, &active)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entrega não encontrada"})
+		return
+	}
+
+	if !active {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reative o webhook antes de reenviar entregas"})
+		return
+	}
+
+	now := time.Now().UTC()
+	var id int64
+	err = withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, replay_of, next_attempt_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`, d.EndpointID, d.EventID, d.EventType, d.Payload, deliveryID, now, now)
+		if err != nil {
+			return err
+		}
+		id, _ = result.LastInsertId()
+		return enqueueBackgroundJob(tx, "webhooks.deliver", nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao reenviar entrega"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Entrega agendada para reenvio", "delivery_id": id})
+}
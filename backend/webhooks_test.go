@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignPayload(t *testing.T) {
+	tests := []struct {
+		secret, body, want string
+	}{
+		{"key", "The quick brown fox jumps over the lazy dog", "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{"segredo", `1700000000.{"event":"job.created"}`, "sha256=4bf719040ea09ee2e7c12c98a443a355f5a6f56033bf89fec6fedc7ffc1443f3"},
+	}
+	for _, tt := range tests {
+		if got := signPayload(tt.secret, []byte(tt.body)); got != tt.want {
+			t.Errorf("signPayload(%q, %q) = %s, want %s", tt.secret, tt.body, got, tt.want)
+		}
+	}
+
+	if signPayload("a", []byte("x")) == signPayload("b", []byte("x")) {
+		t.Error("segredos diferentes geraram a mesma assinatura")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	defer func(base time.Duration) { webhookRetryBase = base }(webhookRetryBase)
+	webhookRetryBase = 30 * time.Second
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, webhookMaxBackoff},
+		{100, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	allowPrivateWebhookTargets(t)
+	const secret = "whsec_teste"
+	delivery := &WebhookDelivery{EventID: "evt_1", EventType: "job.created", Payload: `{"job_id":7}`}
+
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus int
+		wantErr    string
+	}{
+		{name: "sucesso", status: http.StatusOK, wantStatus: 200},
+		{name: "aceito", status: http.StatusNoContent, wantStatus: 204},
+		{name: "erro do destino", status: http.StatusInternalServerError, wantStatus: 500, wantErr: "status 500"},
+		{name: "resposta 3xx não conta como entrega", status: http.StatusNotModified, wantStatus: 304, wantErr: "status 304"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				got, body = r, string(b)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			status, deliveryErr := postWebhook(server.URL, secret, delivery)
+			if status != tt.wantStatus || deliveryErr != tt.wantErr {
+				t.Fatalf("got %d %q, want %d %q", status, deliveryErr, tt.wantStatus, tt.wantErr)
+			}
+
+			if got.Method != http.MethodPost || body != delivery.Payload {
+				t.Errorf("requisição inesperada: %s %q", got.Method, body)
+			}
+			if got.Header.Get("X-Webhook-Id") != "evt_1" || got.Header.Get("X-Webhook-Event") != "job.created" {
+				t.Errorf("cabeçalhos inesperados: %v", got.Header)
+			}
+
+			// O destino confere a assinatura com o horário e o corpo recebidos
+			timestamp := got.Header.Get("X-Webhook-Timestamp")
+			sent, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+				t.Errorf("timestamp inválido: %q", timestamp)
+			}
+			if want := signPayload(secret, []byte(timestamp+"."+body)); got.Header.Get("X-Webhook-Signature") != want {
+				t.Errorf("assinatura %q, want %q", got.Header.Get("X-Webhook-Signature"), want)
+			}
+		})
+	}
+
+	status, deliveryErr := postWebhook("http://127.0.0.1:1", secret, delivery)
+	if status != 0 || deliveryErr == "" {
+		t.Errorf("destino inacessível: got %d %q", status, deliveryErr)
+	}
+}
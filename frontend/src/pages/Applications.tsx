@@ -28,16 +28,6 @@ const Applications: React.FC = () => {
     }
   };
 
-  const handleUpdateStatus = async (appId: number, newStatus: string) => {
-    try {
-      await api.put(`/api/applications/${appId}`, { status: newStatus });
-      alert('Status atualizado com sucesso!');
-      fetchApplications();
-    } catch (error: any) {
-      alert(error.response?.data?.error || 'Erro ao atualizar status');
-    }
-  };
-
   const handleDeleteApplication = async (appId: number) => {
     if (confirm('Tem certeza que deseja cancelar esta candidatura?')) {
       try {
@@ -167,16 +157,6 @@ const Applications: React.FC = () => {
                   
                   {/* Actions */}
                   <div className="flex flex-col gap-3 lg:w-48">
-                    <select
-                      value={app.status}
-                      onChange={(e) => handleUpdateStatus(app.id, e.target.value)}
-                      className="px-4 py-2 border border-gray-300 rounded-lg text-sm transition-all duration-200 bg-white text-gray-900 focus:outline-none focus:border-primary-500 focus:ring-2 focus:ring-primary-200"
-                    >
-                      <option value="pending">Pendente</option>
-                      <option value="accepted">Aceita</option>
-                      <option value="rejected">Rejeitada</option>
-                    </select>
-                    
                     <button 
                       onClick={() => handleDeleteApplication(app.id)} 
                       className="bg-red-600 text-white px-4 py-2 rounded-lg text-sm font-medium transition-all duration-200 hover:bg-red-700 hover:shadow-md hover:-translate-y-0.5 flex items-center justify-center gap-2"